@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to display the annotations which affect the restore of container checkpoints
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/olekukonko/tablewriter"
+	spec "github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// restoreAnnotation is an annotation written by a container engine which
+// changes how the engine restores the container
+type restoreAnnotation struct {
+	Key         string
+	Description string
+}
+
+// restoreAnnotations are displayed in this order if they are set
+var restoreAnnotations = []restoreAnnotation{
+	// Podman
+	{"io.podman.annotations.autoremove", "Container is removed when it exits (--rm)"},
+	{"io.podman.annotations.init", "An init process runs as PID 1 (--init)"},
+	{"io.podman.annotations.privileged", "Container is privileged, restoring requires the same privileges"},
+	{"io.podman.annotations.publish-all", "All exposed ports are published to random host ports"},
+	{"io.podman.annotations.apparmor", "AppArmor profile which has to be loaded on the restore host"},
+	{"io.podman.annotations.seccomp", "Seccomp profile the container was created with"},
+	{"io.podman.annotations.label", "SELinux label options"},
+	// CRI-O
+	{"io.kubernetes.cri-o.TTY", "A terminal is attached to the container"},
+	{"io.kubernetes.cri-o.Stdin", "Standard input of the container is kept open"},
+	{"io.kubernetes.cri-o.StdinOnce", "Standard input is closed after the first attach"},
+	{"io.kubernetes.cri-o.SeccompProfilePath", "Seccomp profile the container was created with"},
+	{"io.kubernetes.cri-o.Volumes", "Volumes which have to exist on the restore host"},
+	{"io.kubernetes.container.restartCount", "Number of restarts of the container before checkpointing"},
+	// containerd
+	{"io.kubernetes.cri.container-type", "Only containers and not sandboxes can be restored"},
+}
+
+func showRestoreAnnotations(specDump *spec.Spec) {
+	printCaption("Restore annotations")
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	setTableHeader(table, []string{
+		"Annotation",
+		"Value",
+		"Description",
+	})
+	found := false
+	for _, a := range restoreAnnotations {
+		value, ok := specDump.Annotations[a.Key]
+		if !ok {
+			continue
+		}
+		table.Append([]string{a.Key, displayValue(value), a.Description})
+		found = true
+	}
+	if !found {
+		fmt.Println("No restore related annotations found")
+		return
+	}
+	table.Render()
+}
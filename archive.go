@@ -0,0 +1,278 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to unpack container checkpoint archives
+
+package main
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	metadata "github.com/checkpoint-restore/checkpointctl/lib"
+	"github.com/checkpoint-restore/go-criu/v6/crit"
+	"github.com/containers/storage/pkg/archive"
+	"github.com/containers/storage/pkg/unshare"
+)
+
+// Parts of split archives are named like dump.tar.001, dump.tar.002, ...
+var splitArchivePart = regexp.MustCompile(`^(.*)\.([0-9]{3})$`)
+
+// extractCheckpoint unpacks the checkpoint archive input into a newly
+// created temporary directory. The caller has to remove the directory.
+// Checkpoints can also be read from OCI images in a registry.
+func extractCheckpoint(input string) (string, error) {
+	var parts []string
+	if metadataOnly && isImageReference(input) {
+		return "", fmt.Errorf("--metadata-only is not supported for checkpoints in a registry")
+	}
+	if !isImageReference(input) {
+		var err error
+		if parts, err = getArchiveParts(input); err != nil {
+			return "", err
+		}
+	}
+
+	dir, err := os.MkdirTemp("", "checkpointctl")
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case parts == nil:
+		err = pullCheckpointImage(input, dir)
+	case metadataOnly:
+		err = untarMetadata(parts, dir)
+	default:
+		err = untarParts(parts, dir)
+	}
+	if err != nil {
+		if err := os.RemoveAll(dir); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		return "", fmt.Errorf("unpacking of checkpoint archive %s failed: %w", input, err)
+	}
+	if err := checkCheckpointLayout(dir); err != nil {
+		if err := os.RemoveAll(dir); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		return "", fmt.Errorf("%s is not a container checkpoint: %w", input, err)
+	}
+
+	return dir, nil
+}
+
+// checkCheckpointLayout returns an error if dir contains none of the
+// metadata files written by the container engines. Missing single files
+// are reported by the subcommands reading them.
+func checkCheckpointLayout(dir string) error {
+	for _, name := range []string{metadata.ConfigDumpFile, metadata.SpecDumpFile, metadata.PodDumpFile} {
+		if _, err := os.Lstat(filepath.Join(dir, name)); err == nil {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("neither %s nor %s found", metadata.ConfigDumpFile, metadata.SpecDumpFile)
+}
+
+// getArchiveParts returns the files which make up the checkpoint archive
+// input. This is either input itself or, for archives which have been split
+// into multiple parts, all parts of the archive in the right order.
+func getArchiveParts(input string) ([]string, error) {
+	// Split archives can be specified by any of its parts
+	// or by the name of the archive without the part suffix.
+	base := input
+	if m := splitArchivePart.FindStringSubmatch(input); m != nil {
+		base = m[1]
+	} else if _, err := os.Stat(input); err == nil {
+		return []string{input}, checkRegularFile(input)
+	}
+	if _, err := os.Stat(base + ".001"); err != nil {
+		return []string{input}, checkRegularFile(input)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(base))
+	if err != nil {
+		return nil, err
+	}
+	var parts []string
+	for _, e := range entries {
+		part := filepath.Join(filepath.Dir(base), e.Name())
+		if m := splitArchivePart.FindStringSubmatch(part); m != nil && m[1] == filepath.Clean(base) {
+			parts = append(parts, part)
+		}
+	}
+	sort.Strings(parts)
+
+	for i, part := range parts {
+		m := splitArchivePart.FindStringSubmatch(part)
+		n, err := strconv.Atoi(m[2])
+		if err != nil {
+			return nil, err
+		}
+		if n != i+1 {
+			return nil, fmt.Errorf("split archive %s is incomplete: part %s.%03d is missing", base, base, i+1)
+		}
+		if err := checkRegularFile(part); err != nil {
+			return nil, err
+		}
+	}
+
+	return parts, nil
+}
+
+func checkRegularFile(input string) error {
+	tar, err := os.Stat(input)
+	if err != nil {
+		return err
+	}
+	if !tar.Mode().IsRegular() {
+		return fmt.Errorf("input %s not a regular file", input)
+	}
+
+	return nil
+}
+
+// untarParts unpacks the concatenation of all parts into dir
+func untarParts(parts []string, dir string) error {
+	if len(parts) == 1 {
+		return archive.UntarPath(parts[0], dir)
+	}
+
+	var readers []io.Reader
+	for _, part := range parts {
+		f, err := os.Open(part)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		readers = append(readers, f)
+	}
+
+	return archive.Untar(io.MultiReader(readers...), dir, &archive.TarOptions{
+		InUserNS: unshare.IsRootless(),
+	})
+}
+
+// Files in the subdirectories of the archive which are read with
+// --metadata-only, all other files there are CRIU images
+var metadataImageFiles = map[string]bool{
+	metadata.DumpLogFile:     true,
+	metadata.RestoreLogFile:  true,
+	metadata.DescriptorsFile: true,
+	crit.StatsDump:           true,
+	crit.StatsRestore:        true,
+}
+
+// isMetadataFile returns true if the file name of the archive is needed
+// with --metadata-only. These are the files in the top directory except
+// for the tar archives of the root file system, and the logs and
+// statistics of CRIU.
+func isMetadataFile(name string) bool {
+	dir, file := filepath.Split(name)
+	switch strings.Count(dir, "/") {
+	case 0:
+		return filepath.Ext(file) != ".tar"
+	case 1:
+		return metadataImageFiles[file]
+	}
+
+	return false
+}
+
+// untarMetadata unpacks only the metadata files of the archive into dir.
+// All other files are created as sparse files of their original size
+// without reading their content, which keeps the displayed sizes intact.
+func untarMetadata(parts []string, dir string) error {
+	var readers []io.Reader
+	for _, part := range parts {
+		f, err := os.Open(part)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		readers = append(readers, f)
+	}
+	r, err := archive.DecompressStream(io.MultiReader(readers...))
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	// Symbolic links are created after all files so that
+	// no file is written through a link from the archive
+	links := make(map[string]string)
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		name := filepath.Clean(hdr.Name)
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("invalid path %s in archive", hdr.Name)
+		}
+		path := filepath.Join(dir, name)
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, 0o700); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			links[path] = hdr.Linkname
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+				return err
+			}
+			if err := writeArchiveFile(path, tr, hdr.Size, isMetadataFile(name)); err != nil {
+				return err
+			}
+		}
+	}
+	for path, target := range links {
+		if err := os.Symlink(target, path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeArchiveFile creates the file path with size bytes. The content is
+// only copied from r if withContent is set.
+func writeArchiveFile(path string, r io.Reader, size int64, withContent bool) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if withContent {
+		_, err = io.Copy(f, r)
+		return err
+	}
+
+	return f.Truncate(size)
+}
+
+// decompress returns a reader for the content of r, which is
+// decompressed if r is compressed with gzip
+func decompress(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		return gzip.NewReader(br)
+	}
+
+	return br, nil
+}
@@ -0,0 +1,188 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to display the capabilities of container checkpoints
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/olekukonko/tablewriter"
+	spec "github.com/opencontainers/runtime-spec/specs-go"
+)
+
+const (
+	capKept    = "kept"
+	capDropped = "dropped"
+	capAdded   = "added"
+)
+
+// containerdDefaultCaps is the default capability set of containerd,
+// which is the same as the one of Docker
+var containerdDefaultCaps = []string{
+	"CAP_AUDIT_WRITE",
+	"CAP_CHOWN",
+	"CAP_DAC_OVERRIDE",
+	"CAP_FOWNER",
+	"CAP_FSETID",
+	"CAP_KILL",
+	"CAP_MKNOD",
+	"CAP_NET_BIND_SERVICE",
+	"CAP_NET_RAW",
+	"CAP_SETFCAP",
+	"CAP_SETGID",
+	"CAP_SETPCAP",
+	"CAP_SETUID",
+	"CAP_SYS_CHROOT",
+}
+
+// defaultCaps are the capabilities the container engines grant to
+// containers unless configured otherwise. Podman uses the defaults of
+// containers.conf, CRI-O the ones of crio.conf. For unknown engines the
+// defaults of containerd are used.
+var defaultCaps = map[string][]string{
+	"Podman": {
+		"CAP_CHOWN",
+		"CAP_DAC_OVERRIDE",
+		"CAP_FOWNER",
+		"CAP_FSETID",
+		"CAP_KILL",
+		"CAP_NET_BIND_SERVICE",
+		"CAP_SETFCAP",
+		"CAP_SETGID",
+		"CAP_SETPCAP",
+		"CAP_SETUID",
+		"CAP_SYS_CHROOT",
+	},
+	"CRI-O": {
+		"CAP_CHOWN",
+		"CAP_DAC_OVERRIDE",
+		"CAP_FOWNER",
+		"CAP_FSETID",
+		"CAP_KILL",
+		"CAP_NET_BIND_SERVICE",
+		"CAP_SETGID",
+		"CAP_SETPCAP",
+		"CAP_SETUID",
+	},
+	"containerd": containerdDefaultCaps,
+}
+
+// capabilityChange is a capability of the bounding set of the container
+// compared to the default of its container engine
+type capabilityChange struct {
+	Name   string
+	Status string
+}
+
+func containsCap(caps []string, name string) bool {
+	for _, c := range caps {
+		if c == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// engineDefaultCaps returns the default capabilities of the container
+// engine and the name of the engine whose defaults are used
+func engineDefaultCaps(engine string) ([]string, string) {
+	if caps, ok := defaultCaps[engine]; ok {
+		return caps, engine
+	}
+
+	return containerdDefaultCaps, "containerd"
+}
+
+// compareCaps returns the default capabilities which were kept or dropped,
+// followed by the capabilities which were added to the default
+func compareCaps(bounding, defaults []string) []capabilityChange {
+	var changes []capabilityChange
+	for _, c := range defaults {
+		status := capDropped
+		if containsCap(bounding, c) {
+			status = capKept
+		}
+		changes = append(changes, capabilityChange{c, status})
+	}
+	var added []capabilityChange
+	for _, c := range bounding {
+		if !containsCap(defaults, c) {
+			added = append(added, capabilityChange{c, capAdded})
+		}
+	}
+	sort.Slice(added, func(i, j int) bool { return added[i].Name < added[j].Name })
+
+	return append(changes, added...)
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+
+	return "no"
+}
+
+func showCapabilities(specDump *spec.Spec, engine string) {
+	caps := &spec.LinuxCapabilities{}
+	if specDump.Process != nil && specDump.Process.Capabilities != nil {
+		caps = specDump.Process.Capabilities
+	}
+
+	names := make(map[string]bool)
+	for _, set := range [][]string{caps.Bounding, caps.Effective, caps.Permitted, caps.Inheritable, caps.Ambient} {
+		for _, c := range set {
+			names[c] = true
+		}
+	}
+	sorted := make([]string, 0, len(names))
+	for c := range names {
+		sorted = append(sorted, c)
+	}
+	sort.Strings(sorted)
+
+	printCaption("Capabilities")
+	if len(sorted) == 0 {
+		fmt.Println("No capabilities found in checkpoint")
+	} else {
+		table := tablewriter.NewWriter(os.Stdout)
+		setTableHeader(table, []string{
+			"Capability",
+			"Bounding",
+			"Effective",
+			"Permitted",
+			"Inheritable",
+			"Ambient",
+		})
+		for _, c := range sorted {
+			table.Append([]string{
+				c,
+				yesNo(containsCap(caps.Bounding, c)),
+				yesNo(containsCap(caps.Effective, c)),
+				yesNo(containsCap(caps.Permitted, c)),
+				yesNo(containsCap(caps.Inheritable, c)),
+				yesNo(containsCap(caps.Ambient, c)),
+			})
+		}
+		table.Render()
+	}
+
+	if !showDropped {
+		return
+	}
+	defaults, reference := engineDefaultCaps(engine)
+	table := tablewriter.NewWriter(os.Stdout)
+	setTableHeader(table, []string{
+		"Capability",
+		"Status",
+	})
+	for _, c := range compareCaps(caps.Bounding, defaults) {
+		table.Append([]string{c.Name, c.Status})
+	}
+	printCaption("Capabilities compared to the defaults of %s", reference)
+	table.Render()
+}
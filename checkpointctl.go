@@ -5,17 +5,95 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
-	"github.com/containers/storage/pkg/archive"
 	"github.com/spf13/cobra"
 )
 
 var (
-	name       string
-	version    string
-	printStats bool
-	showMounts bool
-	fullPaths  bool
+	name          string
+	version       string
+	showAll       bool
+	printStats    bool
+	statsDelta    bool
+	showDuration  bool
+	dumpLog       bool
+	dumpLogLines  int
+	showMounts    bool
+	fullPaths     bool
+	mountIDs      bool
+	mountTypes    []string
+	mountSort     bool
+	mountSizes    bool
+	showTZ        bool
+	showHostname  bool
+	showTTY       bool
+	netFiles      bool
+	sharedMemory  bool
+	showIPC       bool
+	memTracking   bool
+	memUsage      bool
+	memPages      bool
+	imgSizes      bool
+	ghostFiles    bool
+	locale        string
+	rawNumbers    bool
+	checkMounts   bool
+	macProfile    bool
+	showCaps      bool
+	showDropped   bool
+	showSeccomp   bool
+	showUlimits   bool
+	lifecycle     bool
+	showVersions  bool
+	validSpec     bool
+	restoreAnnots bool
+	showLabels    bool
+	showAnnots    bool
+	showEnv       bool
+	maskEnv       bool
+	envMerged     bool
+	showCmd       bool
+	maxValueLen   int
+	noTruncate    bool
+	checkProfile  bool
+	cmpCriuVer    bool
+	diffStat      bool
+	diffPsTree    bool
+	psTreeCmd     bool
+	sizeAudit     bool
+	sizeBreakdown bool
+	reqFeats      bool
+	bestEffort    bool
+	procIDs       bool
+	listProcs     bool
+	psTree        bool
+	showSched     bool
+	showTimers    bool
+	sortBy        string
+	pids          []uint
+	procFilter    string
+	warnSize      string
+	warnDumpTime  time.Duration
+	strict        bool
+	maxOpenFiles  bool
+	outputFormat  string
+	view          string
+	quiet         bool
+	noHeader      bool
+	podMapFile    string
+	shareFile     string
+	redact        []string
+	onlyInvalid   bool
+	manifestCtr   string
+	allowDups     bool
+	metadataOnly  bool
+	imagesDir     string
+	sizeUnitName  string
+	siUnits       bool
+	resumeFrom    string
 )
 
 func main() {
@@ -26,13 +104,64 @@ func main() {
 			"created by Podman, CRI-O and containerd",
 		SilenceUsage: true,
 	}
+	rootCommand.PersistentFlags().StringVar(
+		&imagesDir,
+		"images-dir",
+		"",
+		"Name of the directory in the checkpoint which contains the CRIU images (default: auto-detect)",
+	)
+	rootCommand.PersistentFlags().StringVar(
+		&sizeUnitName,
+		"size-unit",
+		sizeUnitAuto,
+		"Unit of all displayed sizes: auto, B, KiB, MiB, GiB",
+	)
+	rootCommand.PersistentFlags().BoolVar(
+		&siUnits,
+		"si",
+		false,
+		"Display sizes in decimal units (kB, MB, GB) instead of binary units",
+	)
+	rootCommand.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		return validateSizeUnit()
+	}
 
 	showCommand := setupShow()
 	rootCommand.AddCommand(showCommand)
+
+	validateCommand := setupValidate()
+	rootCommand.AddCommand(validateCommand)
+
+	shareCommand := setupShare()
+	rootCommand.AddCommand(shareCommand)
+
+	restoreHintCommand := setupRestoreHint()
+	rootCommand.AddCommand(restoreHintCommand)
+
+	diffCommand := setupDiff()
+	rootCommand.AddCommand(diffCommand)
+
+	preflightCommand := setupPreflight()
+	rootCommand.AddCommand(preflightCommand)
+
+	inspectCommand := setupInspect()
+	rootCommand.AddCommand(inspectCommand)
+
+	verifyCommand := setupVerify()
+	rootCommand.AddCommand(verifyCommand)
+
+	driftCommand := setupDrift()
+	rootCommand.AddCommand(driftCommand)
+
+	statCommand := setupStat()
+	rootCommand.AddCommand(statCommand)
+
+	compatCommand := setupCompat()
+	rootCommand.AddCommand(compatCommand)
 	rootCommand.Version = version
 
 	if err := rootCommand.Execute(); err != nil {
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
 
@@ -44,12 +173,42 @@ func setupShow() *cobra.Command {
 		Args:  cobra.MinimumNArgs(1),
 	}
 	flags := cmd.Flags()
+	flags.BoolVar(
+		&showAll,
+		"all",
+		false,
+		"Print all available information about the checkpoints",
+	)
 	flags.BoolVar(
 		&printStats,
 		"print-stats",
 		false,
 		"Print checkpointing statistics if available",
 	)
+	flags.BoolVar(
+		&statsDelta,
+		"stats-delta",
+		false,
+		"Print dump and restore statistics side by side if both are available",
+	)
+	flags.BoolVar(
+		&showDuration,
+		"duration",
+		false,
+		"Print how long checkpointing blocked the container",
+	)
+	flags.BoolVar(
+		&dumpLog,
+		"dump-log",
+		false,
+		"Print the CRIU dump log if included in the checkpoints",
+	)
+	flags.IntVar(
+		&dumpLogLines,
+		"dump-log-lines",
+		0,
+		"Only print the given number of lines from the end of the dump log",
+	)
 	flags.BoolVar(
 		&showMounts,
 		"mounts",
@@ -62,24 +221,539 @@ func setupShow() *cobra.Command {
 		false,
 		"Display mounts with full paths",
 	)
+	flags.BoolVar(
+		&mountIDs,
+		"mount-ids",
+		false,
+		"Display the mount IDs and device numbers of mounts captured by CRIU",
+	)
+	flags.StringSliceVar(
+		&mountTypes,
+		"mount-type",
+		nil,
+		"Only display mounts of the given filesystem types (e.g. bind,tmpfs)",
+	)
+	flags.BoolVar(
+		&mountSort,
+		"mount-sort",
+		false,
+		"Sort the mounts by destination",
+	)
+	flags.BoolVar(
+		&mountSizes,
+		"mount-sizes",
+		false,
+		"Display the size of mount sources which are directories on this host (may be slow)",
+	)
+	flags.BoolVar(
+		&showTZ,
+		"timezone",
+		false,
+		"Print the timezone and locale environment of the container",
+	)
+	flags.BoolVar(
+		&showEnv,
+		"env",
+		false,
+		"Print the environment variables of the container",
+	)
+	flags.BoolVar(
+		&maskEnv,
+		"mask-env",
+		false,
+		"Replace the values of environment variables which look like secrets with ***",
+	)
+	flags.BoolVar(
+		&envMerged,
+		"env-merged",
+		false,
+		"Show the environment of the container process with the source of each variable",
+	)
+	flags.BoolVar(
+		&showCmd,
+		"cmd",
+		false,
+		"Print the command line of the container",
+	)
+	flags.IntVar(
+		&maxValueLen,
+		"max-value-len",
+		defaultMaxValueLen,
+		"Truncate displayed values after the given number of characters",
+	)
+	flags.BoolVar(
+		&noTruncate,
+		"no-truncate",
+		false,
+		"Display values without truncation",
+	)
+	flags.BoolVar(
+		&showHostname,
+		"hostname",
+		false,
+		"Print the hostname from the spec and the hostname captured at runtime",
+	)
+	flags.BoolVar(
+		&showTTY,
+		"tty",
+		false,
+		"Print whether the container has a terminal and the terminals captured by CRIU",
+	)
+	flags.BoolVar(
+		&netFiles,
+		"net-files",
+		false,
+		"Print the resolv.conf, hostname and hosts files included in the checkpoint",
+	)
+	flags.BoolVar(
+		&sharedMemory,
+		"shared-memory",
+		false,
+		"Print the memory regions shared between the processes of the container",
+	)
+	flags.BoolVar(
+		&showIPC,
+		"ipc",
+		false,
+		"Print the System V shared memory segments, semaphore sets and message queues",
+	)
+	flags.BoolVar(
+		&memTracking,
+		"mem-tracking",
+		false,
+		"Print whether memory change tracking (pre-copy) was used",
+	)
+	flags.BoolVar(
+		&memUsage,
+		"mem-usage",
+		false,
+		"Print the memory of the checkpoint as a percentage of the container's memory limit",
+	)
+	flags.BoolVar(
+		&memPages,
+		"mem-pages",
+		false,
+		"Print the anonymous, file-backed and shared memory of each process",
+	)
+	flags.BoolVar(
+		&imgSizes,
+		"image-sizes",
+		false,
+		"Print the size of the CRIU images per category",
+	)
+	flags.BoolVar(
+		&sizeBreakdown,
+		"size",
+		false,
+		"Print the size of each file of the checkpoint instead of the total size",
+	)
+	flags.BoolVar(
+		&ghostFiles,
+		"ghost-files",
+		false,
+		"Print the deleted but open files which CRIU stored in the checkpoint",
+	)
+	flags.StringVar(
+		&locale,
+		"locale",
+		"",
+		"Locale used to format numbers (default from LC_ALL, LC_NUMERIC or LANG)",
+	)
+	flags.BoolVar(
+		&rawNumbers,
+		"raw",
+		false,
+		"Print sizes in bytes and numbers without thousands separators",
+	)
+	flags.BoolVar(
+		&reqFeats,
+		"required-features",
+		false,
+		"Print the CRIU options required to restore the checkpoints",
+	)
+	flags.BoolVar(
+		&bestEffort,
+		"best-effort",
+		false,
+		"Display the available data of checkpoints from unknown container managers",
+	)
+	flags.BoolVar(
+		&procIDs,
+		"proc-ids",
+		false,
+		"Print the umask, session and process group IDs of the checkpointed processes",
+	)
+	flags.BoolVar(
+		&listProcs,
+		"processes",
+		false,
+		"Print a flat list of the PID, PPID, command, state and memory of the checkpointed processes",
+	)
+	flags.BoolVar(
+		&psTree,
+		"ps-tree",
+		false,
+		"Print the tree of the checkpointed processes (as Graphviz graph with --output dot)",
+	)
+	flags.BoolVar(
+		&psTreeCmd,
+		"ps-tree-cmd",
+		false,
+		"Print the command line of the processes in the process tree",
+	)
+	flags.BoolVar(
+		&showSched,
+		"sched",
+		false,
+		"Print the scheduling policy, nice value and priority of the checkpointed processes",
+	)
+	flags.BoolVar(
+		&showTimers,
+		"timers",
+		false,
+		"Print the interval and POSIX timers of the checkpointed processes",
+	)
+	flags.StringVar(
+		&sortBy,
+		"sort-by",
+		sortByPID,
+		"Sort the list of processes by: "+strings.Join(processSortOrders, ", "),
+	)
+	flags.UintSliceVar(
+		&pids,
+		"pid",
+		nil,
+		"Limit the displayed processes to the given PIDs",
+	)
+	flags.StringVar(
+		&procFilter,
+		"proc-filter",
+		"",
+		"Limit the displayed processes to commands matching the given regular expression or, if it is not a valid one, containing the given string",
+	)
+	flags.StringVar(
+		&warnSize,
+		"warn-size",
+		"",
+		"Warn if the checkpoint size exceeds the given size (e.g. 2GiB)",
+	)
+	flags.DurationVar(
+		&warnDumpTime,
+		"warn-dump-time",
+		0,
+		"Warn if the container was frozen longer than the given duration during checkpointing (e.g. 2s)",
+	)
+	flags.BoolVar(
+		&maxOpenFiles,
+		"max-open-files",
+		false,
+		"Warn if a process has more open files than the limit of open files on this host allows",
+	)
+	flags.BoolVar(
+		&strict,
+		"strict",
+		false,
+		"Treat warnings about exceeded thresholds and spec violations as errors",
+	)
+	flags.BoolVar(
+		&checkMounts,
+		"check-mounts",
+		false,
+		"Warn about bind mounts with a source which does not exist on this host",
+	)
+	flags.BoolVar(
+		&macProfile,
+		"mac",
+		false,
+		"Print the AppArmor profile and SELinux labels of the container",
+	)
+	flags.BoolVar(
+		&showCaps,
+		"caps",
+		false,
+		"Print the capabilities of the container",
+	)
+	flags.BoolVar(
+		&showDropped,
+		"show-dropped",
+		false,
+		"Compare the capabilities with the defaults of the container engine",
+	)
+	flags.BoolVar(
+		&showSeccomp,
+		"seccomp",
+		false,
+		"Print the seccomp profile of the container and the seccomp mode of the processes",
+	)
+	flags.BoolVar(
+		&showUlimits,
+		"ulimits",
+		false,
+		"Print the ulimits configured for the container in config.dump",
+	)
+	flags.BoolVar(
+		&lifecycle,
+		"lifecycle",
+		false,
+		"Print the restart policy and health check of the container",
+	)
+	flags.BoolVar(
+		&showVersions,
+		"versions",
+		false,
+		"Print the version of CRIU which created the checkpoint",
+	)
+	flags.BoolVar(
+		&validSpec,
+		"validate-spec",
+		false,
+		"Validate the spec of the container against the OCI runtime specification",
+	)
+	flags.BoolVar(
+		&showLabels,
+		"labels",
+		false,
+		"Print the labels of the container",
+	)
+	flags.BoolVar(
+		&showAnnots,
+		"annotations",
+		false,
+		"Print the annotations of the container",
+	)
+	flags.BoolVar(
+		&restoreAnnots,
+		"restore-annotations",
+		false,
+		"Print the annotations which affect how the container is restored",
+	)
+	flags.BoolVar(
+		&checkProfile,
+		"check-apparmor",
+		false,
+		"Warn if the AppArmor profile of the container is not loaded on this host",
+	)
+	flags.BoolVar(
+		&cmpCriuVer,
+		"compare-criu-version",
+		false,
+		"Compare the CRIU version of the checkpoint with the criu binary on this host",
+	)
+	flags.StringVar(
+		&podMapFile,
+		"pod-map",
+		"",
+		"JSON or YAML file mapping container IDs to pod and namespace",
+	)
+	flags.StringVar(
+		&view,
+		"view",
+		viewMemory,
+		"View rendered with --output svg: "+strings.Join(svgViews, ", "),
+	)
+	flags.BoolVar(
+		&allowDups,
+		"allow-duplicates",
+		false,
+		"Process checkpoints which are passed more than once every time",
+	)
+	flags.StringVar(
+		&resumeFrom,
+		"resume-from",
+		"",
+		"State file recording the displayed checkpoints, which are skipped when running again",
+	)
+	flags.BoolVar(
+		&metadataOnly,
+		"metadata-only",
+		false,
+		"Only unpack the metadata of the checkpoint and not the CRIU images",
+	)
+	flags.BoolVar(
+		&quiet,
+		"quiet",
+		false,
+		"Only print the tables, without the banner and the captions of the sections",
+	)
+	flags.BoolVar(
+		&noHeader,
+		"no-header",
+		false,
+		"Do not print the header rows of the tables",
+	)
+	addOutputFlag(cmd)
 
 	return cmd
 }
 
 func show(cmd *cobra.Command, args []string) error {
-	if fullPaths && !showMounts {
+	if fullPaths && !showMounts && !showAll {
 		return fmt.Errorf("Cannot use --full-paths without --mounts option")
 	}
+	if mountIDs && !showMounts && !showAll {
+		return fmt.Errorf("Cannot use --mount-ids without --mounts option")
+	}
+	if len(mountTypes) > 0 && !showMounts && !showAll {
+		return fmt.Errorf("Cannot use --mount-type without --mounts option")
+	}
+	if mountSort && !showMounts && !showAll {
+		return fmt.Errorf("Cannot use --mount-sort without --mounts option")
+	}
+	if mountSizes && !showMounts && !showAll {
+		return fmt.Errorf("Cannot use --mount-sizes without --mounts option")
+	}
+	if envMerged && !showEnv && !showAll {
+		return fmt.Errorf("Cannot use --env-merged without --env option")
+	}
+	if showDropped && !showCaps && !showAll {
+		return fmt.Errorf("Cannot use --show-dropped without --caps option")
+	}
+	if psTreeCmd && !psTree && !showAll {
+		return fmt.Errorf("Cannot use --ps-tree-cmd without --ps-tree option")
+	}
 
-	input := args[0]
-	tar, err := os.Stat(input)
-	if err != nil {
+	if _, err := parseWarnSize(warnSize); err != nil {
+		return err
+	}
+	if err := validateOutputFormat(cmd); err != nil {
+		return err
+	}
+	if showAll {
+		enableAllSections()
+	}
+	if err := setupNumberFormat(); err != nil {
 		return err
 	}
-	if !tar.Mode().IsRegular() {
-		return fmt.Errorf("input %s not a regular file", input)
+	if err := validateProcessSortOrder(sortBy); err != nil {
+		return err
+	}
+	setupProcessFilter()
+	if err := validateMaxValueLen(); err != nil {
+		return err
+	}
+	// The process seccomp modes and the size of compressed memory pages
+	// are read from the CRIU images as well, the network files from
+	// rootfs-diff.tar and the ghost files
+	if metadataOnly && (needsCriuImages() || maxOpenFiles || showSeccomp || memUsage || netFiles) {
+		return fmt.Errorf("--metadata-only cannot be used with options which read the CRIU images or rootfs-diff.tar")
+	}
+	if dumpLogLines < 0 {
+		return fmt.Errorf("--dump-log-lines must not be negative")
+	}
+	if podMapFile != "" {
+		m, err := loadPodMap(podMapFile)
+		if err != nil {
+			return err
+		}
+		podMap = m
+	}
+
+	if !allowDups {
+		args = uniqueCheckpoints(args)
+	}
+	var state *resumeState
+	if resumeFrom != "" {
+		s, err := openResumeState(resumeFrom)
+		if err != nil {
+			return err
+		}
+		defer s.Close()
+		state = s
+		if args = state.pending(args); len(args) == 0 {
+			fmt.Fprintln(os.Stderr, "Note: all checkpoints have already been processed")
+			return nil
+		}
+	}
+	if len(args) == 1 {
+		if err := showCheckpoint(args[0]); err != nil {
+			return err
+		}
+		if state != nil {
+			return state.record(args[0])
+		}
+		return nil
+	}
+
+	// With several checkpoints a broken one does not prevent the
+	// others from being displayed. Only displayed checkpoints are
+	// recorded with --resume-from, broken ones are tried again.
+	var firstErr, policyErr error
+	failed, violations, sameExitCode := 0, 0, true
+	for i, input := range args {
+		if i > 0 && outputFormat == outputYAML {
+			fmt.Println("---")
+		}
+		if err := showCheckpoint(input); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s: %v\n", input, err)
+			failed++
+			if firstErr == nil {
+				firstErr = err
+			} else if exitCode(err) != exitCode(firstErr) {
+				sameExitCode = false
+			}
+			// Checkpoints violating a policy of --strict fail the run
+			if isPolicyError(err) {
+				violations++
+				if policyErr == nil {
+					policyErr = err
+				}
+			}
+			continue
+		}
+		if state != nil {
+			if err := state.record(input); err != nil {
+				return err
+			}
+		}
+	}
+	if failed == len(args) {
+		// Keep the exit code if all checkpoints failed for the same reason
+		if sameExitCode {
+			return fmt.Errorf("none of the %d checkpoints could be displayed: %w", len(args), firstErr)
+		}
+		return fmt.Errorf("none of the %d checkpoints could be displayed", len(args))
+	}
+	if policyErr != nil {
+		return fmt.Errorf("%d of the %d checkpoints failed --strict: %w", violations, len(args), policyErr)
+	}
+
+	return nil
+}
+
+// uniqueCheckpoints removes checkpoints which have been passed more than
+// once, also under a different path or through a symbolic link. Inputs
+// which are not local files, like images in a registry, are compared as given.
+func uniqueCheckpoints(inputs []string) []string {
+	seen := make(map[string]string)
+	var unique []string
+	for _, input := range inputs {
+		key := checkpointKey(input)
+		if first, ok := seen[key]; ok {
+			fmt.Fprintf(os.Stderr, "Note: skipping %s, which is the same checkpoint as %s\n", input, first)
+			continue
+		}
+		seen[key] = input
+		unique = append(unique, input)
+	}
+
+	return unique
+}
+
+// checkpointKey identifies a checkpoint independent of the path it was
+// passed with. Local files are identified by their absolute path with
+// symbolic links resolved, other inputs by the input itself.
+func checkpointKey(input string) string {
+	if path, err := filepath.Abs(input); err == nil {
+		if resolved, err := filepath.EvalSymlinks(path); err == nil {
+			return resolved
+		}
 	}
-	dir, err := os.MkdirTemp("", "checkpointctl")
+
+	return input
+}
+
+func showCheckpoint(input string) error {
+	dir, err := extractCheckpoint(input)
 	if err != nil {
 		return err
 	}
@@ -89,8 +763,539 @@ func show(cmd *cobra.Command, args []string) error {
 		}
 	}()
 
-	if err := archive.UntarPath(input, dir); err != nil {
-		return fmt.Errorf("unpacking of checkpoint archive %s failed: %w", input, err)
+	if isSandboxCheckpoint(dir) {
+		return showSandboxCheckpoint(input, dir)
+	}
+
+	return showContainerCheckpoint(input, dir)
+}
+
+func setupValidate() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validate the content of checkpoint archives",
+		RunE:  validate,
+		Args:  cobra.MinimumNArgs(1),
 	}
-	return showContainerCheckpoint(dir)
+	flags := cmd.Flags()
+	flags.BoolVar(
+		&onlyInvalid,
+		"only-invalid",
+		false,
+		"Only display checkpoints which failed validation",
+	)
+	flags.BoolVar(
+		&sizeAudit,
+		"size-audit",
+		false,
+		"Compare the size of the checkpoint with the sizes declared in descriptors.json",
+	)
+	flags.BoolVar(
+		&allowDups,
+		"allow-duplicates",
+		false,
+		"Process checkpoints which are passed more than once every time",
+	)
+	addOutputFlag(cmd)
+
+	return cmd
+}
+
+func validate(cmd *cobra.Command, args []string) error {
+	if err := setOutputFormat(cmd); err != nil {
+		return err
+	}
+
+	if !allowDups {
+		args = uniqueCheckpoints(args)
+	}
+
+	invalid := 0
+	results := []validationOutput{}
+	for _, input := range args {
+		v := validateArchive(input)
+		if !v.Valid() {
+			invalid++
+		} else if onlyInvalid {
+			continue
+		}
+		result := validationOutput{
+			Input:  input,
+			Valid:  v.Valid(),
+			Checks: v.Checks,
+		}
+		switch outputFormat {
+		case outputTable:
+			showCheckpointValidation(input, v)
+		case outputLogfmt:
+			// One line per checkpoint is printed as soon as it is validated
+			if err := printOutput(result); err != nil {
+				return err
+			}
+		default:
+			results = append(results, result)
+		}
+	}
+	if outputFormat == outputJSON || outputFormat == outputYAML {
+		if err := printOutput(results); err != nil {
+			return err
+		}
+	}
+	if invalid > 0 {
+		return fmt.Errorf("%d of %d checkpoint(s) failed validation", invalid, len(args))
+	}
+
+	return nil
+}
+
+func validateArchive(input string) *checkpointValidation {
+	dir, err := extractCheckpoint(input)
+	if err != nil {
+		// An archive which cannot be unpacked is reported like any other
+		// broken checkpoint to continue with the remaining archives
+		v := &checkpointValidation{}
+		v.add("archive", checkFailed, err.Error())
+		return v
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}()
+
+	return validateCheckpoint(dir)
+}
+
+func setupShare() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "share",
+		Short: "Export redacted checkpoint metadata suitable for bug reports",
+		RunE:  share,
+		Args:  cobra.ExactArgs(1),
+	}
+	flags := cmd.Flags()
+	flags.StringVarP(
+		&shareFile,
+		"output",
+		"o",
+		"",
+		"Write the metadata bundle to the given file instead of stdout",
+	)
+	flags.StringSliceVar(
+		&redact,
+		"redact",
+		redactableFields,
+		"Comma-separated list of fields to redact ("+strings.Join(redactableFields, ", ")+")",
+	)
+
+	return cmd
+}
+
+func share(cmd *cobra.Command, args []string) error {
+	if err := validateRedactFields(redact); err != nil {
+		return err
+	}
+
+	input := args[0]
+	dir, err := extractCheckpoint(input)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}()
+
+	bundle, err := createShareBundle(dir, redact)
+	if err != nil {
+		return err
+	}
+
+	return writeShareBundle(bundle, shareFile)
+}
+
+func setupRestoreHint() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restore-hint",
+		Short: "Suggest a command to restore a checkpoint archive",
+		RunE:  restoreHint,
+		Args:  cobra.ExactArgs(1),
+	}
+
+	return cmd
+}
+
+func restoreHint(cmd *cobra.Command, args []string) error {
+	input := args[0]
+	dir, err := extractCheckpoint(input)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}()
+
+	return showRestoreHint(input, dir)
+}
+
+func setupPreflight() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "preflight",
+		Short: "Verify that the external references of a checkpoint archive exist on this host",
+		RunE:  preflight,
+		Args:  cobra.ExactArgs(1),
+	}
+	flags := cmd.Flags()
+	flags.BoolVar(
+		&bestEffort,
+		"best-effort",
+		false,
+		"Only warn about missing external references",
+	)
+	addOutputFlag(cmd)
+
+	return cmd
+}
+
+func preflight(cmd *cobra.Command, args []string) error {
+	if err := setOutputFormat(cmd); err != nil {
+		return err
+	}
+
+	input := args[0]
+	dir, err := extractCheckpoint(input)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}()
+
+	out, err := getExternalReferences(input, dir)
+	if err != nil {
+		return fmt.Errorf("unable to verify external references: %w", err)
+	}
+	if outputFormat != outputTable {
+		if err := printOutput(out); err != nil {
+			return err
+		}
+	} else {
+		showPreflight(out)
+	}
+
+	if missing := out.missingReferences(); missing > 0 {
+		err := fmt.Errorf("%d of %d external reference(s) missing", missing, len(out.References))
+		if bestEffort {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			return nil
+		}
+		return err
+	}
+
+	return nil
+}
+
+func setupInspect() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "inspect",
+		Short: "Print a report with the summary, sizes, statistics and validation of a checkpoint archive",
+		RunE:  inspect,
+		Args:  cobra.ExactArgs(1),
+	}
+	addOutputFlag(cmd)
+
+	return cmd
+}
+
+func inspect(cmd *cobra.Command, args []string) error {
+	if err := setOutputFormat(cmd); err != nil {
+		return err
+	}
+
+	input := args[0]
+	dir, err := extractCheckpoint(input)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}()
+
+	out, err := getInspectOutput(input, dir)
+	if err != nil {
+		return err
+	}
+	if err := printOutput(out); err != nil {
+		return err
+	}
+	if !out.Validation.Valid {
+		return fmt.Errorf("%s failed validation", input)
+	}
+
+	return nil
+}
+
+func setupVerify() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify that a checkpoint archive contains all parts needed for a restore",
+		RunE:  verify,
+		Args:  cobra.ExactArgs(1),
+	}
+	addOutputFlag(cmd)
+
+	return cmd
+}
+
+func verify(cmd *cobra.Command, args []string) error {
+	if err := setOutputFormat(cmd); err != nil {
+		return err
+	}
+
+	input := args[0]
+	dir, err := extractCheckpoint(input)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}()
+
+	if isSandboxCheckpoint(dir) {
+		return fmt.Errorf("%s is a pod sandbox checkpoint, which is not supported by verify", input)
+	}
+	v := verifyCheckpoint(dir)
+	if outputFormat == outputTable {
+		showCheckpointVerification(input, v)
+	} else if err := printOutput(validationOutput{
+		Input:  input,
+		Valid:  v.Valid(),
+		Checks: v.Checks,
+	}); err != nil {
+		return err
+	}
+	if !v.Valid() {
+		return fmt.Errorf("%s failed verification", input)
+	}
+
+	return nil
+}
+
+func setupStat() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stat",
+		Short: "Print the sizes of a checkpoint archive without decoding its contents",
+		RunE:  stat,
+		Args:  cobra.ExactArgs(1),
+	}
+	addOutputFlag(cmd)
+
+	return cmd
+}
+
+func stat(cmd *cobra.Command, args []string) error {
+	if err := setOutputFormat(cmd); err != nil {
+		return err
+	}
+
+	input := args[0]
+	// Only the sizes of the CRIU images are needed, which are kept
+	// when unpacking only the metadata of local archives
+	metadataOnly = !isImageReference(input)
+	dir, err := extractCheckpoint(input)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}()
+
+	if isSandboxCheckpoint(dir) {
+		return fmt.Errorf("%s is a pod sandbox checkpoint, which is not supported by stat", input)
+	}
+	out, err := getStatOutput(input, dir)
+	if err != nil {
+		return err
+	}
+	if outputFormat == outputTable {
+		showCheckpointStat(out)
+		return nil
+	}
+
+	return printOutput(out)
+}
+
+func setupCompat() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "compat",
+		Short: "Compare the host which created a checkpoint archive with this host",
+		RunE:  compat,
+		Args:  cobra.ExactArgs(1),
+	}
+	addOutputFlag(cmd)
+
+	return cmd
+}
+
+func compat(cmd *cobra.Command, args []string) error {
+	if err := setOutputFormat(cmd); err != nil {
+		return err
+	}
+
+	input := args[0]
+	dir, err := extractCheckpoint(input)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}()
+
+	if isSandboxCheckpoint(dir) {
+		return fmt.Errorf("%s is a pod sandbox checkpoint, which is not supported by compat", input)
+	}
+	out, err := getCompatOutput(input, dir)
+	if err != nil {
+		return fmt.Errorf("unable to compare hosts: %w", err)
+	}
+	if outputFormat == outputTable {
+		showCompat(out)
+		return nil
+	}
+
+	return printOutput(out)
+}
+
+func setupDiff() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Compare two checkpoint archives",
+		RunE:  diff,
+		Args:  cobra.ExactArgs(2),
+	}
+	flags := cmd.Flags()
+	flags.BoolVar(
+		&diffStat,
+		"stat",
+		false,
+		"Print a summary of the differences before the detailed diff",
+	)
+	flags.BoolVar(
+		&diffPsTree,
+		"ps-tree",
+		false,
+		"Compare the process trees of the checkpoints",
+	)
+	addOutputFlag(cmd)
+
+	return cmd
+}
+
+func diff(cmd *cobra.Command, args []string) error {
+	if err := setOutputFormat(cmd); err != nil {
+		return err
+	}
+
+	var summaries []*checkpointSummary
+	for _, input := range args {
+		dir, err := extractCheckpoint(input)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := os.RemoveAll(dir); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+		}()
+
+		summary, err := loadCheckpointSummary(dir)
+		if err != nil {
+			return err
+		}
+		summaries = append(summaries, summary)
+	}
+
+	d := diffCheckpoints(summaries[0], summaries[1])
+	if outputFormat != outputTable {
+		return printOutput(newDiffOutput(args[0], args[1], d))
+	}
+	showCheckpointDiff(args[0], args[1], d)
+
+	return nil
+}
+
+func setupDrift() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "drift <checkpoint> <manifest>",
+		Short: "Compare a checkpoint with the container of a Kubernetes manifest",
+		RunE:  drift,
+		Args:  cobra.ExactArgs(2),
+	}
+	flags := cmd.Flags()
+	flags.StringVar(
+		&manifestCtr,
+		"container",
+		"",
+		"Name of the container in the manifest (default: the checkpointed container)",
+	)
+	addOutputFlag(cmd)
+
+	return cmd
+}
+
+func drift(cmd *cobra.Command, args []string) error {
+	if err := setOutputFormat(cmd); err != nil {
+		return err
+	}
+
+	input, manifest := args[0], args[1]
+	dir, err := extractCheckpoint(input)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}()
+
+	summary, err := loadCheckpointSummary(dir)
+	if err != nil {
+		return err
+	}
+	c, err := loadManifestContainer(manifest, manifestCtr, summary.Info.Name)
+	if err != nil {
+		return err
+	}
+	divergences, err := driftFromManifest(c, summary)
+	if err != nil {
+		return fmt.Errorf("unable to compare %s with %s: %w", input, manifest, err)
+	}
+
+	out := &driftOutput{
+		Checkpoint:  input,
+		Manifest:    manifest,
+		Container:   c.Name,
+		Diverged:    len(divergences) > 0,
+		Divergences: divergences,
+	}
+	if outputFormat != outputTable {
+		return printOutput(out)
+	}
+	showDrift(out)
+
+	return nil
 }
@@ -0,0 +1,297 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to compare the host which created a container
+// checkpoint with the host checkpointctl is running on
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/checkpoint-restore/go-criu/v6/crit/images"
+	"github.com/olekukonko/tablewriter"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	compatMatch      = "match"
+	compatCompatible = "compatible"
+	compatMismatch   = "mismatch"
+	compatUnknown    = "unknown"
+
+	verdictCompatible   = "likely compatible"
+	verdictPossibly     = "possibly incompatible"
+	verdictIncompatible = "incompatible"
+	verdictUnknown      = "unknown"
+
+	cgroupMountpoint = "/sys/fs/cgroup"
+)
+
+// criuLogHost matches the uname of the host CRIU logs at the start of a
+// dump as "Running on <nodename> <sysname> <release> <version> <machine>"
+var criuLogHost = regexp.MustCompile(`\bRunning on \S+ \S+ (\S+) .* (\S+)$`)
+
+// hostAttribute is a property of the host which affects whether a
+// checkpoint can be restored on it
+type hostAttribute struct {
+	Name       string `json:"name"`
+	Checkpoint string `json:"checkpoint"`
+	Host       string `json:"host"`
+	Status     string `json:"status"`
+}
+
+// compatOutput is the machine-readable result of the compat subcommand.
+// Score is the percentage of the known attributes which are compatible.
+type compatOutput struct {
+	Input      string          `json:"input"`
+	Verdict    string          `json:"verdict"`
+	Compatible bool            `json:"compatible"`
+	Score      int             `json:"score"`
+	Attributes []hostAttribute `json:"attributes"`
+}
+
+// hostFingerprint are the attributes of a host which are compared. The C
+// library of the host is not among them: the processes of the container use
+// the C library of the container image, which CRIU restores together with
+// their memory, and CRIU does not record the C library of the host.
+type hostFingerprint struct {
+	Kernel       string
+	Architecture string
+	Cgroup       string
+	Criu         string
+}
+
+// readCheckpointHost returns the kernel release and the architecture of the
+// host which created the checkpoint, if they are recorded in the dump log
+func readCheckpointHost(checkpointDirectory string) (string, string) {
+	path := findDumpLog(checkpointDirectory)
+	if path == "" {
+		return "", ""
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	// The host is logged right after the version
+	for i := 0; i < 50 && scanner.Scan(); i++ {
+		if m := criuLogHost.FindStringSubmatch(scanner.Text()); m != nil {
+			return m[1], m[2]
+		}
+	}
+
+	return "", ""
+}
+
+// checkpointCgroupVersion derives the cgroup version of the host which
+// created the checkpoint from the cgroup file system mounted in the container
+func checkpointCgroupVersion(checkpointDirectory string) string {
+	if !criuImageExists(checkpointDirectory, pstreeImg) || checkImageVersion(checkpointDirectory) != nil {
+		return ""
+	}
+	mounts, err := readMountpoints(checkpointDirectory)
+	if err != nil {
+		return ""
+	}
+
+	version := ""
+	for _, m := range mounts {
+		switch {
+		case m.GetFstype() == uint32(images.Fstype_CGROUP2) && m.GetMountpoint() == cgroupMountpoint:
+			return "v2"
+		case m.GetFstype() == uint32(images.Fstype_CGROUP):
+			version = "v1"
+		}
+	}
+
+	return version
+}
+
+// hostCgroupVersion returns the cgroup version of this host. Hosts with
+// cgroup v1 mount a tmpfs with one cgroup file system per controller.
+func hostCgroupVersion() string {
+	var fs unix.Statfs_t
+	if err := unix.Statfs(cgroupMountpoint, &fs); err != nil {
+		return ""
+	}
+	switch fs.Type {
+	case unix.CGROUP2_SUPER_MAGIC:
+		return "v2"
+	case unix.TMPFS_MAGIC:
+		return "v1"
+	}
+
+	return ""
+}
+
+func getCheckpointFingerprint(checkpointDirectory string) hostFingerprint {
+	kernel, arch := readCheckpointHost(checkpointDirectory)
+
+	return hostFingerprint{
+		Kernel:       kernel,
+		Architecture: arch,
+		Cgroup:       checkpointCgroupVersion(checkpointDirectory),
+		Criu:         readCriuVersion(checkpointDirectory),
+	}
+}
+
+func getHostFingerprint() (hostFingerprint, error) {
+	var uname unix.Utsname
+	if err := unix.Uname(&uname); err != nil {
+		return hostFingerprint{}, fmt.Errorf("failed to get uname: %w", err)
+	}
+	// Without a usable criu binary the CRIU version is not compared
+	criu, _ := installedCriuVersion()
+
+	return hostFingerprint{
+		Kernel:       unix.ByteSliceToString(uname.Release[:]),
+		Architecture: unix.ByteSliceToString(uname.Machine[:]),
+		Cgroup:       hostCgroupVersion(),
+		Criu:         criu,
+	}, nil
+}
+
+// compareHostVersions compares the versions of CRIU. A newer version on this
+// host is compatible, an older one might lack features the checkpoint
+// depends on.
+func compareHostVersions(checkpoint, host string) string {
+	switch {
+	case checkpoint == host:
+		return compatMatch
+	case compareCriuVersions(host, checkpoint) >= 0:
+		return compatCompatible
+	}
+
+	return compatMismatch
+}
+
+// compareKernelReleases compares the major and minor version of the kernel
+// releases. The patch level and the distribution specific suffix, as in
+// 5.14.0-362.el9, are ignored, as distributions backport features and the
+// suffixes of different distributions cannot be ordered.
+func compareKernelReleases(checkpoint, host string) string {
+	if checkpoint == host {
+		return compatMatch
+	}
+	if compareCriuVersions(kernelMajorMinor(host), kernelMajorMinor(checkpoint)) >= 0 {
+		return compatCompatible
+	}
+
+	return compatMismatch
+}
+
+// kernelMajorMinor returns the major and minor version of a kernel release
+func kernelMajorMinor(release string) string {
+	parts := strings.SplitN(release, ".", 3)
+	if len(parts) > 2 {
+		parts = parts[:2]
+	}
+
+	return strings.Join(parts, ".")
+}
+
+func compareEqual(checkpoint, host string) string {
+	if checkpoint == host {
+		return compatMatch
+	}
+
+	return compatMismatch
+}
+
+func getCompatOutput(input, checkpointDirectory string) (*compatOutput, error) {
+	host, err := getHostFingerprint()
+	if err != nil {
+		return nil, err
+	}
+	checkpoint := getCheckpointFingerprint(checkpointDirectory)
+
+	out := &compatOutput{Input: input}
+	for _, a := range []struct {
+		name             string
+		checkpoint, host string
+		compare          func(string, string) string
+	}{
+		{"Kernel", checkpoint.Kernel, host.Kernel, compareKernelReleases},
+		{"Architecture", checkpoint.Architecture, host.Architecture, compareEqual},
+		{"Cgroup version", checkpoint.Cgroup, host.Cgroup, compareEqual},
+		{"CRIU", checkpoint.Criu, host.Criu, compareHostVersions},
+	} {
+		attr := hostAttribute{
+			Name:       a.name,
+			Checkpoint: a.checkpoint,
+			Host:       a.host,
+			Status:     compatUnknown,
+		}
+		if a.checkpoint != "" && a.host != "" {
+			attr.Status = a.compare(a.checkpoint, a.host)
+		}
+		if attr.Checkpoint == "" {
+			attr.Checkpoint = versionUnknown
+		}
+		if attr.Host == "" {
+			attr.Host = versionUnknown
+		}
+		out.Attributes = append(out.Attributes, attr)
+	}
+
+	known, compatible := 0, 0
+	out.Verdict = verdictCompatible
+	for _, a := range out.Attributes {
+		switch a.Status {
+		case compatUnknown:
+			continue
+		case compatMismatch:
+			// CRIU cannot restore processes on another architecture
+			if a.Name == "Architecture" {
+				out.Verdict = verdictIncompatible
+			} else if out.Verdict != verdictIncompatible {
+				out.Verdict = verdictPossibly
+			}
+		default:
+			compatible++
+		}
+		known++
+	}
+	if known > 0 {
+		out.Score = compatible * 100 / known
+	} else {
+		out.Verdict = verdictUnknown
+	}
+	out.Compatible = out.Verdict == verdictCompatible
+
+	return out, nil
+}
+
+func showCompat(out *compatOutput) {
+	fmt.Printf("\nComparing the host of container checkpoint %s with this host\n\n", out.Input)
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{
+		"Attribute",
+		"Checkpoint",
+		"This Host",
+		"Result",
+	})
+	for _, a := range out.Attributes {
+		table.Append([]string{a.Name, a.Checkpoint, a.Host, a.Status})
+	}
+	table.Render()
+
+	fmt.Printf("\nVerdict: %s (%d%% of the known attributes are compatible)\n", out.Verdict, out.Score)
+	var unknown []string
+	for _, a := range out.Attributes {
+		if a.Status == compatUnknown {
+			unknown = append(unknown, a.Name)
+		}
+	}
+	if len(unknown) > 0 {
+		fmt.Printf("Not compared: %s\n", strings.Join(unknown, ", "))
+	}
+}
@@ -6,34 +6,79 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	metadata "github.com/checkpoint-restore/checkpointctl/lib"
-	"github.com/checkpoint-restore/go-criu/v6/crit"
+	"github.com/checkpoint-restore/go-criu/v6/crit/images"
 	"github.com/olekukonko/tablewriter"
 	spec "github.com/opencontainers/runtime-spec/specs-go"
 )
 
+// Host directories managed by the container engines. Mounts from these
+// directories are recreated by the engine during restore.
+var containerStorageDirs = []string{
+	"/var/lib/containers",
+	"/run/containers",
+	"/var/run/containers",
+	"/var/lib/containerd",
+	"/run/containerd",
+	"/var/run/containerd",
+}
+
 type containerMetadata struct {
 	Name    string `json:"name,omitempty"`
 	Attempt uint32 `json:"attempt,omitempty"`
 }
 
 type containerInfo struct {
-	Name    string
-	IP      string
-	MAC     string
-	Created string
-	Engine  string
+	Name      string
+	IP        string
+	MAC       string
+	Created   string
+	Engine    string
+	Pod       string
+	Namespace string
+}
+
+// podmanAddresses returns the static IP and MAC addresses of all networks
+// of the container, as Podman does not store the assigned addresses
+func podmanAddresses(containerConfig *metadata.ContainerConfig) ([]string, []string) {
+	var ips, macs []string
+	if containerConfig.StaticIP != "" {
+		ips = append(ips, containerConfig.StaticIP)
+	}
+	if containerConfig.StaticMAC != "" {
+		macs = append(macs, containerConfig.StaticMAC)
+	}
+	var networks []string
+	for name := range containerConfig.Networks {
+		networks = append(networks, name)
+	}
+	sort.Strings(networks)
+	for _, name := range networks {
+		n := containerConfig.Networks[name]
+		ips = append(ips, n.StaticIPs...)
+		if n.StaticMAC != "" {
+			macs = append(macs, n.StaticMAC)
+		}
+	}
+
+	return ips, macs
 }
 
 func getPodmanInfo(containerConfig *metadata.ContainerConfig, _ *spec.Spec) *containerInfo {
+	ips, macs := podmanAddresses(containerConfig)
+
 	return &containerInfo{
 		Name:    containerConfig.Name,
+		IP:      strings.Join(ips, ", "),
+		MAC:     strings.Join(macs, ", "),
 		Created: containerConfig.CreatedTime.Format(time.RFC3339),
 		Engine:  "Podman",
 	}
@@ -41,10 +86,47 @@ func getPodmanInfo(containerConfig *metadata.ContainerConfig, _ *spec.Spec) *con
 
 func getContainerdInfo(containerdStatus *metadata.ContainerdStatus, specDump *spec.Spec) *containerInfo {
 	return &containerInfo{
-		Name:    specDump.Annotations["io.kubernetes.cri.container-name"],
-		Created: time.Unix(0, containerdStatus.CreatedAt).Format(time.RFC3339),
-		Engine:  "containerd",
+		Name:      specDump.Annotations["io.kubernetes.cri.container-name"],
+		Created:   time.Unix(0, containerdStatus.CreatedAt).Format(time.RFC3339),
+		Engine:    "containerd",
+		Pod:       specDump.Annotations["io.kubernetes.cri.sandbox-name"],
+		Namespace: specDump.Annotations["io.kubernetes.cri.sandbox-namespace"],
+	}
+}
+
+// getUnknownInfo collects what is available about checkpoints created by
+// container managers which are not supported
+func getUnknownInfo(containerConfig *metadata.ContainerConfig, _ *spec.Spec) *containerInfo {
+	ci := &containerInfo{
+		Name:   containerConfig.Name,
+		Engine: "unknown",
+	}
+	if !containerConfig.CreatedTime.IsZero() {
+		ci.Created = containerConfig.CreatedTime.Format(time.RFC3339)
 	}
+
+	return ci
+}
+
+// indexedAnnotations returns the values of the annotation key and of the
+// indexed annotations key.0, key.1, ... which CRI-O uses for containers
+// with multiple addresses
+func indexedAnnotations(specDump *spec.Spec, key string) []string {
+	var values []string
+	if v, ok := specDump.Annotations[key]; ok && v != "" {
+		values = append(values, v)
+	}
+	for i := 0; ; i++ {
+		v, ok := specDump.Annotations[fmt.Sprintf("%s.%d", key, i)]
+		if !ok {
+			break
+		}
+		if v != "" {
+			values = append(values, v)
+		}
+	}
+
+	return values
 }
 
 func getCRIOInfo(_ *metadata.ContainerConfig, specDump *spec.Spec) (*containerInfo, error) {
@@ -54,45 +136,129 @@ func getCRIOInfo(_ *metadata.ContainerConfig, specDump *spec.Spec) (*containerIn
 	}
 
 	return &containerInfo{
-		IP:      specDump.Annotations["io.kubernetes.cri-o.IP.0"],
-		Name:    cm.Name,
-		Created: specDump.Annotations["io.kubernetes.cri-o.Created"],
-		Engine:  "CRI-O",
+		IP:        strings.Join(indexedAnnotations(specDump, "io.kubernetes.cri-o.IP"), ", "),
+		MAC:       strings.Join(indexedAnnotations(specDump, "io.kubernetes.cri-o.MAC"), ", "),
+		Name:      cm.Name,
+		Created:   specDump.Annotations["io.kubernetes.cri-o.Created"],
+		Engine:    "CRI-O",
+		Pod:       specDump.Annotations["io.kubernetes.pod.name"],
+		Namespace: specDump.Annotations["io.kubernetes.pod.namespace"],
 	}, nil
 }
 
-func showContainerCheckpoint(checkpointDirectory string) error {
-	var (
-		row []string
-		ci  *containerInfo
-	)
+// engineDetector recognizes the checkpoints of a container engine
+type engineDetector struct {
+	// Detect returns true if the checkpoint was created by the engine
+	Detect func(checkpointDirectory string, specDump *spec.Spec) bool
+	Info   func(checkpointDirectory string, containerConfig *metadata.ContainerConfig, specDump *spec.Spec) (*containerInfo, error)
+}
+
+// engineDetectors are tried in order to find the engine of a checkpoint
+var engineDetectors = []engineDetector{
+	// Podman
+	{
+		Detect: func(_ string, specDump *spec.Spec) bool {
+			return specDump.Annotations["io.container.manager"] == "libpod"
+		},
+		Info: func(_ string, containerConfig *metadata.ContainerConfig, specDump *spec.Spec) (*containerInfo, error) {
+			return getPodmanInfo(containerConfig, specDump), nil
+		},
+	},
+	// CRI-O
+	{
+		Detect: func(_ string, specDump *spec.Spec) bool {
+			return specDump.Annotations["io.container.manager"] == "cri-o"
+		},
+		Info: func(_ string, containerConfig *metadata.ContainerConfig, specDump *spec.Spec) (*containerInfo, error) {
+			return getCRIOInfo(containerConfig, specDump)
+		},
+	},
+	// containerd does not set an annotation but writes a status file
+	{
+		Detect: func(checkpointDirectory string, _ *spec.Spec) bool {
+			_, err := os.Stat(filepath.Join(checkpointDirectory, metadata.StatusFile))
+			return err == nil
+		},
+		Info: func(checkpointDirectory string, _ *metadata.ContainerConfig, specDump *spec.Spec) (*containerInfo, error) {
+			containerdStatus, _, err := metadata.ReadContainerCheckpointStatusFile(checkpointDirectory)
+			if err != nil {
+				return nil, err
+			}
+			return getContainerdInfo(containerdStatus, specDump), nil
+		},
+	},
+}
+
+func getContainerInfo(checkpointDirectory string, containerConfig *metadata.ContainerConfig, specDump *spec.Spec) (*containerInfo, error) {
+	var ci *containerInfo
+	for _, d := range engineDetectors {
+		if !d.Detect(checkpointDirectory, specDump) {
+			continue
+		}
+		var err error
+		ci, err = d.Info(checkpointDirectory, containerConfig, specDump)
+		if err != nil {
+			return nil, fmt.Errorf("getting container checkpoint information failed: %w", err)
+		}
+		break
+	}
+	if ci == nil {
+		if !bestEffort {
+			return nil, fmt.Errorf("%w found: %s", metadata.ErrUnknownContainerManager, specDump.Annotations["io.container.manager"])
+		}
+		ci = getUnknownInfo(containerConfig, specDump)
+	}
+	applyPodMapping(ci, containerConfig.ID)
+
+	return ci, nil
+}
+
+// shortID truncates a container ID to the length displayed by the container engines
+func shortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+
+	return id
+}
+
+// readContainerCheckpoint reads the metadata of a container checkpoint
+// written by the container engine
+func readContainerCheckpoint(checkpointDirectory string) (*metadata.ContainerConfig, *spec.Spec, *containerInfo, error) {
 	containerConfig, _, err := metadata.ReadContainerCheckpointConfigDump(checkpointDirectory)
 	if err != nil {
-		return err
+		return nil, nil, nil, err
 	}
 	specDump, _, err := metadata.ReadContainerCheckpointSpecDump(checkpointDirectory)
 	if err != nil {
-		return err
+		return nil, nil, nil, err
 	}
-
-	switch m := specDump.Annotations["io.container.manager"]; m {
-	case "libpod":
-		ci = getPodmanInfo(containerConfig, specDump)
-	case "cri-o":
-		ci, err = getCRIOInfo(containerConfig, specDump)
-	default:
-		containerdStatus, _, _ := metadata.ReadContainerCheckpointStatusFile(checkpointDirectory)
-		if containerdStatus == nil {
-			return fmt.Errorf("unknown container manager found: %s", m)
-		}
-		ci = getContainerdInfo(containerdStatus, specDump)
+	ci, err := getContainerInfo(checkpointDirectory, containerConfig, specDump)
+	if err != nil {
+		return nil, nil, nil, err
 	}
 
+	return containerConfig, specDump, ci, nil
+}
+
+func showContainerCheckpoint(input, checkpointDirectory string) error {
+	var row []string
+	containerConfig, specDump, ci, err := readContainerCheckpoint(checkpointDirectory)
 	if err != nil {
-		return fmt.Errorf("getting container checkpoint information failed: %w", err)
+		return err
 	}
 
-	fmt.Printf("\nDisplaying container checkpoint data from %s\n\n", checkpointDirectory)
+	if outputFormat == outputSVG {
+		return showMemorySVG(checkpointDirectory, ci)
+	}
+	if outputFormat == outputDOT {
+		return showProcessTreeDOT(checkpointDirectory, ci)
+	}
+	if outputFormat != outputTable {
+		return showContainerCheckpointOutput(checkpointDirectory, containerConfig, specDump, ci)
+	}
+
+	printCaption("Displaying container checkpoint data from %s\n", input)
 
 	table := tablewriter.NewWriter(os.Stdout)
 	header := []string{
@@ -106,11 +272,7 @@ func showContainerCheckpoint(checkpointDirectory string) error {
 
 	row = append(row, ci.Name)
 	row = append(row, containerConfig.RootfsImageName)
-	if len(containerConfig.ID) > 12 {
-		row = append(row, containerConfig.ID[:12])
-	} else {
-		row = append(row, containerConfig.ID)
-	}
+	row = append(row, shortID(containerConfig.ID))
 
 	row = append(row, containerConfig.OCIRuntime)
 	row = append(row, ci.Created)
@@ -124,110 +286,668 @@ func showContainerCheckpoint(checkpointDirectory string) error {
 		header = append(header, "MAC")
 		row = append(row, ci.MAC)
 	}
+	if ci.Pod != "" {
+		header = append(header, "Pod")
+		row = append(row, ci.Pod)
+	}
+	if ci.Namespace != "" {
+		header = append(header, "Namespace")
+		row = append(row, ci.Namespace)
+	}
+
+	if driver := getStorageDriver(containerConfig); driver != "unknown" {
+		header = append(header, "Storage Driver")
+		row = append(row, driver)
+	}
+
+	// The number of threads is only shown if the process tree can be read
+	if threads, ok := getThreadCount(checkpointDirectory); ok {
+		header = append(header, "Threads")
+		row = append(row, formatCount(int64(threads)))
+	}
 
 	size, err := getCheckpointSize(checkpointDirectory)
-	if err != nil {
+	switch {
+	case err == nil && sizeBreakdown:
+		// The size is broken down in its own table below
+	case err == nil:
+		header = append(header, "CHKPT Size")
+		row = append(row, formatSize(size))
+	case bestEffort:
+		fmt.Fprintf(os.Stderr, "Warning: unable to determine checkpoint size: %v\n", err)
+	default:
 		return err
 	}
 
-	header = append(header, "CHKPT Size")
-	row = append(row, metadata.ByteToString(size))
-
 	// Display root fs diff size if available
 	fi, err := os.Lstat(filepath.Join(checkpointDirectory, metadata.RootFsDiffTar))
 	if err == nil {
 		if fi.Size() != 0 {
 			header = append(header, "Root Fs Diff Size")
-			row = append(row, metadata.ByteToString(fi.Size()))
+			row = append(row, formatSize(fi.Size()))
 		}
 	}
 
 	table.SetAutoMergeCells(true)
 	table.SetRowLine(true)
-	table.SetHeader(header)
+	setTableHeader(table, header)
 	table.Append(row)
 	table.Render()
 
+	if sizeBreakdown {
+		if err := optionalSection(showSizeBreakdown(checkpointDirectory)); err != nil {
+			return err
+		}
+	}
+
+	if err := checkThresholds(checkpointDirectory, size); err != nil {
+		return err
+	}
+	if checkMounts {
+		checkMountSources(specDump)
+	}
+	if checkProfile {
+		checkAppArmorProfile(specDump)
+	}
+	if cmpCriuVer {
+		if err := showCriuVersionComparison(checkpointDirectory); err != nil {
+			return err
+		}
+	}
+
 	if showMounts {
+		var mountpoints map[string]*images.MntEntry
+		if mountIDs {
+			m, err := getMountpoints(checkpointDirectory)
+			if err := optionalSection(err); err != nil {
+				return err
+			}
+			mountpoints = m
+		}
+
 		table = tablewriter.NewWriter(os.Stdout)
-		table.SetHeader([]string{
+		header := []string{
 			"Destination",
 			"Type",
 			"Source",
-		})
+		}
+		if mountIDs {
+			header = append(header, "Mount ID", "Device")
+		}
+		if mountSizes {
+			header = append(header, "Source Size")
+		}
+		setTableHeader(table, header)
 		// Get overview of mounts from spec.dump
-		for _, data := range specDump.Mounts {
-			table.Append([]string{
+		mounts := selectMounts(specDump.Mounts)
+		for _, data := range mounts {
+			row := []string{
 				data.Destination,
 				data.Type,
-				func() string {
-					if fullPaths {
-						return data.Source
-					}
-					return shortenPath(data.Source)
-				}(),
-			})
+				mountSource(data.Source),
+			}
+			if mountIDs {
+				id, dev := "-", "-"
+				if m, ok := mountpoints[data.Destination]; ok {
+					id = fmt.Sprintf("%d", m.GetMntId())
+					dev = formatKernelDevice(m.GetRootDev())
+				}
+				row = append(row, id, dev)
+			}
+			if mountSizes {
+				size := "-"
+				if s, ok := mountSourceSize(data.Source); ok {
+					size = formatSize(s)
+				}
+				row = append(row, size)
+			}
+			table.Append(row)
+		}
+		printCaption("Overview of Mounts")
+		if len(mounts) == 0 && len(mountTypes) > 0 {
+			fmt.Println("No mounts matching filter")
+		} else {
+			table.Render()
+		}
+	}
+
+	if showLabels {
+		if err := optionalSection(showContainerLabels(containerConfig, specDump)); err != nil {
+			return err
+		}
+	}
+
+	// Annotations are the best hint about the origin of checkpoints
+	// from container managers which are not supported
+	if ci.Engine == "unknown" || showAnnots {
+		showAnnotations(specDump)
+	}
+
+	if restoreAnnots {
+		showRestoreAnnotations(specDump)
+	}
+
+	if showEnv && envMerged {
+		if err := optionalSection(showMergedEnvironment(checkpointDirectory, containerConfig, specDump)); err != nil {
+			return err
+		}
+	} else if showEnv {
+		showEnvironment(specDump)
+	}
+
+	if showCmd {
+		showCommandLine(specDump)
+	}
+
+	if showTZ {
+		showTimezone(specDump)
+	}
+
+	if macProfile {
+		showMACProfile(specDump)
+	}
+
+	if showCaps {
+		showCapabilities(specDump, ci.Engine)
+	}
+
+	if showSeccomp {
+		if err := optionalSection(showSeccompProfile(checkpointDirectory, specDump)); err != nil {
+			return err
+		}
+	}
+
+	if showUlimits {
+		showConfiguredUlimits(containerConfig)
+	}
+
+	if lifecycle {
+		showLifecycle(containerConfig)
+	}
+
+	if showVersions {
+		showCheckpointVersions(checkpointDirectory)
+	}
+
+	if validSpec {
+		if err := showSpecValidation(specDump); err != nil {
+			return err
+		}
+	}
+
+	if needsCriuImages() {
+		if err := optionalSection(checkImageVersion(checkpointDirectory)); err != nil {
+			return err
+		}
+	}
+
+	if reqFeats {
+		if err := optionalSection(showRequiredFeatures(checkpointDirectory)); err != nil {
+			return err
+		}
+	}
+
+	if procIDs {
+		if err := optionalSection(showProcessIDs(checkpointDirectory)); err != nil {
+			return err
+		}
+	}
+
+	if listProcs {
+		if err := optionalSection(showProcesses(checkpointDirectory, sortBy)); err != nil {
+			return err
+		}
+	}
+
+	if psTree {
+		if err := optionalSection(showProcessTree(checkpointDirectory)); err != nil {
+			return err
+		}
+	}
+
+	if showSched {
+		if err := optionalSection(showScheduling(checkpointDirectory)); err != nil {
+			return err
+		}
+	}
+
+	if showTimers {
+		if err := optionalSection(showProcessTimers(checkpointDirectory)); err != nil {
+			return err
+		}
+	}
+
+	if showHostname {
+		if err := optionalSection(showHostnames(checkpointDirectory, specDump)); err != nil {
+			return err
+		}
+	}
+
+	if showTTY {
+		if err := optionalSection(showTerminals(checkpointDirectory, specDump)); err != nil {
+			return err
+		}
+	}
+
+	if netFiles {
+		if err := optionalSection(showNetworkFiles(checkpointDirectory, specDump)); err != nil {
+			return err
+		}
+	}
+
+	if sharedMemory {
+		if err := optionalSection(showSharedMemory(checkpointDirectory)); err != nil {
+			return err
+		}
+	}
+
+	if showIPC {
+		if err := optionalSection(showIpcObjects(checkpointDirectory)); err != nil {
+			return err
+		}
+	}
+
+	if memTracking {
+		showMemoryTracking(checkpointDirectory)
+	}
+
+	if memUsage {
+		if err := optionalSection(showMemoryLimitUsage(checkpointDirectory, specDump)); err != nil {
+			return err
+		}
+	}
+
+	if memPages {
+		if err := optionalSection(showProcessMemory(checkpointDirectory)); err != nil {
+			return err
+		}
+	}
+
+	if imgSizes {
+		if err := optionalSection(showImageSizes(checkpointDirectory)); err != nil {
+			return err
+		}
+	}
+
+	if ghostFiles {
+		if err := optionalSection(showGhostFiles(checkpointDirectory)); err != nil {
+			return err
 		}
-		fmt.Println("\nOverview of Mounts")
-		table.Render()
 	}
 
 	if printStats {
-		cpDir, err := os.Open(checkpointDirectory)
-		if err != nil {
+		if err := optionalSection(showCriuStatistics(checkpointDirectory)); err != nil {
 			return err
 		}
-		defer cpDir.Close()
+	}
 
-		// Get dump statistics with crit
-		dumpStatistics, err := crit.GetDumpStats(cpDir.Name())
-		if err != nil {
-			return fmt.Errorf("unable to display checkpointing statistics: %w", err)
+	if statsDelta {
+		if err := optionalSection(showStatsComparison(checkpointDirectory)); err != nil {
+			return err
 		}
+	}
 
-		table = tablewriter.NewWriter(os.Stdout)
-		table.SetHeader([]string{
-			"Freezing Time",
-			"Frozen Time",
-			"Memdump Time",
-			"Memwrite Time",
-			"Pages Scanned",
-			"Pages Written",
-		})
-		table.Append([]string{
-			fmt.Sprintf("%d us", dumpStatistics.GetFreezingTime()),
-			fmt.Sprintf("%d us", dumpStatistics.GetFrozenTime()),
-			fmt.Sprintf("%d us", dumpStatistics.GetMemdumpTime()),
-			fmt.Sprintf("%d us", dumpStatistics.GetMemwriteTime()),
-			fmt.Sprintf("%d", dumpStatistics.GetPagesScanned()),
-			fmt.Sprintf("%d", dumpStatistics.GetPagesWritten()),
-		})
-		fmt.Println("\nCRIU dump statistics")
-		table.Render()
+	if showDuration {
+		showCheckpointDuration(checkpointDirectory)
+	}
+
+	if dumpLog {
+		if err := optionalSection(showDumpLog(checkpointDirectory, dumpLogLines)); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-func dirSize(path string) (size int64, err error) {
-	err = filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
+func showAnnotations(specDump *spec.Spec) {
+	keys := make([]string, 0, len(specDump.Annotations))
+	for k := range specDump.Annotations {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	table := tablewriter.NewWriter(os.Stdout)
+	setTableHeader(table, []string{
+		"Annotation",
+		"Value",
+	})
+	for _, k := range keys {
+		table.Append([]string{k, displayValue(specDump.Annotations[k])})
+	}
+	printCaption("Annotations")
+	table.Render()
+}
+
+// showTimezone prints the timezone and locale related settings of the
+// container. A timezone mismatch between the checkpoint and the restore
+// host can cause subtle misbehaviour of the restored processes.
+func showTimezone(specDump *spec.Spec) {
+	var env []string
+	if specDump.Process != nil {
+		env = specDump.Process.Env
+	}
+
+	tz, ok := getEnvValue(env, "TZ")
+	if !ok {
+		tz = "-"
+	}
+	localtime := "no"
+	for _, m := range specDump.Mounts {
+		if m.Destination == "/etc/localtime" {
+			localtime = "yes (" + mountSource(m.Source) + ")"
 		}
-		if !info.IsDir() {
-			size += info.Size()
+	}
+	locale, ok := getEnvValue(env, "LC_ALL")
+	if !ok {
+		if locale, ok = getEnvValue(env, "LANG"); !ok {
+			locale = "-"
 		}
+	}
 
-		return err
+	table := tablewriter.NewWriter(os.Stdout)
+	setTableHeader(table, []string{
+		"TZ",
+		"Localtime Mounted",
+		"Locale",
 	})
+	table.Append([]string{tz, localtime, locale})
+	printCaption("Timezone and locale")
+	table.Render()
+}
+
+// showHostnames prints the hostname configured in the spec next to the
+// hostname of the running container, which can be changed at runtime.
+func showHostnames(checkpointDirectory string, specDump *spec.Spec) error {
+	runtimeHostname, found, err := readRuntimeHostname(checkpointDirectory)
+	if err != nil {
+		return fmt.Errorf("unable to display hostname: %w", err)
+	}
+
+	specHostname := specDump.Hostname
+	if specHostname == "" {
+		specHostname = "-"
+	}
+	changed := "-"
+	if !found {
+		runtimeHostname = "-"
+	} else if runtimeHostname != specDump.Hostname {
+		changed = "yes"
+	} else {
+		changed = "no"
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	setTableHeader(table, []string{
+		"Spec Hostname",
+		"Runtime Hostname",
+		"Changed",
+	})
+	table.Append([]string{specHostname, runtimeHostname, changed})
+	printCaption("Hostname")
+	table.Render()
+
+	return nil
+}
+
+// getEnvValue returns the value of the environment variable name
+// from a list of KEY=value entries
+func getEnvValue(env []string, name string) (string, bool) {
+	for _, e := range env {
+		if key, value, found := strings.Cut(e, "="); found && key == name {
+			return value, true
+		}
+	}
+
+	return "", false
+}
+
+// getThreadCount returns the total number of threads of the checkpointed
+// processes. It is false if the checkpoint has no readable process tree.
+func getThreadCount(checkpointDirectory string) (int, bool) {
+	if !criuImageExists(checkpointDirectory, pstreeImg) || checkImageVersion(checkpointDirectory) != nil {
+		return 0, false
+	}
+	threads, err := countThreads(checkpointDirectory)
+	if err != nil {
+		return 0, false
+	}
+
+	return threads, true
+}
+
+// getStorageDriver returns the storage driver which provided the root file
+// system of the container, as restoring requires a compatible storage setup.
+// Podman records the directory of the container in containers/storage, which
+// is named after the driver, like overlay-containers/<id>/userdata. The other
+// engines do not record the driver.
+func getStorageDriver(containerConfig *metadata.ContainerConfig) string {
+	for _, part := range strings.Split(containerConfig.StaticDir, string(filepath.Separator)) {
+		if driver := strings.TrimSuffix(part, "-containers"); driver != part && driver != "" {
+			return driver
+		}
+	}
+
+	return "unknown"
+}
+
+// enableAllSections selects every section of the show subcommand for --all
+func enableAllSections() {
+	for _, section := range []*bool{
+		&showMounts,
+		&showEnv,
+		&showCmd,
+		&showTZ,
+		&macProfile,
+		&showCaps,
+		&showDropped,
+		&showSeccomp,
+		&showUlimits,
+		&lifecycle,
+		&showVersions,
+		&validSpec,
+		&restoreAnnots,
+		&showLabels,
+		&showAnnots,
+		&reqFeats,
+		&procIDs,
+		&listProcs,
+		&psTree,
+		&showSched,
+		&showTimers,
+		&showHostname,
+		&showTTY,
+		&netFiles,
+		&sharedMemory,
+		&showIPC,
+		&memTracking,
+		&memUsage,
+		&memPages,
+		&imgSizes,
+		&ghostFiles,
+		&printStats,
+		&statsDelta,
+		&showDuration,
+		&dumpLog,
+	} {
+		*section = true
+	}
+}
+
+// optionalSection turns the error of a section into a warning with --all,
+// as not every checkpoint contains the data for every section
+func optionalSection(err error) error {
+	if err != nil && showAll {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return nil
+	}
+
+	return err
+}
+
+// needsCriuImages returns true if any of the selected options
+// requires decoding the CRIU images of the checkpoint
+func needsCriuImages() bool {
+	return reqFeats || procIDs || listProcs || psTree || showSched || showTimers || showHostname || showTTY || sharedMemory || showIPC || memPages || ghostFiles || envMerged || mountIDs
+}
+
+func dirSize(path string) (size int64, err error) {
+	entries, err := dirEntrySizes(path)
+	for _, e := range entries {
+		size += e.Size
+	}
 
 	return size, err
 }
 
+// dirEntrySizes returns the size of each file and subdirectory directly
+// below path. The size of a subdirectory is the size of all its files.
+func dirEntrySizes(path string) ([]fileSize, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var sizes []fileSize
+	for _, entry := range entries {
+		var size int64
+		err := filepath.Walk(filepath.Join(path, entry.Name()), func(_ string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() {
+				size += info.Size()
+			}
+
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		sizes = append(sizes, fileSize{Path: entry.Name(), Size: size})
+	}
+
+	return sizes, nil
+}
+
 func getCheckpointSize(path string) (size int64, err error) {
-	dir := filepath.Join(path, metadata.CheckpointDirectory)
+	return dirSize(imagesDirectory(path))
+}
+
+// isExternalMount returns true for bind mounts with a source on the host
+// which is not managed by the container engine
+func isExternalMount(m spec.Mount) bool {
+	bind := m.Type == "bind"
+	for _, o := range m.Options {
+		if o == "bind" || o == "rbind" {
+			bind = true
+		}
+	}
+	if !bind || !strings.HasPrefix(m.Source, "/") {
+		return false
+	}
+	for _, dir := range containerStorageDirs {
+		if m.Source == dir || strings.HasPrefix(m.Source, dir+"/") {
+			return false
+		}
+	}
+
+	return true
+}
+
+// checkMountSources prints a warning to stderr for each bind mount
+// with a source which is missing on this host, as the restore would fail.
+func checkMountSources(specDump *spec.Spec) {
+	for _, m := range specDump.Mounts {
+		if !isExternalMount(m) {
+			continue
+		}
+		_, err := os.Stat(m.Source)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			fmt.Fprintf(os.Stderr, "Warning: source %s of bind mount %s does not exist\n", m.Source, m.Destination)
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "Warning: unable to check source of bind mount %s: %v\n", m.Destination, err)
+		}
+	}
+}
+
+// getMountpoints returns the mounts captured by CRIU by their mount point
+func getMountpoints(checkpointDirectory string) (map[string]*images.MntEntry, error) {
+	if !criuImageExists(checkpointDirectory, pstreeImg) {
+		return nil, fmt.Errorf("unable to display mount IDs: %s %w", pstreeImg, metadata.ErrSectionUnavailable)
+	}
+	if err := checkImageVersion(checkpointDirectory); err != nil {
+		return nil, err
+	}
+	mounts, err := readMountpoints(checkpointDirectory)
+	if err != nil {
+		return nil, fmt.Errorf("unable to display mount IDs: %w", err)
+	}
+
+	mountpoints := make(map[string]*images.MntEntry)
+	for _, m := range mounts {
+		mountpoints[m.GetMountpoint()] = m
+	}
+
+	return mountpoints, nil
+}
+
+// formatKernelDevice formats a device number in the encoding used by
+// CRIU, with the minor number in the lower 20 bits, as major:minor
+func formatKernelDevice(dev uint32) string {
+	return fmt.Sprintf("%d:%d", dev>>20, dev&(1<<20-1))
+}
+
+// selectMounts returns the mounts of the types given with --mount-type,
+// sorted by destination with --mount-sort
+func selectMounts(mounts []spec.Mount) []spec.Mount {
+	types := make(map[string]bool)
+	for _, t := range mountTypes {
+		types[t] = true
+	}
+	var selected []spec.Mount
+	for _, m := range mounts {
+		if len(types) == 0 || types[m.Type] {
+			selected = append(selected, m)
+		}
+	}
+	if mountSort {
+		sort.SliceStable(selected, func(i, j int) bool {
+			return selected[i].Destination < selected[j].Destination
+		})
+	}
+
+	return selected
+}
+
+// mountSourceSize returns the size of the files below the source of a
+// mount. It is only known for sources which are directories on this host,
+// which is not the case if the checkpoint is inspected on another host.
+func mountSourceSize(source string) (int64, bool) {
+	if !filepath.IsAbs(source) {
+		// The source of mounts like proc or tmpfs is no path
+		return 0, false
+	}
+	if fi, err := os.Stat(source); err != nil || !fi.IsDir() {
+		return 0, false
+	}
+	size, err := dirSize(source)
+	if err != nil {
+		return 0, false
+	}
+
+	return size, true
+}
+
+// mountSource returns the source of a mount as it should be
+// displayed depending on the --full-paths option
+func mountSource(source string) string {
+	if fullPaths {
+		return source
+	}
 
-	return dirSize(dir)
+	return shortenPath(source)
 }
 
 func shortenPath(path string) string {
@@ -0,0 +1,562 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to read the CRIU images of container checkpoints
+
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime/debug"
+	"strconv"
+	"strings"
+
+	metadata "github.com/checkpoint-restore/checkpointctl/lib"
+	"github.com/checkpoint-restore/go-criu/v6/crit"
+	"github.com/checkpoint-restore/go-criu/v6/crit/images"
+	"github.com/checkpoint-restore/go-criu/v6/magic"
+	"github.com/olekukonko/tablewriter"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	inventoryImg = "inventory.img"
+	pstreeImg    = "pstree.img"
+	fileLocksImg = "file-locks.img"
+	unixSkImg    = "unixsk.img"
+	ttyInfoImg   = "tty-info.img"
+	ttyDataImg   = "tty-data.img"
+
+	// CRIU image format versions (CRTOOLS_IMAGES_V1 and CRTOOLS_IMAGES_V1_1)
+	// which can be decoded by the embedded version of crit
+	minImageVersion = 1
+	maxImageVersion = 2
+
+	// Memory pages are stored in multiples of the smallest supported page size
+	pageSize = 4096
+
+	// Values of network_lock_method in inventory.img
+	networkLockNftables = 1
+
+	// Flag of external unix sockets in unixsk.img
+	unixSkExtern = 0x1
+)
+
+const goCriuModule = "github.com/checkpoint-restore/go-criu/v6"
+
+// CRIU logs its version at the start of the dump log like "Version: 3.17.1 (gitid 0)"
+var criuLogVersion = regexp.MustCompile(`\bVersion: ([0-9][^ ]*)`)
+
+// unsupportedImageError is returned for CRIU images of a type which is
+// unknown to the crit library embedded into checkpointctl. These images
+// have been written by a newer version of CRIU.
+type unsupportedImageError struct {
+	Image       string
+	CriuVersion string
+}
+
+func (e *unsupportedImageError) Error() string {
+	msg := fmt.Sprintf("%s has an image type which is not supported by the embedded crit library (%s)", e.Image, embeddedCritVersion())
+	if e.CriuVersion != "" {
+		return fmt.Sprintf("%s: the checkpoint was created with CRIU %s, a build of checkpointctl with a go-criu version supporting CRIU %s is required", msg, e.CriuVersion, e.CriuVersion)
+	}
+
+	return msg + ": a build of checkpointctl with a newer go-criu version is required"
+}
+
+// embeddedCritVersion returns the version of go-criu checkpointctl was built with
+func embeddedCritVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, dep := range info.Deps {
+			if dep.Path == goCriuModule {
+				return "go-criu " + dep.Version
+			}
+		}
+	}
+
+	return "go-criu v6"
+}
+
+// isUnsupportedImage returns true if crit failed to decode the image file
+// path because it does not know the magic of the image. Only files which
+// start with the magic of all CRIU images are considered, anything else
+// is not a CRIU image at all.
+func isUnsupportedImage(path string, err error) bool {
+	msg := err.Error()
+	if !strings.HasPrefix(msg, "Unknown magic") && !strings.HasPrefix(msg, "No handler found for magic") {
+		return false
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(f, buf); err != nil {
+		return false
+	}
+	m := uint64(binary.LittleEndian.Uint32(buf))
+	magics := magic.LoadMagic()
+
+	return m == magics.ByName["IMG_COMMON"] || m == magics.ByName["IMG_SERVICE"]
+}
+
+// readCriuVersion returns the version of CRIU which created the
+// checkpoint, if it is recorded in the dump log
+func readCriuVersion(checkpointDirectory string) string {
+	path := findDumpLog(checkpointDirectory)
+	if path == "" {
+		return ""
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	// The version is logged within the first lines
+	for i := 0; i < 50 && scanner.Scan(); i++ {
+		if m := criuLogVersion.FindStringSubmatch(scanner.Text()); m != nil {
+			return m[1]
+		}
+	}
+
+	return ""
+}
+
+// installedCriuVersion returns the version of the criu binary in PATH,
+// which prints it like the dump log as "Version: 3.17.1"
+func installedCriuVersion() (string, error) {
+	path, err := exec.LookPath("criu")
+	if err != nil {
+		return "", err
+	}
+	out, err := exec.Command(path, "--version").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to run %s --version: %w", path, err)
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		if m := criuLogVersion.FindStringSubmatch(line); m != nil {
+			return m[1], nil
+		}
+	}
+
+	return "", fmt.Errorf("no version in the output of %s --version", path)
+}
+
+// compareCriuVersions returns -1, 0 or 1 if the CRIU version a is older,
+// equal or newer than b. Only the leading digits of each component are
+// compared, so that "3.19-rc1" equals "3.19".
+func compareCriuVersions(a, b string) int {
+	pa, pb := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var na, nb int
+		if i < len(pa) {
+			na = leadingNumber(pa[i])
+		}
+		if i < len(pb) {
+			nb = leadingNumber(pb[i])
+		}
+		switch {
+		case na < nb:
+			return -1
+		case na > nb:
+			return 1
+		}
+	}
+
+	return 0
+}
+
+func leadingNumber(s string) int {
+	end := 0
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+	n, _ := strconv.Atoi(s[:end])
+
+	return n
+}
+
+// showCriuVersionComparison prints the CRIU version which created the
+// checkpoint next to the version of the criu binary on this host, which
+// will be used to restore it
+func showCriuVersionComparison(checkpointDirectory string) error {
+	installed, err := installedCriuVersion()
+	if errors.Is(err, exec.ErrNotFound) {
+		fmt.Fprintln(os.Stderr, "Note: no criu binary found in PATH, skipping the CRIU version comparison")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to compare CRIU versions: %w", err)
+	}
+	checkpoint := readCriuVersion(checkpointDirectory)
+	recorded := checkpoint
+	if recorded == "" {
+		recorded = "unknown"
+	}
+
+	printCaption("CRIU Version")
+	table := tablewriter.NewWriter(os.Stdout)
+	setTableHeader(table, []string{"Checkpoint", "Installed"})
+	table.Append([]string{recorded, installed})
+	table.Render()
+
+	switch {
+	case checkpoint == "":
+		fmt.Println("The CRIU version of the checkpoint is not recorded in its dump log")
+	case compareCriuVersions(installed, checkpoint) < 0:
+		fmt.Fprintf(
+			os.Stderr,
+			"Warning: the installed CRIU %s is older than CRIU %s which created the checkpoint, "+
+				"restoring fails if the checkpoint uses features the installed version does not support\n",
+			installed, checkpoint,
+		)
+	case compareCriuVersions(installed, checkpoint) > 0:
+		fmt.Printf("The installed CRIU %s is newer than CRIU %s which created the checkpoint, CRIU restores checkpoints of older versions\n", installed, checkpoint)
+	default:
+		fmt.Println("The installed CRIU matches the version which created the checkpoint")
+	}
+
+	return nil
+}
+
+// criuImageError returns a specific error for the image file path, which
+// cannot be decoded by the embedded crit library
+func criuImageError(checkpointDirectory, path string, err error) error {
+	name := filepath.Base(path)
+	if isUnsupportedImage(path, err) {
+		return &unsupportedImageError{
+			Image:       name,
+			CriuVersion: readCriuVersion(checkpointDirectory),
+		}
+	}
+
+	return fmt.Errorf("failed to decode %s: %w", name, err)
+}
+
+// imagesDirectoryName returns the name of the subdirectory of the checkpoint
+// which contains the CRIU images. Container engines use "checkpoint", other
+// tools name it differently. Without --images-dir the first subdirectory
+// which contains inventory.img is used.
+func imagesDirectoryName(checkpointDirectory string) string {
+	if imagesDir != "" {
+		return imagesDir
+	}
+	if _, err := os.Stat(filepath.Join(checkpointDirectory, metadata.CheckpointDirectory)); err == nil {
+		return metadata.CheckpointDirectory
+	}
+	entries, err := os.ReadDir(checkpointDirectory)
+	if err != nil {
+		return metadata.CheckpointDirectory
+	}
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(checkpointDirectory, e.Name(), inventoryImg)); err == nil {
+			return e.Name()
+		}
+	}
+
+	return metadata.CheckpointDirectory
+}
+
+// imagesDirectory returns the path of the directory with the CRIU images
+func imagesDirectory(checkpointDirectory string) string {
+	return filepath.Join(checkpointDirectory, imagesDirectoryName(checkpointDirectory))
+}
+
+// criuImageExists returns true if the image name is part
+// of the CRIU images of the checkpoint
+func criuImageExists(checkpointDirectory, name string) bool {
+	_, err := os.Stat(filepath.Join(imagesDirectory(checkpointDirectory), name))
+	return err == nil
+}
+
+// readCriuImage decodes the image name from the CRIU images of the checkpoint
+func readCriuImage(checkpointDirectory, name string) (*crit.CriuImage, error) {
+	path := filepath.Join(imagesDirectory(checkpointDirectory), name)
+	c := crit.New(path, "", "", false, true)
+	img, err := c.Decode()
+	if err != nil {
+		return nil, criuImageError(checkpointDirectory, path, err)
+	}
+
+	return img, nil
+}
+
+// openCriuImage opens the image at path for reading its entries directly,
+// which is needed for images with extra data crit does not decode
+// correctly. The returned file is positioned at the first entry.
+func openCriuImage(path, magicName string) (*os.File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	// The image starts with the common magic followed by the image magic
+	buf := make([]byte, 8)
+	if _, err := io.ReadFull(f, buf); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	if uint64(binary.LittleEndian.Uint32(buf[4:])) != magic.LoadMagic().ByName[magicName] {
+		f.Close()
+		return nil, fmt.Errorf("%s: not a %s image", filepath.Base(path), magicName)
+	}
+
+	return f, nil
+}
+
+// readCriuEntry decodes the next entry of an image opened with openCriuImage.
+// It returns io.EOF after the last entry.
+func readCriuEntry(f *os.File, m proto.Message) error {
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(f, buf); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return fmt.Errorf("%s: %w", filepath.Base(f.Name()), err)
+		}
+		return err
+	}
+	payload := make([]byte, binary.LittleEndian.Uint32(buf))
+	if _, err := io.ReadFull(f, payload); err != nil {
+		return fmt.Errorf("%s: %w", filepath.Base(f.Name()), err)
+	}
+	if err := proto.Unmarshal(payload, m); err != nil {
+		return fmt.Errorf("%s: %w", filepath.Base(f.Name()), err)
+	}
+
+	return nil
+}
+
+// skipCriuData skips the extra data of size bytes following an entry,
+// which CRIU pads to a multiple of align
+func skipCriuData(f *os.File, size, align int64) error {
+	_, err := f.Seek((size+align-1)/align*align, io.SeekCurrent)
+	return err
+}
+
+func readInventory(checkpointDirectory string) (*images.InventoryEntry, error) {
+	img, err := readCriuImage(checkpointDirectory, inventoryImg)
+	if err != nil {
+		return nil, err
+	}
+	if len(img.Entries) == 0 {
+		return nil, fmt.Errorf("%s does not contain any entries", inventoryImg)
+	}
+	inventory, ok := img.Entries[0].Message.(*images.InventoryEntry)
+	if !ok {
+		return nil, fmt.Errorf("failed to type assert %s", inventoryImg)
+	}
+
+	return inventory, nil
+}
+
+// checkImageVersion verifies that the CRIU image format of the checkpoint
+// can be decoded before any of the images is used
+func checkImageVersion(checkpointDirectory string) error {
+	if !criuImageExists(checkpointDirectory, inventoryImg) {
+		// Let the individual features report what is missing
+		return nil
+	}
+	inventory, err := readInventory(checkpointDirectory)
+	if err != nil {
+		return fmt.Errorf("unable to determine CRIU image format version: %w", err)
+	}
+	if v := inventory.GetImgVersion(); v < minImageVersion || v > maxImageVersion {
+		return fmt.Errorf(
+			"CRIU image format version %d is not supported by this build of checkpointctl (supported: %d-%d)",
+			v, minImageVersion, maxImageVersion,
+		)
+	}
+
+	return nil
+}
+
+// readRuntimeHostname returns the hostname of the UTS namespace of the
+// container's init process as captured by CRIU. It is false if the
+// checkpoint does not contain a UTS namespace.
+func readRuntimeHostname(checkpointDirectory string) (string, bool, error) {
+	processes, err := readProcesses(checkpointDirectory)
+	if err != nil {
+		return "", false, err
+	}
+	if len(processes) == 0 {
+		return "", false, fmt.Errorf("%s does not contain any entries", pstreeImg)
+	}
+
+	var name string
+	if ids := processes[0].Core.GetIds(); ids != nil && ids.UtsNsId != nil {
+		name = fmt.Sprintf("utsns-%d.img", ids.GetUtsNsId())
+	} else {
+		// Images written by older versions of CRIU do not contain
+		// the namespace IDs in the core image
+		utsns, err := filepath.Glob(filepath.Join(imagesDirectory(checkpointDirectory), "utsns-*.img"))
+		if err != nil {
+			return "", false, err
+		}
+		if len(utsns) != 1 {
+			return "", false, nil
+		}
+		name = filepath.Base(utsns[0])
+	}
+	if !criuImageExists(checkpointDirectory, name) {
+		return "", false, nil
+	}
+
+	img, err := readCriuImage(checkpointDirectory, name)
+	if err != nil {
+		return "", false, err
+	}
+	if len(img.Entries) == 0 {
+		return "", false, fmt.Errorf("%s does not contain any entries", name)
+	}
+	uts, ok := img.Entries[0].Message.(*images.UtsnsEntry)
+	if !ok {
+		return "", false, fmt.Errorf("failed to type assert %s", name)
+	}
+
+	return uts.GetNodename(), true, nil
+}
+
+// readMountpoints returns the mounts of the mount namespace of the
+// container's init process as captured by CRIU. It is nil if the
+// checkpoint does not contain the mountpoints image.
+func readMountpoints(checkpointDirectory string) ([]*images.MntEntry, error) {
+	processes, err := readProcesses(checkpointDirectory)
+	if err != nil {
+		return nil, err
+	}
+	if len(processes) == 0 {
+		return nil, fmt.Errorf("%s does not contain any entries", pstreeImg)
+	}
+
+	var name string
+	if ids := processes[0].Core.GetIds(); ids != nil && ids.MntNsId != nil {
+		name = fmt.Sprintf("mountpoints-%d.img", ids.GetMntNsId())
+	} else {
+		mountpoints, err := filepath.Glob(filepath.Join(imagesDirectory(checkpointDirectory), "mountpoints-*.img"))
+		if err != nil {
+			return nil, err
+		}
+		if len(mountpoints) != 1 {
+			return nil, nil
+		}
+		name = filepath.Base(mountpoints[0])
+	}
+	if !criuImageExists(checkpointDirectory, name) {
+		return nil, nil
+	}
+
+	img, err := readCriuImage(checkpointDirectory, name)
+	if err != nil {
+		return nil, err
+	}
+	var mounts []*images.MntEntry
+	for _, entry := range img.Entries {
+		m, ok := entry.Message.(*images.MntEntry)
+		if !ok {
+			return nil, fmt.Errorf("failed to type assert %s", name)
+		}
+		mounts = append(mounts, m)
+	}
+
+	return mounts, nil
+}
+
+// requiredFeature is a CRIU feature which was used during checkpointing
+// and which has to be enabled again with the given option during restore
+type requiredFeature struct {
+	Feature string
+	Option  string
+}
+
+func getRequiredFeatures(checkpointDirectory string) ([]requiredFeature, error) {
+	var features []requiredFeature
+
+	inventory, err := readInventory(checkpointDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	tcpStreams, err := filepath.Glob(filepath.Join(imagesDirectory(checkpointDirectory), "tcp-stream-*.img"))
+	if err != nil {
+		return nil, err
+	}
+	if len(tcpStreams) > 0 {
+		features = append(features, requiredFeature{"established TCP connections", "--tcp-established"})
+	}
+	if inventory.GetTcpClose() {
+		features = append(features, requiredFeature{"closed TCP connections", "--tcp-close"})
+	}
+	if inventory.GetNetworkLockMethod() == networkLockNftables {
+		features = append(features, requiredFeature{"nftables network locking", "--network-lock nftables"})
+	}
+
+	if criuImageExists(checkpointDirectory, fileLocksImg) {
+		img, err := readCriuImage(checkpointDirectory, fileLocksImg)
+		if err != nil {
+			return nil, err
+		}
+		if len(img.Entries) > 0 {
+			features = append(features, requiredFeature{"file locks", "--file-locks"})
+		}
+	}
+
+	if criuImageExists(checkpointDirectory, unixSkImg) {
+		img, err := readCriuImage(checkpointDirectory, unixSkImg)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range img.Entries {
+			sk, ok := entry.Message.(*images.UnixSkEntry)
+			if ok && sk.GetUflags()&unixSkExtern != 0 {
+				features = append(features, requiredFeature{"external unix sockets", "--ext-unix-sk"})
+				break
+			}
+		}
+	}
+
+	if criuImageExists(checkpointDirectory, ttyInfoImg) {
+		features = append(features, requiredFeature{"terminal sessions", "--shell-job"})
+	}
+
+	return features, nil
+}
+
+func showRequiredFeatures(checkpointDirectory string) error {
+	features, err := getRequiredFeatures(checkpointDirectory)
+	if err != nil {
+		return fmt.Errorf("unable to display required CRIU features: %w", err)
+	}
+
+	printCaption("Required CRIU restore options")
+	if len(features) == 0 {
+		fmt.Println("No additional CRIU restore options required")
+		return nil
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	setTableHeader(table, []string{
+		"Feature",
+		"Restore Option",
+	})
+	var options []string
+	for _, f := range features {
+		table.Append([]string{f.Feature, f.Option})
+		options = append(options, f.Option)
+	}
+	table.Render()
+	fmt.Printf("criu restore %s\n", strings.Join(options, " "))
+
+	return nil
+}
@@ -0,0 +1,444 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to compare two container checkpoints
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	metadata "github.com/checkpoint-restore/checkpointctl/lib"
+	"github.com/olekukonko/tablewriter"
+	spec "github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// checkpointSummary contains the data of a checkpoint which is compared
+type checkpointSummary struct {
+	Info           *containerInfo
+	Config         *metadata.ContainerConfig
+	Spec           *spec.Spec
+	Size           int64
+	RootFsDiffSize int64
+	// Only loaded with --ps-tree
+	ProcessTree []*processNode
+}
+
+// fieldChange is a field which differs between two checkpoints. Delta
+// is only set for sizes.
+type fieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+	Delta string `json:"delta,omitempty"`
+}
+
+// listChange contains the names of list entries which only exist
+// in one of two checkpoints or which differ between both
+type listChange struct {
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+	Changed []string `json:"changed,omitempty"`
+}
+
+func (l *listChange) empty() bool {
+	return len(l.Added) == 0 && len(l.Removed) == 0 && len(l.Changed) == 0
+}
+
+type checkpointDiff struct {
+	Fields              []fieldChange
+	Mounts              listChange
+	Env                 listChange
+	Processes           listChange
+	SizeDelta           int64
+	RootFsDiffSizeDelta int64
+}
+
+func (d *checkpointDiff) empty() bool {
+	return len(d.Fields) == 0 && d.SizeDelta == 0 && d.RootFsDiffSizeDelta == 0 &&
+		d.Mounts.empty() && d.Env.empty() && d.Processes.empty()
+}
+
+// diffOutput is the machine-readable representation of a checkpointDiff
+type diffOutput struct {
+	Old                 string        `json:"old"`
+	New                 string        `json:"new"`
+	Identical           bool          `json:"identical"`
+	Stat                string        `json:"stat,omitempty"`
+	Fields              []fieldChange `json:"fields"`
+	Mounts              listChange    `json:"mounts"`
+	Env                 listChange    `json:"env"`
+	Processes           *listChange   `json:"processes,omitempty"`
+	SizeDelta           int64         `json:"sizeDelta"`
+	RootFsDiffSizeDelta int64         `json:"rootFsDiffSizeDelta"`
+}
+
+func newDiffOutput(inputA, inputB string, d *checkpointDiff) *diffOutput {
+	out := &diffOutput{
+		Old:                 inputA,
+		New:                 inputB,
+		Identical:           d.empty(),
+		Fields:              d.Fields,
+		Mounts:              d.Mounts,
+		Env:                 d.Env,
+		SizeDelta:           d.SizeDelta,
+		RootFsDiffSizeDelta: d.RootFsDiffSizeDelta,
+	}
+	if diffPsTree {
+		out.Processes = &d.Processes
+	}
+	if out.Fields == nil {
+		// Always emit an array, even if no field differs
+		out.Fields = []fieldChange{}
+	}
+	if diffStat && !out.Identical {
+		out.Stat = d.stat()
+	}
+
+	return out
+}
+
+func loadCheckpointSummary(checkpointDirectory string) (*checkpointSummary, error) {
+	containerConfig, _, err := metadata.ReadContainerCheckpointConfigDump(checkpointDirectory)
+	if err != nil {
+		return nil, err
+	}
+	specDump, _, err := metadata.ReadContainerCheckpointSpecDump(checkpointDirectory)
+	if err != nil {
+		return nil, err
+	}
+	ci, err := getContainerInfo(checkpointDirectory, containerConfig, specDump)
+	if err != nil {
+		return nil, err
+	}
+	size, err := getCheckpointSize(checkpointDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &checkpointSummary{
+		Info:   ci,
+		Config: containerConfig,
+		Spec:   specDump,
+		Size:   size,
+	}
+	if fi, err := os.Lstat(filepath.Join(checkpointDirectory, metadata.RootFsDiffTar)); err == nil {
+		summary.RootFsDiffSize = fi.Size()
+	}
+	if diffPsTree {
+		if err := checkImageVersion(checkpointDirectory); err != nil {
+			return nil, err
+		}
+		if summary.ProcessTree, err = buildProcessTree(checkpointDirectory); err != nil {
+			return nil, fmt.Errorf("unable to compare process trees: %w", err)
+		}
+	}
+
+	return summary, nil
+}
+
+func diffCheckpoints(a, b *checkpointSummary) *checkpointDiff {
+	d := &checkpointDiff{
+		SizeDelta:           b.Size - a.Size,
+		RootFsDiffSizeDelta: b.RootFsDiffSize - a.RootFsDiffSize,
+	}
+
+	fields := []fieldChange{
+		{"Container", a.Info.Name, b.Info.Name, ""},
+		{"Image", a.Config.RootfsImageName, b.Config.RootfsImageName, ""},
+		{"ID", a.Config.ID, b.Config.ID, ""},
+		{"Runtime", a.Config.OCIRuntime, b.Config.OCIRuntime, ""},
+		{"Created", a.Info.Created, b.Info.Created, ""},
+		{"Engine", a.Info.Engine, b.Info.Engine, ""},
+		{"IP", a.Info.IP, b.Info.IP, ""},
+		{"MAC", a.Info.MAC, b.Info.MAC, ""},
+		{"Pod", a.Info.Pod, b.Info.Pod, ""},
+		{"Namespace", a.Info.Namespace, b.Info.Namespace, ""},
+		{"Storage Driver", getStorageDriver(a.Config), getStorageDriver(b.Config), ""},
+	}
+	for _, f := range fields {
+		if f.Old != f.New {
+			d.Fields = append(d.Fields, f)
+		}
+	}
+	// The sizes are compared in bytes, as sizes which differ by a few bytes
+	// are formatted the same
+	if d.SizeDelta != 0 {
+		d.Fields = append(d.Fields, fieldChange{
+			"CHKPT Size",
+			formatSize(a.Size),
+			formatSize(b.Size),
+			sizeChange(a.Size, b.Size),
+		})
+	}
+	if d.RootFsDiffSizeDelta != 0 {
+		d.Fields = append(d.Fields, fieldChange{
+			"Root FS Diff Size",
+			formatSize(a.RootFsDiffSize),
+			formatSize(b.RootFsDiffSize),
+			sizeChange(a.RootFsDiffSize, b.RootFsDiffSize),
+		})
+	}
+
+	d.Mounts = diffKeys(mountMap(a.Spec), mountMap(b.Spec))
+	d.Env = diffKeys(envMap(a.Spec), envMap(b.Spec))
+	diffProcessNodes(a.ProcessTree, b.ProcessTree, "", &d.Processes)
+
+	return d
+}
+
+// processPath returns the name of a process from the commands of its
+// ancestors, as the PIDs usually differ between two checkpoints
+func processPath(parent string, n *processNode) string {
+	if parent == "" {
+		return n.process.Comm
+	}
+
+	return parent + "/" + n.process.Comm
+}
+
+// addProcessSubtree records n and all its descendants as added or removed
+func addProcessSubtree(names *[]string, parent string, n *processNode) {
+	path := processPath(parent, n)
+	*names = append(*names, fmt.Sprintf("%s (PID %d)", path, n.process.PID))
+	for _, c := range n.children {
+		addProcessSubtree(names, path, c)
+	}
+}
+
+// diffProcessNodes compares the child processes a and b of two matching
+// processes. Children are matched by their command in pstree order. The
+// remaining children are matched by their position and reported as changed
+// command, all others as added or removed including their descendants.
+func diffProcessNodes(a, b []*processNode, parent string, l *listChange) {
+	matches := make(map[*processNode]*processNode)
+	used := make(map[*processNode]bool)
+	for _, na := range a {
+		for _, nb := range b {
+			if !used[nb] && na.process.Comm == nb.process.Comm {
+				matches[na] = nb
+				used[nb] = true
+				break
+			}
+		}
+	}
+	var removed, added []*processNode
+	for _, na := range a {
+		if _, ok := matches[na]; !ok {
+			removed = append(removed, na)
+		}
+	}
+	for _, nb := range b {
+		if !used[nb] {
+			added = append(added, nb)
+		}
+	}
+	for i := 0; i < len(removed) && i < len(added); i++ {
+		matches[removed[i]] = added[i]
+		l.Changed = append(l.Changed, fmt.Sprintf(
+			"%s (PID %d, was %s)", processPath(parent, added[i]), added[i].process.PID, removed[i].process.Comm,
+		))
+	}
+
+	for _, na := range a {
+		if nb, ok := matches[na]; ok {
+			diffProcessNodes(na.children, nb.children, processPath(parent, nb), l)
+		}
+	}
+	for i := len(added); i < len(removed); i++ {
+		addProcessSubtree(&l.Removed, parent, removed[i])
+	}
+	for i := len(removed); i < len(added); i++ {
+		addProcessSubtree(&l.Added, parent, added[i])
+	}
+}
+
+// diffKeys compares the entries of two maps by their keys
+func diffKeys(a, b map[string]string) listChange {
+	var l listChange
+	for k, va := range a {
+		vb, ok := b[k]
+		switch {
+		case !ok:
+			l.Removed = append(l.Removed, k)
+		case va != vb:
+			l.Changed = append(l.Changed, k)
+		}
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			l.Added = append(l.Added, k)
+		}
+	}
+	sort.Strings(l.Added)
+	sort.Strings(l.Removed)
+	sort.Strings(l.Changed)
+
+	return l
+}
+
+// mountMap returns the type, source and options of the mounts by destination
+func mountMap(specDump *spec.Spec) map[string]string {
+	mounts := make(map[string]string)
+	for _, m := range specDump.Mounts {
+		mounts[m.Destination] = fmt.Sprintf("%s %s %s", m.Type, m.Source, strings.Join(m.Options, ","))
+	}
+
+	return mounts
+}
+
+// envMap returns the environment variables of the container process
+func envMap(specDump *spec.Spec) map[string]string {
+	env := make(map[string]string)
+	if specDump.Process == nil {
+		return env
+	}
+	for _, e := range specDump.Process.Env {
+		key, value, _ := strings.Cut(e, "=")
+		env[key] = value
+	}
+
+	return env
+}
+
+// plural returns the noun for the number of n items
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// formatSizeDelta formats the difference of two sizes with its sign
+func formatSizeDelta(delta int64) string {
+	if delta < 0 {
+		return "-" + formatSize(-delta)
+	}
+
+	return "+" + formatSize(delta)
+}
+
+// sizeChange formats the difference of two sizes in bytes and in percent
+// of the old size, like "+2048 bytes (+100.0%)"
+func sizeChange(oldSize, newSize int64) string {
+	delta := newSize - oldSize
+	change := formatCount(delta) + " bytes"
+	if delta >= 0 {
+		change = "+" + change
+	}
+	if oldSize == 0 {
+		return change
+	}
+	percent := formatPercent(float64(delta) / float64(oldSize) * 100)
+	if delta >= 0 {
+		percent = "+" + percent
+	}
+
+	return fmt.Sprintf("%s (%s)", change, percent)
+}
+
+func listStat(noun string, l listChange) []string {
+	var parts []string
+	if n := len(l.Added); n > 0 {
+		parts = append(parts, plural(n, noun)+" added")
+	}
+	if n := len(l.Removed); n > 0 {
+		parts = append(parts, plural(n, noun)+" removed")
+	}
+	if n := len(l.Changed); n > 0 {
+		parts = append(parts, plural(n, noun)+" changed")
+	}
+
+	return parts
+}
+
+// stat summarizes the differences like 'git diff --stat'
+func (d *checkpointDiff) stat() string {
+	var parts []string
+	// Sizes are reported as delta and not as changed field
+	changedFields := 0
+	for _, f := range d.Fields {
+		if f.Field != "CHKPT Size" && f.Field != "Root FS Diff Size" {
+			changedFields++
+		}
+	}
+	if changedFields > 0 {
+		parts = append(parts, plural(changedFields, "field")+" changed")
+	}
+	parts = append(parts, listStat("mount", d.Mounts)...)
+	if d.SizeDelta != 0 {
+		parts = append(parts, "size "+formatSizeDelta(d.SizeDelta))
+	}
+	if d.RootFsDiffSizeDelta != 0 {
+		parts = append(parts, "root fs diff size "+formatSizeDelta(d.RootFsDiffSizeDelta))
+	}
+	parts = append(parts, listStat("env var", d.Env)...)
+	parts = append(parts, listStat("process", d.Processes)...)
+
+	return strings.Join(parts, ", ")
+}
+
+func showListChange(title, column string, l listChange) {
+	if l.empty() {
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{
+		column,
+		"Change",
+	})
+	for _, c := range []struct {
+		names  []string
+		change string
+	}{
+		{l.Added, "added"},
+		{l.Removed, "removed"},
+		{l.Changed, "changed"},
+	} {
+		for _, n := range c.names {
+			table.Append([]string{n, c.change})
+		}
+	}
+	fmt.Printf("\n%s\n", title)
+	table.Render()
+}
+
+func showCheckpointDiff(inputA, inputB string, d *checkpointDiff) {
+	fmt.Printf("\nComparing container checkpoints %s and %s\n", inputA, inputB)
+
+	if d.empty() {
+		fmt.Println("\nNo differences found")
+		return
+	}
+
+	if diffStat {
+		fmt.Printf("\n%s\n", d.stat())
+	}
+
+	if len(d.Fields) > 0 {
+		table := tablewriter.NewWriter(os.Stdout)
+		// Keep the names of the archives as they are
+		table.SetAutoFormatHeaders(false)
+		table.SetHeader([]string{
+			"FIELD",
+			filepath.Base(inputA),
+			filepath.Base(inputB),
+			"DELTA",
+		})
+		for _, f := range d.Fields {
+			table.Append([]string{f.Field, f.Old, f.New, f.Delta})
+		}
+		fmt.Println()
+		table.Render()
+	}
+
+	showListChange("Mounts", "Destination", d.Mounts)
+	showListChange("Environment variables", "Variable", d.Env)
+	showListChange("Processes", "Process", d.Processes)
+}
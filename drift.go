@@ -0,0 +1,337 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to compare container checkpoints with Kubernetes manifests
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	crname "github.com/google/go-containerregistry/pkg/name"
+	"github.com/olekukonko/tablewriter"
+	spec "github.com/opencontainers/runtime-spec/specs-go"
+	"sigs.k8s.io/yaml"
+)
+
+// The parts of a Kubernetes Pod manifest which are compared with the
+// checkpoint. Only the fields required here are decoded to not depend
+// on the Kubernetes API packages.
+type manifestEnvVar struct {
+	Name      string      `json:"name"`
+	Value     string      `json:"value"`
+	ValueFrom interface{} `json:"valueFrom"`
+}
+
+type manifestVolumeMount struct {
+	MountPath string `json:"mountPath"`
+	ReadOnly  bool   `json:"readOnly"`
+}
+
+type manifestResources struct {
+	Limits map[string]string `json:"limits"`
+}
+
+type manifestContainer struct {
+	Name         string                `json:"name"`
+	Image        string                `json:"image"`
+	Command      []string              `json:"command"`
+	Args         []string              `json:"args"`
+	Env          []manifestEnvVar      `json:"env"`
+	VolumeMounts []manifestVolumeMount `json:"volumeMounts"`
+	Resources    manifestResources     `json:"resources"`
+}
+
+type manifestPodSpec struct {
+	Containers []manifestContainer `json:"containers"`
+	Template   *struct {
+		Spec manifestPodSpec `json:"spec"`
+	} `json:"template"`
+}
+
+type podManifest struct {
+	Kind string          `json:"kind"`
+	Spec manifestPodSpec `json:"spec"`
+}
+
+// divergence is a setting of the container which differs between
+// the manifest and the checkpoint
+type divergence struct {
+	Field      string `json:"field"`
+	Declared   string `json:"declared"`
+	Checkpoint string `json:"checkpoint"`
+}
+
+// driftOutput is the machine-readable result of the drift subcommand
+type driftOutput struct {
+	Checkpoint  string       `json:"checkpoint"`
+	Manifest    string       `json:"manifest"`
+	Container   string       `json:"container"`
+	Diverged    bool         `json:"diverged"`
+	Divergences []divergence `json:"divergences"`
+}
+
+// loadManifestContainer reads the container from a Pod manifest or from
+// the pod template of a workload like a Deployment. A manifest can also
+// contain a single container only.
+func loadManifestContainer(path, containerName, checkpointContainer string) (*manifestContainer, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m podManifest
+	if err := yaml.Unmarshal(content, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", path, err)
+	}
+
+	containers := m.Spec.Containers
+	if m.Spec.Template != nil {
+		containers = m.Spec.Template.Spec.Containers
+	}
+	if m.Kind == "" && len(containers) == 0 {
+		var c manifestContainer
+		if err := yaml.Unmarshal(content, &c); err != nil {
+			return nil, fmt.Errorf("failed to parse manifest %s: %w", path, err)
+		}
+		containers = []manifestContainer{c}
+	}
+	if len(containers) == 0 {
+		return nil, fmt.Errorf("manifest %s does not contain any containers", path)
+	}
+
+	if containerName == "" {
+		if len(containers) == 1 {
+			return &containers[0], nil
+		}
+		// Kubernetes checkpoints know the name of their container
+		containerName = checkpointContainer
+	}
+	for i := range containers {
+		if containers[i].Name == containerName {
+			return &containers[i], nil
+		}
+	}
+	if containerName == "" {
+		return nil, fmt.Errorf("manifest %s contains multiple containers, select one with --container", path)
+	}
+
+	return nil, fmt.Errorf("container %q not found in manifest %s", containerName, path)
+}
+
+// normalizeImageName returns the fully qualified name of an image
+// to compare short names like "nginx" with "docker.io/library/nginx:latest"
+func normalizeImageName(image string) string {
+	ref, err := crname.ParseReference(image)
+	if err != nil {
+		return image
+	}
+
+	return ref.Name()
+}
+
+// parseQuantity converts a Kubernetes resource quantity like 128Mi, 1G
+// or 500m into a number
+func parseQuantity(q string) (float64, error) {
+	suffixes := []struct {
+		suffix     string
+		multiplier float64
+	}{
+		{"Ki", 1 << 10},
+		{"Mi", 1 << 20},
+		{"Gi", 1 << 30},
+		{"Ti", 1 << 40},
+		{"k", 1e3},
+		{"M", 1e6},
+		{"G", 1e9},
+		{"T", 1e12},
+		{"m", 1e-3},
+	}
+	multiplier := 1.0
+	number := q
+	for _, s := range suffixes {
+		if strings.HasSuffix(q, s.suffix) {
+			number = strings.TrimSuffix(q, s.suffix)
+			multiplier = s.multiplier
+			break
+		}
+	}
+	v, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid quantity %q", q)
+	}
+
+	return v * multiplier, nil
+}
+
+func driftCommand(c *manifestContainer, specDump *spec.Spec) *divergence {
+	var args []string
+	if specDump.Process != nil {
+		args = specDump.Process.Args
+	}
+	var declared []string
+	switch {
+	case len(c.Command) > 0:
+		declared = append(append(declared, c.Command...), c.Args...)
+		if strings.Join(declared, " ") == strings.Join(args, " ") {
+			return nil
+		}
+	case len(c.Args) > 0:
+		// Without a command the entrypoint of the image is used,
+		// followed by the declared arguments
+		declared = c.Args
+		if len(args) >= len(declared) && strings.Join(args[len(args)-len(declared):], " ") == strings.Join(declared, " ") {
+			return nil
+		}
+	default:
+		// Command and arguments of the image are used
+		return nil
+	}
+
+	return &divergence{"Command", strings.Join(declared, " "), strings.Join(args, " ")}
+}
+
+func driftEnv(c *manifestContainer, specDump *spec.Spec) []divergence {
+	var divergences []divergence
+	env := envMap(specDump)
+	for _, e := range c.Env {
+		value, ok := env[e.Name]
+		switch {
+		case !ok && e.ValueFrom != nil:
+			divergences = append(divergences, divergence{"Env " + e.Name, "(valueFrom)", "-"})
+		case !ok:
+			divergences = append(divergences, divergence{"Env " + e.Name, e.Value, "-"})
+		case e.ValueFrom != nil:
+			// Values from secrets and config maps are not in the manifest
+		case value != e.Value:
+			divergences = append(divergences, divergence{"Env " + e.Name, e.Value, value})
+		}
+	}
+
+	return divergences
+}
+
+func driftMounts(c *manifestContainer, specDump *spec.Spec) []divergence {
+	var divergences []divergence
+	for _, vm := range c.VolumeMounts {
+		var mount *spec.Mount
+		for i := range specDump.Mounts {
+			if specDump.Mounts[i].Destination == vm.MountPath {
+				mount = &specDump.Mounts[i]
+			}
+		}
+		if mount == nil {
+			divergences = append(divergences, divergence{"Mount " + vm.MountPath, "mounted", "-"})
+			continue
+		}
+		readOnly := false
+		for _, o := range mount.Options {
+			if o == "ro" {
+				readOnly = true
+			}
+		}
+		if readOnly != vm.ReadOnly {
+			divergences = append(divergences, divergence{
+				"Mount " + vm.MountPath,
+				mountAccess(vm.ReadOnly),
+				mountAccess(readOnly),
+			})
+		}
+	}
+
+	return divergences
+}
+
+func mountAccess(readOnly bool) string {
+	if readOnly {
+		return "read-only"
+	}
+
+	return "read-write"
+}
+
+func driftResources(c *manifestContainer, specDump *spec.Spec) ([]divergence, error) {
+	var resources *spec.LinuxResources
+	if specDump.Linux != nil {
+		resources = specDump.Linux.Resources
+	}
+
+	var divergences []divergence
+	if limit, ok := c.Resources.Limits["memory"]; ok {
+		declared, err := parseQuantity(limit)
+		if err != nil {
+			return nil, fmt.Errorf("memory limit: %w", err)
+		}
+		switch {
+		case resources == nil || resources.Memory == nil || resources.Memory.Limit == nil:
+			divergences = append(divergences, divergence{"Memory Limit", formatSize(int64(declared)), "-"})
+		case *resources.Memory.Limit != int64(declared):
+			divergences = append(divergences, divergence{"Memory Limit", formatSize(int64(declared)), formatSize(*resources.Memory.Limit)})
+		}
+	}
+	if limit, ok := c.Resources.Limits["cpu"]; ok {
+		declared, err := parseQuantity(limit)
+		if err != nil {
+			return nil, fmt.Errorf("CPU limit: %w", err)
+		}
+		switch {
+		case resources == nil || resources.CPU == nil || resources.CPU.Quota == nil || resources.CPU.Period == nil || *resources.CPU.Period == 0:
+			divergences = append(divergences, divergence{"CPU Limit", formatCPUs(declared), "-"})
+		default:
+			cpus := float64(*resources.CPU.Quota) / float64(*resources.CPU.Period)
+			// The quota is rounded to microseconds by the runtime
+			if math.Abs(cpus-declared) >= 0.001 {
+				divergences = append(divergences, divergence{"CPU Limit", formatCPUs(declared), formatCPUs(cpus)})
+			}
+		}
+	}
+
+	return divergences, nil
+}
+
+func formatCPUs(cpus float64) string {
+	return strconv.FormatFloat(cpus, 'f', -1, 64) + " CPUs"
+}
+
+// driftFromManifest compares the declared configuration of a container
+// with the configuration captured in its checkpoint
+func driftFromManifest(c *manifestContainer, summary *checkpointSummary) ([]divergence, error) {
+	divergences := []divergence{}
+	if c.Image != "" && normalizeImageName(c.Image) != normalizeImageName(summary.Config.RootfsImageName) {
+		divergences = append(divergences, divergence{"Image", c.Image, summary.Config.RootfsImageName})
+	}
+	if d := driftCommand(c, summary.Spec); d != nil {
+		divergences = append(divergences, *d)
+	}
+	divergences = append(divergences, driftEnv(c, summary.Spec)...)
+	divergences = append(divergences, driftMounts(c, summary.Spec)...)
+	resources, err := driftResources(c, summary.Spec)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(divergences, resources...), nil
+}
+
+func showDrift(out *driftOutput) {
+	fmt.Printf("\nComparing container checkpoint %s with container %s of %s\n", out.Checkpoint, out.Container, out.Manifest)
+	if !out.Diverged {
+		fmt.Println("\nNo divergences found")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{
+		"Field",
+		"Declared",
+		"Checkpoint",
+	})
+	for _, d := range out.Divergences {
+		table.Append([]string{d.Field, d.Declared, d.Checkpoint})
+	}
+	fmt.Println()
+	table.Render()
+}
@@ -0,0 +1,258 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to display the environment and command line of container checkpoints
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	metadata "github.com/checkpoint-restore/checkpointctl/lib"
+	"github.com/olekukonko/tablewriter"
+	spec "github.com/opencontainers/runtime-spec/specs-go"
+)
+
+const (
+	defaultMaxValueLen = 80
+	truncationMarker   = "..."
+)
+
+// Sources of the variables in the merged environment of --env-merged
+const (
+	// The variable has the value the container was created with
+	envSourceImage = "image"
+	// The variable was set by the container process at runtime
+	envSourceRuntime = "runtime"
+	// The variable was created with another value
+	envSourceOverridden = "overridden at runtime"
+	// The variable was created but is not set in the container process
+	envSourceUnset = "unset at runtime"
+)
+
+func validateMaxValueLen() error {
+	if maxValueLen < 1 {
+		return fmt.Errorf("--max-value-len must be at least 1, use --no-truncate to display complete values")
+	}
+
+	return nil
+}
+
+// displayValue makes a value from the checkpoint safe to print to a
+// terminal. Non-printable characters and invalid UTF-8 are escaped and
+// values longer than --max-value-len are truncated unless --no-truncate
+// is given. Escape sequences are never cut in half.
+func displayValue(value string) string {
+	var b strings.Builder
+	length := 0
+	for i := 0; i < len(value); {
+		r, size := utf8.DecodeRuneInString(value[i:])
+		var token string
+		switch {
+		case r == utf8.RuneError && size == 1:
+			token = fmt.Sprintf(`\x%02x`, value[i])
+		case unicode.IsPrint(r):
+			token = string(r)
+		default:
+			token = strings.Trim(strconv.QuoteRune(r), "'")
+		}
+		tokenLength := utf8.RuneCountInString(token)
+		if !noTruncate && length+tokenLength > maxValueLen {
+			b.WriteString(truncationMarker)
+			break
+		}
+		b.WriteString(token)
+		length += tokenLength
+		i += size
+	}
+
+	return b.String()
+}
+
+// secretEnvWords are parts of the names of environment variables which
+// usually carry credentials. PASSWORD, SECRET and TOKEN match anywhere in
+// the name, KEY only as a separate word to leave names like KEYBOARD alone.
+var (
+	secretEnvSubstrings = []string{"PASSWORD", "SECRET", "TOKEN"}
+	secretEnvWords      = []string{"KEY"}
+)
+
+// isSecretEnv returns true if the name of an environment variable suggests
+// that its value is a secret
+func isSecretEnv(name string) bool {
+	name = strings.ToUpper(name)
+	for _, s := range secretEnvSubstrings {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, w := range words {
+		for _, s := range secretEnvWords {
+			if w == s {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func showEnvironment(specDump *spec.Spec) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	setTableHeader(table, []string{
+		"Variable",
+		"Value",
+	})
+	if specDump.Process != nil {
+		for _, e := range specDump.Process.Env {
+			key, value, _ := strings.Cut(e, "=")
+			if maskEnv && isSecretEnv(key) {
+				value = redactedValue
+			}
+			table.Append([]string{displayValue(key), displayValue(value)})
+		}
+	}
+	printCaption("Environment variables")
+	table.Render()
+}
+
+func showCommandLine(specDump *spec.Spec) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	setTableHeader(table, []string{
+		"Index",
+		"Argument",
+	})
+	if specDump.Process != nil {
+		for i, arg := range specDump.Process.Args {
+			table.Append([]string{strconv.Itoa(i), displayValue(arg)})
+		}
+	}
+	printCaption("Command line")
+	table.Render()
+	// The working directory of the container process from the spec, the
+	// processes may have changed their working directory since
+	if specDump.Process != nil && specDump.Process.Cwd != "" {
+		fmt.Printf("Working directory: %s\n", displayValue(specDump.Process.Cwd))
+	}
+}
+
+// mergedEnvVar is a variable of the environment of the container process
+// with the source of its value
+type mergedEnvVar struct {
+	Name   string
+	Value  string
+	Source string
+}
+
+// readProcessEnv returns the environment of a process, which is read
+// from the memory of the process like /proc/<pid>/environ
+func readProcessEnv(checkpointDirectory string, pid uint32) ([]string, error) {
+	mm, err := readMm(checkpointDirectory, pid)
+	if err != nil {
+		return nil, err
+	}
+	if mm.GetMmEnvEnd() <= mm.GetMmEnvStart() {
+		return nil, fmt.Errorf("environment of process %d %w", pid, metadata.ErrSectionUnavailable)
+	}
+	data, err := readProcessMemory(checkpointDirectory, pid, mm.GetMmEnvStart(), mm.GetMmEnvEnd())
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.Split(strings.TrimRight(string(data), "\x00"), "\x00"), nil
+}
+
+// configuredEnv returns the environment the container was created with.
+// Podman stores the spec of the container, which contains the environment
+// of the image and the one given when creating the container, in
+// config.dump. For other engines the spec of the checkpoint is used.
+func configuredEnv(containerConfig *metadata.ContainerConfig, specDump *spec.Spec) []string {
+	if containerConfig.Spec != nil && containerConfig.Spec.Process != nil {
+		return containerConfig.Spec.Process.Env
+	}
+	if specDump.Process != nil {
+		return specDump.Process.Env
+	}
+
+	return nil
+}
+
+// mergeEnv compares the environment of the container process with the
+// configured one. Variables are sorted like in the process environment,
+// followed by the configured variables which the process unset.
+func mergeEnv(configured, process []string) []mergedEnvVar {
+	values := make(map[string]string)
+	for _, e := range configured {
+		key, value, _ := strings.Cut(e, "=")
+		values[key] = value
+	}
+
+	var merged []mergedEnvVar
+	seen := make(map[string]bool)
+	for _, e := range process {
+		key, value, _ := strings.Cut(e, "=")
+		seen[key] = true
+		source := envSourceRuntime
+		if v, ok := values[key]; ok && v == value {
+			source = envSourceImage
+		} else if ok {
+			source = envSourceOverridden
+		}
+		merged = append(merged, mergedEnvVar{key, value, source})
+	}
+	for _, e := range configured {
+		key, value, _ := strings.Cut(e, "=")
+		if !seen[key] {
+			seen[key] = true
+			merged = append(merged, mergedEnvVar{key, value, envSourceUnset})
+		}
+	}
+
+	return merged
+}
+
+// showMergedEnvironment prints the environment of the container process
+// from its memory next to the source of each variable
+func showMergedEnvironment(checkpointDirectory string, containerConfig *metadata.ContainerConfig, specDump *spec.Spec) error {
+	processes, err := readProcesses(checkpointDirectory)
+	if err != nil {
+		return fmt.Errorf("unable to display merged environment: %w", err)
+	}
+	if len(processes) == 0 {
+		return fmt.Errorf("unable to display merged environment: %s does not contain any processes", pstreeImg)
+	}
+	// The first process in pstree order is the container process
+	p := processes[0]
+	env, err := readProcessEnv(checkpointDirectory, p.PID)
+	if err != nil {
+		return fmt.Errorf("unable to display merged environment: %w", err)
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	setTableHeader(table, []string{
+		"Variable",
+		"Value",
+		"Source",
+	})
+	for _, v := range mergeEnv(configuredEnv(containerConfig, specDump), env) {
+		value := v.Value
+		if maskEnv && isSecretEnv(v.Name) {
+			value = redactedValue
+		}
+		table.Append([]string{displayValue(v.Name), displayValue(value), v.Source})
+	}
+	printCaption("Environment variables of process %d (%s)", p.PID, p.Comm)
+	table.Render()
+
+	return nil
+}
@@ -0,0 +1,43 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to map errors to the exit codes of checkpointctl
+
+package main
+
+import (
+	"errors"
+
+	metadata "github.com/checkpoint-restore/checkpointctl/lib"
+)
+
+// Exit codes which allow scripts to tell broken checkpoints from
+// checkpoints which only lack the requested information
+const (
+	// exitFailure is used for unreadable or missing checkpoints and all
+	// other errors
+	exitFailure = 1
+	// exitUnknownContainerManager is used for checkpoints of container
+	// engines which are not supported, unless --best-effort is given
+	exitUnknownContainerManager = 2
+	// exitSectionUnavailable is used if a section requested on the
+	// command line needs data which is not included in the checkpoint
+	exitSectionUnavailable = 3
+)
+
+// isPolicyError reports whether the checkpoint could be read, but violates
+// one of the checks which --strict turns into failures
+func isPolicyError(err error) bool {
+	return errors.Is(err, errThresholdExceeded) || errors.Is(err, errSpecInvalid)
+}
+
+// exitCode returns the exit code for an error returned by a subcommand
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, metadata.ErrUnknownContainerManager):
+		return exitUnknownContainerManager
+	case errors.Is(err, metadata.ErrSectionUnavailable):
+		return exitSectionUnavailable
+	}
+
+	return exitFailure
+}
@@ -0,0 +1,215 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to format numbers for human readers
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	metadata "github.com/checkpoint-restore/checkpointctl/lib"
+)
+
+// numberFormat contains the separators of the locale used for output
+type numberFormat struct {
+	Thousands string
+	Decimal   string
+}
+
+// The locale "C" does not group digits
+var plainNumbers = numberFormat{"", "."}
+
+// Separators of the languages which are known to checkpointctl
+var languageNumberFormats = map[string]numberFormat{
+	"en": {",", "."},
+	"ja": {",", "."},
+	"ko": {",", "."},
+	"zh": {",", "."},
+	"da": {".", ","},
+	"de": {".", ","},
+	"es": {".", ","},
+	"id": {".", ","},
+	"it": {".", ","},
+	"nl": {".", ","},
+	"pt": {".", ","},
+	"tr": {".", ","},
+	"cs": {" ", ","},
+	"fi": {" ", ","},
+	"fr": {" ", ","},
+	"nb": {" ", ","},
+	"pl": {" ", ","},
+	"ru": {" ", ","},
+	"sv": {" ", ","},
+	"uk": {" ", ","},
+}
+
+// Separators of locales which differ from their language
+var localeNumberFormats = map[string]numberFormat{
+	"de_CH": {"'", "."},
+	"it_CH": {"'", "."},
+}
+
+// numbers is the number format used for output
+var numbers = plainNumbers
+
+// getLocale returns the locale from --locale or from the environment
+func getLocale() string {
+	if locale != "" {
+		return locale
+	}
+	for _, env := range []string{"LC_ALL", "LC_NUMERIC", "LANG"} {
+		if value := os.Getenv(env); value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// setupNumberFormat selects the number format of the locale. Locales
+// from the environment which are not known use the plain format.
+func setupNumberFormat() error {
+	// Like JSON and YAML, logfmt output is never localized
+	if rawNumbers || outputFormat == outputLogfmt {
+		numbers = plainNumbers
+		return nil
+	}
+
+	value := getLocale()
+	// Remove the codeset and modifier from e.g. de_DE.UTF-8@euro
+	name, _, _ := strings.Cut(value, ".")
+	name, _, _ = strings.Cut(name, "@")
+	if name == "" || name == "C" || name == "POSIX" {
+		numbers = plainNumbers
+		return nil
+	}
+	if f, ok := localeNumberFormats[name]; ok {
+		numbers = f
+		return nil
+	}
+	language, _, _ := strings.Cut(name, "_")
+	if f, ok := languageNumberFormats[language]; ok {
+		numbers = f
+		return nil
+	}
+	if locale != "" {
+		return fmt.Errorf("unsupported locale %q", locale)
+	}
+	numbers = plainNumbers
+
+	return nil
+}
+
+// formatCount formats n with the thousands separator of the locale
+func formatCount(n int64) string {
+	digits := fmt.Sprintf("%d", n)
+	sign := ""
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	if numbers.Thousands == "" || len(digits) <= 3 {
+		return sign + digits
+	}
+
+	var b strings.Builder
+	b.WriteString(sign)
+	head := len(digits) % 3
+	if head > 0 {
+		b.WriteString(digits[:head])
+	}
+	for i := head; i < len(digits); i += 3 {
+		if b.Len() > len(sign) {
+			b.WriteString(numbers.Thousands)
+		}
+		b.WriteString(digits[i : i+3])
+	}
+
+	return b.String()
+}
+
+// sizeUnitAuto selects the unit of each size depending on its value
+const sizeUnitAuto = "auto"
+
+// sizeUnit is a fixed unit of --size-unit with its decimal
+// counterpart used with --si
+type sizeUnit struct {
+	Binary  string
+	Decimal string
+	Exp     int
+}
+
+var sizeUnits = []sizeUnit{
+	{"B", "B", 0},
+	{"KiB", "kB", 1},
+	{"MiB", "MB", 2},
+	{"GiB", "GB", 3},
+}
+
+// validateSizeUnit returns an error if --size-unit is not supported
+func validateSizeUnit() error {
+	if sizeUnitName == sizeUnitAuto {
+		return nil
+	}
+	names := []string{sizeUnitAuto}
+	for _, u := range sizeUnits {
+		if u.Binary == sizeUnitName {
+			return nil
+		}
+		names = append(names, u.Binary)
+	}
+
+	return fmt.Errorf("unsupported size unit %q (supported: %s)", sizeUnitName, strings.Join(names, ", "))
+}
+
+// formatSIBytes is the decimal counterpart of metadata.ByteToString
+func formatSIBytes(b int64) string {
+	const unit = 1000
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := int64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %cB", float64(b)/float64(div), "kMGTPE"[exp])
+}
+
+// formatSize formats a size in bytes for human readers or as
+// the plain number of bytes with --raw. With --size-unit all sizes
+// are displayed in the same unit with two decimal places.
+func formatSize(size int64) string {
+	if rawNumbers {
+		return fmt.Sprintf("%d", size)
+	}
+
+	var s string
+	switch {
+	case sizeUnitName != sizeUnitAuto:
+		for _, u := range sizeUnits {
+			if u.Binary != sizeUnitName {
+				continue
+			}
+			base, name := 1024.0, u.Binary
+			if siUnits {
+				base, name = 1000.0, u.Decimal
+			}
+			s = fmt.Sprintf("%.2f %s", float64(size)/math.Pow(base, float64(u.Exp)), name)
+		}
+	case siUnits:
+		s = formatSIBytes(size)
+	default:
+		s = metadata.ByteToString(size)
+	}
+
+	return strings.Replace(s, ".", numbers.Decimal, 1)
+}
+
+// formatPercent formats a percentage with the decimal separator of the locale
+func formatPercent(percent float64) string {
+	return strings.Replace(fmt.Sprintf("%.1f%%", percent), ".", numbers.Decimal, 1)
+}
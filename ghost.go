@@ -0,0 +1,199 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to display the ghost files of container checkpoints
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/checkpoint-restore/go-criu/v6/crit/images"
+	"github.com/olekukonko/tablewriter"
+)
+
+const (
+	remapFpathImg = "remap-fpath.img"
+	regFilesImg   = "reg-files.img"
+	filesImg      = "files.img"
+)
+
+// ghostFile is a file which was deleted but still open during checkpointing.
+// CRIU stores the content of such files in the checkpoint.
+type ghostFile struct {
+	Path  string
+	Size  int64
+	Image int64
+	// Location of the content of files which are not stored in chunks
+	image  string
+	offset int64
+}
+
+// readRegularFileNames returns the paths of the regular files opened by the
+// checkpointed processes by file ID. Older versions of CRIU store them in
+// reg-files.img, newer versions in files.img.
+func readRegularFileNames(checkpointDirectory string) (map[uint32]string, error) {
+	names := make(map[uint32]string)
+	for _, name := range []string{regFilesImg, filesImg} {
+		if !criuImageExists(checkpointDirectory, name) {
+			continue
+		}
+		img, err := readCriuImage(checkpointDirectory, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range img.Entries {
+			var reg *images.RegFileEntry
+			switch e := entry.Message.(type) {
+			case *images.RegFileEntry:
+				reg = e
+			case *images.FileEntry:
+				reg = e.GetReg()
+			default:
+				return nil, fmt.Errorf("failed to type assert %s", name)
+			}
+			if reg != nil {
+				names[reg.GetId()] = reg.GetName()
+			}
+		}
+	}
+
+	return names, nil
+}
+
+// readGhostFileEntry decodes the first entry of a ghost file image, which
+// describes the deleted file, and returns the offset of the file content.
+// The entry is read directly as crit returns the last chunk instead of the
+// entry for ghost files stored in chunks.
+func readGhostFileEntry(path string) (*images.GhostFileEntry, int64, error) {
+	f, err := openCriuImage(path, "GHOST_FILE")
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+
+	entry := &images.GhostFileEntry{}
+	if err := readCriuEntry(f, entry); err != nil {
+		return nil, 0, err
+	}
+	offset, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return entry, offset, nil
+}
+
+func getGhostFiles(checkpointDirectory string) ([]ghostFile, error) {
+	if !criuImageExists(checkpointDirectory, remapFpathImg) {
+		return nil, nil
+	}
+	img, err := readCriuImage(checkpointDirectory, remapFpathImg)
+	if err != nil {
+		return nil, err
+	}
+	names, err := readRegularFileNames(checkpointDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	var ghosts []ghostFile
+	for _, entry := range img.Entries {
+		remap, ok := entry.Message.(*images.RemapFilePathEntry)
+		if !ok {
+			return nil, fmt.Errorf("failed to type assert %s", remapFpathImg)
+		}
+		if remap.GetRemapType() != images.RemapType_GHOST {
+			continue
+		}
+		path := filepath.Join(
+			imagesDirectory(checkpointDirectory),
+			fmt.Sprintf("ghost-file-%x.img", remap.GetRemapId()),
+		)
+		fi, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		ghostEntry, offset, err := readGhostFileEntry(path)
+		if err != nil {
+			return nil, err
+		}
+		ghost := ghostFile{
+			Path:   names[remap.GetOrigId()],
+			Size:   fi.Size() - offset,
+			Image:  fi.Size(),
+			image:  path,
+			offset: offset,
+		}
+		if ghost.Path == "" {
+			ghost.Path = fmt.Sprintf("(file %d)", remap.GetOrigId())
+		}
+		// Only ghost files stored in chunks record the size of the file,
+		// otherwise the complete content follows the entry
+		if ghostEntry.GetChunks() {
+			ghost.Size = int64(ghostEntry.GetSize())
+			ghost.image = ""
+		}
+		ghosts = append(ghosts, ghost)
+	}
+
+	sort.Slice(ghosts, func(i, j int) bool {
+		if ghosts[i].Image != ghosts[j].Image {
+			return ghosts[i].Image > ghosts[j].Image
+		}
+		return ghosts[i].Path < ghosts[j].Path
+	})
+
+	return ghosts, nil
+}
+
+// readGhostFileContent returns the content of a ghost file which is not
+// stored in chunks
+func readGhostFileContent(g ghostFile) ([]byte, error) {
+	if g.image == "" {
+		return nil, fmt.Errorf("content of %s is stored in chunks", g.Path)
+	}
+	f, err := os.Open(g.image)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if _, err := f.Seek(g.offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(f)
+}
+
+func showGhostFiles(checkpointDirectory string) error {
+	ghosts, err := getGhostFiles(checkpointDirectory)
+	if err != nil {
+		return fmt.Errorf("unable to display ghost files: %w", err)
+	}
+
+	printCaption("Ghost files")
+	if len(ghosts) == 0 {
+		fmt.Println("No ghost files found in checkpoint")
+		return nil
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	setTableHeader(table, []string{
+		"Path",
+		"Size",
+		"Image Size",
+	})
+	var total int64
+	for _, g := range ghosts {
+		table.Append([]string{g.Path, formatSize(g.Size), formatSize(g.Image)})
+		total += g.Image
+	}
+	table.Append([]string{"Total", "", formatSize(total)})
+	table.Render()
+
+	return nil
+}
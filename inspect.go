@@ -0,0 +1,63 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to combine the information about a container checkpoint
+// into a single report for monitoring systems
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/checkpoint-restore/go-criu/v6/crit/images"
+)
+
+// inspectSchemaVersion is increased on every incompatible change of
+// inspectOutput. Fields may be added without changing the version.
+const inspectSchemaVersion = 1
+
+// inspectOutput is the report of the inspect subcommand. Sections which
+// cannot be read from the checkpoint are null, so that a broken checkpoint
+// is still reported together with its failed validation.
+type inspectOutput struct {
+	SchemaVersion     int                       `json:"schemaVersion"`
+	Input             string                    `json:"input"`
+	Checkpoint        *checkpointOutput         `json:"checkpoint"`
+	ImageSizes        *imageSizes               `json:"imageSizes"`
+	DumpStatistics    *images.DumpStatsEntry    `json:"dumpStatistics"`
+	RestoreStatistics *images.RestoreStatsEntry `json:"restoreStatistics"`
+	Validation        validationOutput          `json:"validation"`
+}
+
+func getInspectOutput(input, checkpointDirectory string) (*inspectOutput, error) {
+	if isSandboxCheckpoint(checkpointDirectory) {
+		return nil, fmt.Errorf("%s is a pod sandbox checkpoint, which is not supported by inspect", input)
+	}
+
+	v := validateCheckpoint(checkpointDirectory)
+	out := &inspectOutput{
+		SchemaVersion: inspectSchemaVersion,
+		Input:         input,
+		Validation: validationOutput{
+			Input:  input,
+			Valid:  v.Valid(),
+			Checks: v.Checks,
+		},
+	}
+
+	if containerConfig, specDump, ci, err := readContainerCheckpoint(checkpointDirectory); err == nil {
+		if checkpoint, err := newCheckpointOutput(checkpointDirectory, containerConfig, specDump, ci); err == nil {
+			out.Checkpoint = checkpoint
+		}
+	}
+	if sizes, err := getImageSizes(checkpointDirectory, true); err == nil {
+		out.ImageSizes = sizes
+	}
+	if dumpStatistics, err := readDumpStats(checkpointDirectory); err == nil {
+		out.DumpStatistics = dumpStatistics
+	}
+	if restoreStatistics, err := readRestoreStats(checkpointDirectory); err == nil {
+		out.RestoreStatistics = restoreStatistics
+	}
+
+	return out, nil
+}
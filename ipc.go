@@ -0,0 +1,228 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to display the System V IPC objects of container checkpoints
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/checkpoint-restore/go-criu/v6/crit/images"
+	"github.com/olekukonko/tablewriter"
+)
+
+// ipcObjects are the System V IPC objects of an IPC namespace of one type
+type ipcObjects struct {
+	Type    string
+	Count   int
+	Size    int64
+	Details string
+}
+
+// ipcNamespaceID returns the ID of the IPC namespace of the container, which
+// is part of the names of the IPC images. CRIU only saves the IPC namespace
+// if the container has its own.
+func ipcNamespaceID(checkpointDirectory string) (uint32, bool, error) {
+	processes, err := readProcesses(checkpointDirectory)
+	if err != nil {
+		return 0, false, err
+	}
+	if len(processes) == 0 {
+		return 0, false, fmt.Errorf("%s does not contain any entries", pstreeImg)
+	}
+	if ids := processes[0].Core.GetIds(); ids != nil && ids.IpcNsId != nil {
+		return ids.GetIpcNsId(), criuImageExists(checkpointDirectory, fmt.Sprintf("ipcns-var-%d.img", ids.GetIpcNsId())), nil
+	}
+
+	vars, err := filepath.Glob(filepath.Join(imagesDirectory(checkpointDirectory), "ipcns-var-*.img"))
+	if err != nil || len(vars) != 1 {
+		return 0, false, err
+	}
+	var id uint32
+	if _, err := fmt.Sscanf(filepath.Base(vars[0]), "ipcns-var-%d.img", &id); err != nil {
+		return 0, false, nil
+	}
+
+	return id, true, nil
+}
+
+// readSharedMemory reads the segments of an ipcns-shm image. The IPC images
+// are read directly, as crit does not skip the padding of the extra data
+// following each entry correctly.
+func readSharedMemory(path string) (*ipcObjects, error) {
+	objects := &ipcObjects{Type: "Shared memory"}
+	f, err := openCriuImage(path, "IPCNS_SHM")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	hugetlb := 0
+	for {
+		shm := &images.IpcShmEntry{}
+		if err := readCriuEntry(f, shm); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		objects.Count++
+		objects.Size += int64(shm.GetSize())
+		if shm.GetHugetlbFlag() != 0 {
+			hugetlb++
+		}
+		// Newer versions of CRIU store the content in the page images
+		if shm.GetInPagemaps() {
+			continue
+		}
+		if err := skipCriuData(f, int64(shm.GetSize()), 4); err != nil {
+			return nil, err
+		}
+	}
+	if hugetlb > 0 {
+		objects.Details = fmt.Sprintf("%d with huge pages", hugetlb)
+	}
+
+	return objects, nil
+}
+
+func readSemaphores(path string) (*ipcObjects, error) {
+	objects := &ipcObjects{Type: "Semaphore sets"}
+	f, err := openCriuImage(path, "IPCNS_SEM")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	semaphores := 0
+	for {
+		sem := &images.IpcSemEntry{}
+		if err := readCriuEntry(f, sem); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		objects.Count++
+		semaphores += int(sem.GetNsems())
+		// The value of each semaphore is stored as 16 bit number
+		objects.Size += int64(sem.GetNsems()) * 2
+		if err := skipCriuData(f, int64(sem.GetNsems())*2, 8); err != nil {
+			return nil, err
+		}
+	}
+	objects.Details = fmt.Sprintf("%d semaphore(s)", semaphores)
+
+	return objects, nil
+}
+
+func readMessageQueues(path string) (*ipcObjects, error) {
+	objects := &ipcObjects{Type: "Message queues"}
+	f, err := openCriuImage(path, "IPCNS_MSG")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	messages := 0
+	for {
+		queue := &images.IpcMsgEntry{}
+		if err := readCriuEntry(f, queue); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		objects.Count++
+		// Each message of the queue is stored as entry followed by its content
+		for i := uint32(0); i < queue.GetQnum(); i++ {
+			msg := &images.IpcMsg{}
+			if err := readCriuEntry(f, msg); err != nil {
+				if errors.Is(err, io.EOF) {
+					return nil, fmt.Errorf("%s: %w", filepath.Base(path), io.ErrUnexpectedEOF)
+				}
+				return nil, err
+			}
+			messages++
+			objects.Size += int64(msg.GetMsize())
+			if err := skipCriuData(f, int64(msg.GetMsize()), 8); err != nil {
+				return nil, err
+			}
+		}
+	}
+	objects.Details = fmt.Sprintf("%d message(s)", messages)
+
+	return objects, nil
+}
+
+func getIpcObjects(checkpointDirectory string) ([]*ipcObjects, bool, error) {
+	id, ok, err := ipcNamespaceID(checkpointDirectory)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	var objects []*ipcObjects
+	for _, t := range []struct {
+		kind string
+		read func(path string) (*ipcObjects, error)
+	}{
+		{"shm", readSharedMemory},
+		{"sem", readSemaphores},
+		{"msg", readMessageQueues},
+	} {
+		name := fmt.Sprintf("ipcns-%s-%d.img", t.kind, id)
+		if !criuImageExists(checkpointDirectory, name) {
+			continue
+		}
+		o, err := t.read(filepath.Join(imagesDirectory(checkpointDirectory), name))
+		if err != nil {
+			return nil, true, err
+		}
+		if o.Count > 0 {
+			objects = append(objects, o)
+		}
+	}
+
+	return objects, true, nil
+}
+
+func showIpcObjects(checkpointDirectory string) error {
+	objects, namespace, err := getIpcObjects(checkpointDirectory)
+	if err != nil {
+		return fmt.Errorf("unable to display IPC objects: %w", err)
+	}
+
+	printCaption("IPC objects")
+	switch {
+	case !namespace:
+		fmt.Println("No IPC namespace found in checkpoint")
+		return nil
+	case len(objects) == 0:
+		fmt.Println("No IPC objects found in checkpoint")
+		return nil
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	setTableHeader(table, []string{
+		"Type",
+		"Objects",
+		"Size",
+		"Details",
+	})
+	for _, o := range objects {
+		table.Append([]string{
+			o.Type,
+			formatCount(int64(o.Count)),
+			formatSize(o.Size),
+			o.Details,
+		})
+	}
+	table.Render()
+
+	return nil
+}
@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to display the labels of containers
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	metadata "github.com/checkpoint-restore/checkpointctl/lib"
+	"github.com/olekukonko/tablewriter"
+	spec "github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// getContainerLabels returns the labels of the container. Podman stores
+// them in config.dump, CRI-O as JSON in an annotation of spec.dump.
+func getContainerLabels(containerConfig *metadata.ContainerConfig, specDump *spec.Spec) (map[string]string, error) {
+	if len(containerConfig.Labels) > 0 {
+		return containerConfig.Labels, nil
+	}
+
+	labels := map[string]string{}
+	if l, ok := specDump.Annotations["io.kubernetes.cri-o.Labels"]; ok {
+		if err := json.Unmarshal([]byte(l), &labels); err != nil {
+			return nil, fmt.Errorf("failed to read io.kubernetes.cri-o.Labels: %w", err)
+		}
+	}
+
+	return labels, nil
+}
+
+func showContainerLabels(containerConfig *metadata.ContainerConfig, specDump *spec.Spec) error {
+	labels, err := getContainerLabels(containerConfig, specDump)
+	if err != nil {
+		return fmt.Errorf("unable to display labels: %w", err)
+	}
+
+	printCaption("Labels")
+	if len(labels) == 0 {
+		fmt.Println("No labels found in checkpoint")
+		return nil
+	}
+
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	table := tablewriter.NewWriter(os.Stdout)
+	setTableHeader(table, []string{
+		"Label",
+		"Value",
+	})
+	for _, k := range keys {
+		table.Append([]string{k, displayValue(labels[k])})
+	}
+	table.Render()
+
+	return nil
+}
@@ -0,0 +1,20 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package metadata
+
+import "errors"
+
+// Errors which allow callers to tell broken checkpoints from checkpoints
+// which only lack the requested information, using errors.Is
+var (
+	// ErrUnknownContainerManager is returned for checkpoints created by a
+	// container engine which is not supported
+	ErrUnknownContainerManager = errors.New("unknown container manager")
+	// ErrSectionUnavailable is wrapped by the errors about missing optional
+	// parts of a checkpoint, like "pstree.img not found in checkpoint"
+	ErrSectionUnavailable = errors.New("not found in checkpoint")
+	// ErrNoFileManifest is returned for the descriptors.json written by
+	// runc and crun, which lists the paths of the stdio file descriptors
+	// of the container instead of the CRIU image files
+	ErrNoFileManifest = errors.New("descriptors.json lists file descriptors, not images")
+)
@@ -24,6 +24,7 @@ const (
 	DeletedFilesFile           = "deleted.files"
 	DumpLogFile                = "dump.log"
 	RestoreLogFile             = "restore.log"
+	DescriptorsFile            = "descriptors.json"
 	// pod archive
 	PodOptionsFile = "pod.options"
 	PodDumpFile    = "pod.dump"
@@ -43,6 +44,102 @@ type ContainerConfig struct {
 	CheckpointedAt  time.Time `json:"checkpointedTime"`
 	RestoredAt      time.Time `json:"restoredTime"`
 	Restored        bool      `json:"restored"`
+	// Podman stores the OCI runtime specification the container was
+	// created with, which contains the configured resource limits
+	Spec *spec.Spec `json:"spec,omitempty"`
+	// Static addresses of older versions of Podman
+	StaticIP  string `json:"staticIP,omitempty"`
+	StaticMAC string `json:"staticMAC,omitempty"`
+	// Networks of the container by name
+	Networks map[string]PerNetworkOptions `json:"newNetworks,omitempty"`
+	// Restart policy and health check as configured in Podman
+	RestartPolicy  string             `json:"restart_policy,omitempty"`
+	RestartRetries uint               `json:"restart_retries,omitempty"`
+	HealthCheck    *HealthCheckConfig `json:"healthcheck,omitempty"`
+	// Labels of the container as given to Podman
+	Labels map[string]string `json:"labels,omitempty"`
+	// Directory of the container in containers/storage as used by Podman
+	StaticDir string `json:"staticDir,omitempty"`
+}
+
+// HealthCheckConfig is the health check of a container as stored by Podman
+type HealthCheckConfig struct {
+	// The first element is NONE, CMD or CMD-SHELL followed by the command
+	Test        []string      `json:",omitempty"`
+	Interval    time.Duration `json:",omitempty"`
+	Timeout     time.Duration `json:",omitempty"`
+	StartPeriod time.Duration `json:",omitempty"`
+	Retries     int           `json:",omitempty"`
+}
+
+// PerNetworkOptions are the options of a network the container is connected to
+type PerNetworkOptions struct {
+	StaticIPs []string `json:"static_ips,omitempty"`
+	StaticMAC string   `json:"static_mac,omitempty"`
+}
+
+// This is a reduced copy of the CRI PodSandboxConfig which CRI-O stores
+// in pod.dump when checkpointing a pod sandbox
+type PodSandboxConfig struct {
+	Metadata     *PodSandboxMetadata    `json:"metadata,omitempty"`
+	Hostname     string                 `json:"hostname,omitempty"`
+	LogDirectory string                 `json:"log_directory,omitempty"`
+	DNSConfig    *DNSConfig             `json:"dns_config,omitempty"`
+	PortMappings []*PortMapping         `json:"port_mappings,omitempty"`
+	Labels       map[string]string      `json:"labels,omitempty"`
+	Annotations  map[string]string      `json:"annotations,omitempty"`
+	Linux        *LinuxPodSandboxConfig `json:"linux,omitempty"`
+}
+
+type PodSandboxMetadata struct {
+	Name      string `json:"name,omitempty"`
+	UID       string `json:"uid,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Attempt   uint32 `json:"attempt,omitempty"`
+}
+
+type DNSConfig struct {
+	Servers  []string `json:"servers,omitempty"`
+	Searches []string `json:"searches,omitempty"`
+	Options  []string `json:"options,omitempty"`
+}
+
+type PortMapping struct {
+	// 0 is TCP, 1 is UDP and 2 is SCTP
+	Protocol      int32  `json:"protocol,omitempty"`
+	ContainerPort int32  `json:"container_port,omitempty"`
+	HostPort      int32  `json:"host_port,omitempty"`
+	HostIP        string `json:"host_ip,omitempty"`
+}
+
+type LinuxPodSandboxConfig struct {
+	CgroupParent    string                       `json:"cgroup_parent,omitempty"`
+	SecurityContext *LinuxSandboxSecurityContext `json:"security_context,omitempty"`
+	Sysctls         map[string]string            `json:"sysctls,omitempty"`
+}
+
+type LinuxSandboxSecurityContext struct {
+	NamespaceOptions *NamespaceOption `json:"namespace_options,omitempty"`
+}
+
+// NamespaceOption contains the mode of the namespaces of the sandbox.
+// 0 is a namespace of the pod, 1 of the container, 2 of the node and
+// 3 of a target container.
+type NamespaceOption struct {
+	Network  int32  `json:"network,omitempty"`
+	Pid      int32  `json:"pid,omitempty"`
+	Ipc      int32  `json:"ipc,omitempty"`
+	TargetID string `json:"target_id,omitempty"`
+}
+
+// CheckpointedPodOptions is stored in pod.options of pod sandbox
+// checkpoints and lists the checkpointed containers of the pod
+type CheckpointedPodOptions struct {
+	Version      int               `json:"version"`
+	SandboxID    string            `json:"sandboxId,omitempty"`
+	Containers   map[string]string `json:"containers,omitempty"`
+	MountLabel   string            `json:"mountLabel,omitempty"`
+	ProcessLabel string            `json:"processLabel,omitempty"`
 }
 
 type ContainerdStatus struct {
@@ -71,11 +168,28 @@ type KubernetesContainerCheckpointMetadata struct {
 	Checkpoints   []KubernetesCheckpoint `json:"checkpoints"`
 }
 
+// CheckpointDescriptor is a single entry of the descriptors.json manifest
+// listing the CRIU image files of a checkpoint
+type CheckpointDescriptor struct {
+	Name string `json:"name"`
+	Size int64  `json:"size,omitempty"`
+}
+
+// ReadContainerCheckpointSpecDump reads spec.dump. Files written with
+// older versions of the OCI runtime specification are converted to the
+// current layout before they are unmarshalled.
 func ReadContainerCheckpointSpecDump(checkpointDirectory string) (*spec.Spec, string, error) {
 	var specDump spec.Spec
-	specDumpFile, err := ReadJSONFile(&specDump, checkpointDirectory, SpecDumpFile)
+	specDumpFile := filepath.Join(checkpointDirectory, SpecDumpFile)
+	content, err := os.ReadFile(specDumpFile)
+	if err != nil {
+		return &specDump, "", err
+	}
+	if err := json.Unmarshal(upgradeSpec(content), &specDump); err != nil {
+		return &specDump, "", fmt.Errorf("failed to unmarshal %s: %w", specDumpFile, err)
+	}
 
-	return &specDump, specDumpFile, err
+	return &specDump, specDumpFile, nil
 }
 
 func ReadContainerCheckpointConfigDump(checkpointDirectory string) (*ContainerConfig, string, error) {
@@ -85,6 +199,20 @@ func ReadContainerCheckpointConfigDump(checkpointDirectory string) (*ContainerCo
 	return &containerConfig, configDumpFile, err
 }
 
+func ReadPodSandboxCheckpointConfig(checkpointDirectory string) (*PodSandboxConfig, string, error) {
+	var podConfig PodSandboxConfig
+	podDumpFile, err := ReadJSONFile(&podConfig, checkpointDirectory, PodDumpFile)
+
+	return &podConfig, podDumpFile, err
+}
+
+func ReadPodSandboxCheckpointOptions(checkpointDirectory string) (*CheckpointedPodOptions, string, error) {
+	var podOptions CheckpointedPodOptions
+	podOptionsFile, err := ReadJSONFile(&podOptions, checkpointDirectory, PodOptionsFile)
+
+	return &podOptions, podOptionsFile, err
+}
+
 func ReadContainerCheckpointDeletedFiles(checkpointDirectory string) ([]string, string, error) {
 	var deletedFiles []string
 	deletedFilesFile, err := ReadJSONFile(&deletedFiles, checkpointDirectory, DeletedFilesFile)
@@ -99,6 +227,28 @@ func ReadContainerCheckpointStatusFile(checkpointDirectory string) (*ContainerdS
 	return &containerdStatus, statusFile, err
 }
 
+// ReadContainerCheckpointDescriptors reads descriptors.json from the directory
+// with the CRIU images, which is not necessarily CheckpointDirectory. The OCI
+// runtimes write a list of strings with the stdio file descriptors to the same
+// file, for which ErrNoFileManifest is returned.
+func ReadContainerCheckpointDescriptors(imagesDirectory string) ([]CheckpointDescriptor, string, error) {
+	descriptorsFile := filepath.Join(imagesDirectory, DescriptorsFile)
+	content, err := os.ReadFile(descriptorsFile)
+	if err != nil {
+		return nil, "", err
+	}
+	var stdio []string
+	if err := json.Unmarshal(content, &stdio); err == nil {
+		return nil, descriptorsFile, ErrNoFileManifest
+	}
+	var descriptors []CheckpointDescriptor
+	if err := json.Unmarshal(content, &descriptors); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal %s: %w", descriptorsFile, err)
+	}
+
+	return descriptors, descriptorsFile, nil
+}
+
 // WriteJSONFile marshalls and writes the given data to a JSON file
 func WriteJSONFile(v interface{}, dir, file string) (string, error) {
 	fileJSON, err := json.MarshalIndent(v, "", "  ")
@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package metadata
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// upgradeSpec converts fields of spec.dump files written with older
+// versions of the OCI runtime specification to the current layout. Newer
+// files are not changed. If the content is no valid JSON it is returned
+// as is to let the caller report the error.
+func upgradeSpec(content []byte) []byte {
+	var s map[string]interface{}
+	// Keep numbers as they are to not lose the precision of large values
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	if err := decoder.Decode(&s); err != nil {
+		return content
+	}
+	changed := false
+
+	process, _ := s["process"].(map[string]interface{})
+	linux, _ := s["linux"].(map[string]interface{})
+
+	// Older versions used a single list of capabilities
+	// for all capability sets
+	if process != nil {
+		if caps, ok := process["capabilities"].([]interface{}); ok {
+			process["capabilities"] = map[string]interface{}{
+				"bounding":    caps,
+				"effective":   caps,
+				"inheritable": caps,
+				"permitted":   caps,
+				"ambient":     caps,
+			}
+			changed = true
+		}
+	}
+
+	if linux != nil {
+		// Older versions kept the rlimits in linux instead of process
+		if rlimits, ok := linux["rlimits"]; ok {
+			if process == nil {
+				process = make(map[string]interface{})
+				s["process"] = process
+			}
+			if _, ok := process["rlimits"]; !ok {
+				process["rlimits"] = rlimits
+			}
+			delete(linux, "rlimits")
+			changed = true
+		}
+
+		// Older versions used a single name per seccomp rule
+		if seccomp, ok := linux["seccomp"].(map[string]interface{}); ok {
+			syscalls, _ := seccomp["syscalls"].([]interface{})
+			for _, sc := range syscalls {
+				syscall, ok := sc.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				if name, ok := syscall["name"].(string); ok {
+					if _, ok := syscall["names"]; !ok {
+						syscall["names"] = []string{name}
+					}
+					delete(syscall, "name")
+					changed = true
+				}
+			}
+		}
+
+		// Older versions used -1 to leave the swappiness unset,
+		// which cannot be represented anymore
+		if resources, ok := linux["resources"].(map[string]interface{}); ok {
+			if memory, ok := resources["memory"].(map[string]interface{}); ok {
+				if swappiness, ok := memory["swappiness"].(json.Number); ok && strings.HasPrefix(string(swappiness), "-") {
+					delete(memory, "swappiness")
+					changed = true
+				}
+			}
+		}
+	}
+
+	if !changed {
+		return content
+	}
+	upgraded, err := json.Marshal(s)
+	if err != nil {
+		return content
+	}
+
+	return upgraded
+}
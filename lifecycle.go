@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to display the restart policy and health check of containers
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	metadata "github.com/checkpoint-restore/checkpointctl/lib"
+	"github.com/olekukonko/tablewriter"
+)
+
+const lifecycleNone = "none"
+
+func formatLifecycleDuration(d time.Duration) string {
+	if d == 0 {
+		return lifecycleNone
+	}
+
+	return d.String()
+}
+
+// restartPolicy returns the restart policy of the container with the
+// number of retries of the on-failure policy
+func restartPolicy(containerConfig *metadata.ContainerConfig) string {
+	if containerConfig.RestartPolicy == "" || containerConfig.RestartPolicy == "no" {
+		return lifecycleNone
+	}
+	if containerConfig.RestartRetries > 0 {
+		return fmt.Sprintf("%s (%d retries)", containerConfig.RestartPolicy, containerConfig.RestartRetries)
+	}
+
+	return containerConfig.RestartPolicy
+}
+
+// healthCheckCommand returns the command of the health check. Commands
+// of type CMD-SHELL are run by the shell of the container.
+func healthCheckCommand(hc *metadata.HealthCheckConfig) string {
+	if hc == nil || len(hc.Test) < 2 || hc.Test[0] == "NONE" {
+		return lifecycleNone
+	}
+	command := strings.Join(hc.Test[1:], " ")
+	if hc.Test[0] == "CMD-SHELL" {
+		return "/bin/sh -c " + command
+	}
+
+	return command
+}
+
+// showLifecycle displays how the container engine supervises the container,
+// which should be configured the same way for the restored container
+func showLifecycle(containerConfig *metadata.ContainerConfig) {
+	hc := containerConfig.HealthCheck
+	command := healthCheckCommand(hc)
+	interval, timeout, retries := lifecycleNone, lifecycleNone, lifecycleNone
+	if command != lifecycleNone {
+		interval = formatLifecycleDuration(hc.Interval)
+		timeout = formatLifecycleDuration(hc.Timeout)
+		if hc.Retries > 0 {
+			retries = fmt.Sprintf("%d", hc.Retries)
+		}
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	setTableHeader(table, []string{
+		"Setting",
+		"Value",
+	})
+	table.AppendBulk([][]string{
+		{"Restart Policy", restartPolicy(containerConfig)},
+		{"Health Check", displayValue(command)},
+		{"Health Check Interval", interval},
+		{"Health Check Timeout", timeout},
+		{"Health Check Retries", retries},
+	})
+	printCaption("Lifecycle")
+	table.Render()
+}
@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to display the CRIU log files of container checkpoints
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	metadata "github.com/checkpoint-restore/checkpointctl/lib"
+)
+
+const (
+	colorRed    = "\033[31m"
+	colorYellow = "\033[33m"
+	colorReset  = "\033[0m"
+)
+
+var (
+	// CRIU prefixes messages logged with pr_err and pr_warn like
+	// "(00.012345) Error (criu/cr-dump.c:1234): ..."
+	criuLogError   = regexp.MustCompile(`\bError \(`)
+	criuLogWarning = regexp.MustCompile(`\bWarn\s+\(`)
+)
+
+// findDumpLog returns the path of the CRIU dump log of the checkpoint.
+// Container engines store it next to the metadata or in the CRIU images
+// directory. It is empty if the checkpoint does not include a dump log.
+func findDumpLog(checkpointDirectory string) string {
+	for _, p := range []string{
+		filepath.Join(checkpointDirectory, metadata.DumpLogFile),
+		filepath.Join(imagesDirectory(checkpointDirectory), metadata.DumpLogFile),
+	} {
+		if _, err := os.Stat(p); err == nil {
+			return p
+		}
+	}
+
+	return ""
+}
+
+// isTerminal returns true if stdout is connected to a terminal
+func isTerminal() bool {
+	fi, err := os.Stdout.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// showDumpLog prints the CRIU dump log of the checkpoint, or only its
+// last lines if lines is greater than 0. On a terminal error and warning
+// lines are highlighted.
+func showDumpLog(checkpointDirectory string, lines int) error {
+	printCaption("CRIU dump log")
+	path := findDumpLog(checkpointDirectory)
+	if path == "" {
+		fmt.Printf("No %s included in checkpoint\n", metadata.DumpLogFile)
+		return nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("unable to display dump log: %w", err)
+	}
+	defer f.Close()
+
+	var log []string
+	total, errorLines, warningLines := 0, 0, 0
+	scanner := bufio.NewScanner(f)
+	// Lines with large hex dumps can exceed the default buffer size
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case criuLogError.MatchString(line):
+			errorLines++
+		case criuLogWarning.MatchString(line):
+			warningLines++
+		}
+		total++
+		log = append(log, line)
+		if lines > 0 && len(log) > lines {
+			log = log[1:]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("unable to display dump log: %w", err)
+	}
+
+	color := isTerminal() && os.Getenv("NO_COLOR") == ""
+	for _, line := range log {
+		switch {
+		case color && criuLogError.MatchString(line):
+			fmt.Println(colorRed + line + colorReset)
+		case color && criuLogWarning.MatchString(line):
+			fmt.Println(colorYellow + line + colorReset)
+		default:
+			fmt.Println(line)
+		}
+	}
+	summary := fmt.Sprintf("%s: %s, %s", metadata.DumpLogFile, plural(errorLines, "error"), plural(warningLines, "warning"))
+	if len(log) < total {
+		summary += fmt.Sprintf(" (last %d of %d lines shown)", len(log), total)
+	}
+	fmt.Println(summary)
+
+	return nil
+}
@@ -0,0 +1,57 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to print container checkpoints as logfmt records
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type logfmtField struct {
+	key   string
+	value string
+}
+
+// logfmtRecord is implemented by the results which can be printed
+// as a single line with --output logfmt
+type logfmtRecord interface {
+	logfmtFields() []logfmtField
+}
+
+// logfmtValue quotes a value if it contains spaces, quotes, equal signs
+// or characters which are not printable
+func logfmtValue(value string) string {
+	if !utf8.ValidString(value) {
+		return strconv.Quote(value)
+	}
+	for _, r := range value {
+		if r == ' ' || r == '=' || r == '"' || r == '\\' || !unicode.IsPrint(r) {
+			return strconv.Quote(value)
+		}
+	}
+
+	return value
+}
+
+// printLogfmt prints the fields as one line of key=value pairs. Fields
+// without a value are left out like empty fields in the JSON output.
+func printLogfmt(fields []logfmtField) {
+	var pairs []string
+	for _, f := range fields {
+		if f.value == "" {
+			continue
+		}
+		pairs = append(pairs, f.key+"="+logfmtValue(f.value))
+	}
+	fmt.Println(strings.Join(pairs, " "))
+}
+
+// logfmtKey turns a name like "CRIU images" into a key like "criu_images"
+func logfmtKey(name string) string {
+	return strings.ReplaceAll(strings.ToLower(name), " ", "_")
+}
@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to display the mandatory access control settings of container checkpoints
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	spec "github.com/opencontainers/runtime-spec/specs-go"
+)
+
+const (
+	apparmorProfilesFile = "/sys/kernel/security/apparmor/profiles"
+
+	// Containers running with this profile are not confined by AppArmor
+	apparmorUnconfined = "unconfined"
+)
+
+func apparmorProfile(specDump *spec.Spec) string {
+	if specDump.Process == nil {
+		return ""
+	}
+
+	return specDump.Process.ApparmorProfile
+}
+
+func showMACProfile(specDump *spec.Spec) {
+	profile := apparmorProfile(specDump)
+	if profile == "" {
+		profile = "-"
+	}
+	label := "-"
+	if specDump.Process != nil && specDump.Process.SelinuxLabel != "" {
+		label = specDump.Process.SelinuxLabel
+	}
+	mountLabel := "-"
+	if specDump.Linux != nil && specDump.Linux.MountLabel != "" {
+		mountLabel = specDump.Linux.MountLabel
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	setTableHeader(table, []string{
+		"AppArmor Profile",
+		"SELinux Label",
+		"Mount Label",
+	})
+	table.Append([]string{profile, label, mountLabel})
+	printCaption("Mandatory access control")
+	table.Render()
+}
+
+// loadedAppArmorProfiles returns the names of the AppArmor profiles
+// loaded into the kernel of this host. Each line of the profiles file
+// contains the name of a profile followed by its mode in parentheses.
+func loadedAppArmorProfiles() (map[string]bool, error) {
+	f, err := os.Open(apparmorProfilesFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	profiles := make(map[string]bool)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.LastIndex(line, " ("); i != -1 {
+			line = line[:i]
+		}
+		profiles[line] = true
+	}
+
+	return profiles, scanner.Err()
+}
+
+// checkAppArmorProfile prints a warning to stderr if the AppArmor profile
+// of the container is not loaded on this host, as the OCI runtime would
+// fail to apply it during restore.
+func checkAppArmorProfile(specDump *spec.Spec) {
+	profile := apparmorProfile(specDump)
+	if profile == "" || profile == apparmorUnconfined {
+		return
+	}
+
+	profiles, err := loadedAppArmorProfiles()
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		fmt.Fprintf(os.Stderr, "Warning: AppArmor profile %s cannot be applied, AppArmor is not enabled on this host\n", profile)
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "Warning: unable to check AppArmor profile %s: %v\n", profile, err)
+	case !profiles[profile]:
+		fmt.Fprintf(os.Stderr, "Warning: AppArmor profile %s is not loaded on this host\n", profile)
+	}
+}
@@ -0,0 +1,559 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to display the memory of the processes in container checkpoints
+
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+
+	metadata "github.com/checkpoint-restore/checkpointctl/lib"
+	"github.com/checkpoint-restore/go-criu/v6/crit"
+	"github.com/checkpoint-restore/go-criu/v6/crit/images"
+	"github.com/olekukonko/tablewriter"
+	spec "github.com/opencontainers/runtime-spec/specs-go"
+)
+
+const (
+	// Flag of shared mappings in the VMAs of mm.img (MAP_SHARED)
+	mapShared = 0x01
+
+	// Link to the images of the previous checkpoint created by CRIU
+	parentImagesLink = "parent"
+
+	// Memory limit reported by cgroup v1 if no limit is set, the
+	// largest number of bytes which is a multiple of the page size
+	unlimitedMemory = math.MaxInt64 &^ (pageSize - 1)
+
+	// Flag of pagemap entries with pages stored in the pages image.
+	// Pages of other entries are in the parent checkpoint or lazy.
+	pagemapPresent = 0x04
+
+	// Magic number of zstd frames, which compressed pages images start with.
+	// Uncompressed pages images have no header.
+	zstdMagic = 0xFD2FB528
+
+	// Status flags of the VMAs in mm.img (VMA_FILE_PRIVATE, VMA_FILE_SHARED,
+	// VMA_ANON_SHARED, VMA_ANON_PRIVATE and VMA_AREA_SYSVIPC)
+	vmaFilePrivate = 1 << 6
+	vmaFileShared  = 1 << 7
+	vmaAnonShared  = 1 << 8
+	vmaAnonPrivate = 1 << 9
+	vmaSysVIPC     = 1 << 10
+
+	memTrackingEnabled  = "enabled"
+	memTrackingDisabled = "disabled"
+	memTrackingUnknown  = "unknown"
+)
+
+// sharedRegion identifies a shared mapping independent of the
+// address it is mapped at in the individual processes
+type sharedRegion struct {
+	Shmid uint64
+	Pgoff uint64
+	Size  uint64
+}
+
+// memoryUsage sums up the memory regions of all processes. Regions
+// which are shared between processes are only counted once, the mappings
+// count each process mapping a region.
+type memoryUsage struct {
+	SharedRegions   int
+	SharedMappings  int
+	SharedSize      uint64
+	PrivateRegions  int
+	PrivateMappings int
+	PrivateSize     uint64
+}
+
+// processMemory is the memory of a process by the kind of its mappings
+type processMemory struct {
+	PID        uint32
+	Comm       string
+	Anonymous  uint64
+	FileBacked uint64
+	Shared     uint64
+}
+
+// readMm decodes the mm image with the memory mappings of a process
+func readMm(checkpointDirectory string, pid uint32) (*images.MmEntry, error) {
+	name := fmt.Sprintf("mm-%d.img", pid)
+	img, err := readCriuImage(checkpointDirectory, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(img.Entries) == 0 {
+		return nil, fmt.Errorf("%s does not contain any entries", name)
+	}
+	mm, ok := img.Entries[0].Message.(*images.MmEntry)
+	if !ok {
+		return nil, fmt.Errorf("failed to type assert %s", name)
+	}
+
+	return mm, nil
+}
+
+// readPagemap decodes the pagemap image of a process with the ID of its
+// pages image and the address ranges of the memory pages in the checkpoint
+func readPagemap(checkpointDirectory string, pid uint32) (*images.PagemapHead, []*images.PagemapEntry, error) {
+	name := fmt.Sprintf("pagemap-%d.img", pid)
+	if !criuImageExists(checkpointDirectory, name) {
+		return nil, nil, fmt.Errorf("%s %w", name, metadata.ErrSectionUnavailable)
+	}
+	img, err := readCriuImage(checkpointDirectory, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(img.Entries) == 0 {
+		return nil, nil, fmt.Errorf("%s does not contain any entries", name)
+	}
+	head, ok := img.Entries[0].Message.(*images.PagemapHead)
+	if !ok {
+		return nil, nil, fmt.Errorf("failed to type assert %s", name)
+	}
+
+	var entries []*images.PagemapEntry
+	for _, entry := range img.Entries[1:] {
+		pm, ok := entry.Message.(*images.PagemapEntry)
+		if !ok {
+			return nil, nil, fmt.Errorf("failed to type assert %s", name)
+		}
+		entries = append(entries, pm)
+	}
+
+	return head, entries, nil
+}
+
+// dumpedMemory returns the size of the memory pages of a process which are
+// stored in the pages image of this checkpoint
+func dumpedMemory(checkpointDirectory string, pid uint32) (uint64, error) {
+	_, entries, err := readPagemap(checkpointDirectory, pid)
+	if err != nil {
+		return 0, err
+	}
+	var size uint64
+	for _, pm := range entries {
+		if pagemapEntryPresent(pm) {
+			size += uint64(pm.GetNrPages()) * pageSize
+		}
+	}
+
+	return size, nil
+}
+
+// pagemapEntryPresent returns true if the pages of a pagemap entry are
+// stored in the pages image of this checkpoint
+func pagemapEntryPresent(pm *images.PagemapEntry) bool {
+	// Older versions of CRIU only mark pages of the parent checkpoint
+	return pm.GetFlags()&pagemapPresent != 0 || (pm.Flags == nil && !pm.GetInParent())
+}
+
+// readProcessMemory returns the memory of a process from start to end. Only
+// memory stored in the pages image of this checkpoint can be read.
+func readProcessMemory(checkpointDirectory string, pid uint32, start, end uint64) ([]byte, error) {
+	if end <= start {
+		return nil, nil
+	}
+	head, entries, err := readPagemap(checkpointDirectory, pid)
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(imagesDirectory(checkpointDirectory), fmt.Sprintf("pages-%d.img", head.GetPagesId()))
+	if compressed, err := isCompressedPages(path); err != nil {
+		return nil, err
+	} else if compressed {
+		return nil, fmt.Errorf("memory of process %d is stored in compressed %s, which cannot be read", pid, filepath.Base(path))
+	}
+	pages, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer pages.Close()
+
+	data := make([]byte, 0, end-start)
+	var offset int64
+	for _, pm := range entries {
+		size := uint64(pm.GetNrPages()) * pageSize
+		present := pagemapEntryPresent(pm)
+		addr := start + uint64(len(data))
+		if present && addr >= pm.GetVaddr() && addr < pm.GetVaddr()+size {
+			n := pm.GetVaddr() + size - addr
+			if n > end-addr {
+				n = end - addr
+			}
+			buf := make([]byte, n)
+			if _, err := pages.ReadAt(buf, offset+int64(addr-pm.GetVaddr())); err != nil {
+				return nil, fmt.Errorf("failed to read memory of process %d: %w", pid, err)
+			}
+			data = append(data, buf...)
+			if uint64(len(data)) == end-start {
+				return data, nil
+			}
+		}
+		if present {
+			offset += int64(size)
+		}
+	}
+
+	return nil, fmt.Errorf("memory of process %d at %#x not found in checkpoint", pid, start+uint64(len(data)))
+}
+
+func getMemoryUsage(checkpointDirectory string) (*memoryUsage, error) {
+	processes, err := readProcesses(checkpointDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	// Number of processes mapping a shared region
+	shared := make(map[sharedRegion]int)
+	usage := &memoryUsage{}
+	for _, p := range processes {
+		mm, err := readMm(checkpointDirectory, p.PID)
+		if err != nil {
+			return nil, err
+		}
+		for _, vma := range mm.GetVmas() {
+			size := vma.GetEnd() - vma.GetStart()
+			if vma.GetFlags()&mapShared == 0 {
+				usage.PrivateRegions++
+				usage.PrivateMappings++
+				usage.PrivateSize += size
+				continue
+			}
+			shared[sharedRegion{vma.GetShmid(), vma.GetPgoff(), size}]++
+		}
+	}
+
+	for region, mappings := range shared {
+		if mappings < 2 {
+			// Shared mappings used by a single process are not
+			// different from private mappings for the footprint
+			usage.PrivateRegions++
+			usage.PrivateMappings += mappings
+			usage.PrivateSize += region.Size
+			continue
+		}
+		usage.SharedRegions++
+		usage.SharedMappings += mappings
+		usage.SharedSize += region.Size
+	}
+
+	return usage, nil
+}
+
+func showSharedMemory(checkpointDirectory string) error {
+	usage, err := getMemoryUsage(checkpointDirectory)
+	if err != nil {
+		return fmt.Errorf("unable to display shared memory: %w", err)
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	setTableHeader(table, []string{
+		"Memory",
+		"Regions",
+		"Mappings",
+		"Size",
+	})
+	table.Append([]string{
+		"Shared",
+		formatCount(int64(usage.SharedRegions)),
+		formatCount(int64(usage.SharedMappings)),
+		formatSize(int64(usage.SharedSize)),
+	})
+	table.Append([]string{
+		"Private",
+		formatCount(int64(usage.PrivateRegions)),
+		formatCount(int64(usage.PrivateMappings)),
+		formatSize(int64(usage.PrivateSize)),
+	})
+	table.Append([]string{
+		"Total",
+		formatCount(int64(usage.SharedRegions + usage.PrivateRegions)),
+		formatCount(int64(usage.SharedMappings + usage.PrivateMappings)),
+		formatSize(int64(usage.SharedSize + usage.PrivateSize)),
+	})
+	printCaption("Shared memory between processes")
+	table.Render()
+
+	return nil
+}
+
+// getProcessMemory returns the memory of each selected process. Anonymous
+// and file-backed memory are the private pages stored in the pagemap of the
+// process. CRIU stores the contents of shared mappings once for all
+// processes, so the size of the shared mappings is used instead.
+func getProcessMemory(checkpointDirectory string) ([]processMemory, error) {
+	processes, err := readProcesses(checkpointDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	var memory []processMemory
+	for _, p := range processes {
+		if !processSelected(p) {
+			continue
+		}
+		mm, err := readMm(checkpointDirectory, p.PID)
+		if err != nil {
+			return nil, err
+		}
+		_, entries, err := readPagemap(checkpointDirectory, p.PID)
+		if err != nil {
+			return nil, err
+		}
+
+		m := processMemory{PID: p.PID, Comm: p.Comm}
+		vmas := mm.GetVmas()
+		for _, vma := range vmas {
+			if vma.GetStatus()&(vmaFileShared|vmaAnonShared|vmaSysVIPC) != 0 {
+				m.Shared += vma.GetEnd() - vma.GetStart()
+			}
+		}
+		// The VMAs and pagemap entries are both sorted by address
+		i := 0
+		for _, pm := range entries {
+			start := pm.GetVaddr()
+			end := start + uint64(pm.GetNrPages())*pageSize
+			for i < len(vmas) && vmas[i].GetEnd() <= start {
+				i++
+			}
+			for j := i; j < len(vmas) && vmas[j].GetStart() < end; j++ {
+				size := overlap(start, end, vmas[j].GetStart(), vmas[j].GetEnd())
+				switch status := vmas[j].GetStatus(); {
+				case status&vmaAnonPrivate != 0:
+					m.Anonymous += size
+				case status&vmaFilePrivate != 0:
+					m.FileBacked += size
+				}
+			}
+		}
+		memory = append(memory, m)
+	}
+
+	return memory, nil
+}
+
+// overlap returns the size of the intersection of two address ranges
+func overlap(start1, end1, start2, end2 uint64) uint64 {
+	if start2 > start1 {
+		start1 = start2
+	}
+	if end2 < end1 {
+		end1 = end2
+	}
+	if end1 <= start1 {
+		return 0
+	}
+
+	return end1 - start1
+}
+
+func showProcessMemory(checkpointDirectory string) error {
+	memory, err := getProcessMemory(checkpointDirectory)
+	if err != nil {
+		return fmt.Errorf("unable to display memory pages: %w", err)
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	setTableHeader(table, []string{
+		"PID",
+		"Command",
+		"Anonymous",
+		"File-backed",
+		"Shared",
+		"Total",
+	})
+	for _, m := range memory {
+		table.Append([]string{
+			fmt.Sprintf("%d", m.PID),
+			m.Comm,
+			formatSize(int64(m.Anonymous)),
+			formatSize(int64(m.FileBacked)),
+			formatSize(int64(m.Shared)),
+			formatSize(int64(m.Anonymous + m.FileBacked + m.Shared)),
+		})
+	}
+	printCaption("Memory pages per process")
+	table.Render()
+
+	return nil
+}
+
+// getMemoryTracking determines if the checkpoint was created on top of a
+// previous (pre-)checkpoint with memory change tracking. It returns
+// enabled, disabled or unknown and how this was determined.
+func getMemoryTracking(checkpointDirectory string) (string, string) {
+	if _, err := os.Lstat(filepath.Join(imagesDirectory(checkpointDirectory), parentImagesLink)); err == nil {
+		return memTrackingEnabled, "checkpoint references the images of a previous checkpoint"
+	}
+
+	dumpStatistics, err := crit.GetDumpStats(checkpointDirectory)
+	if err != nil {
+		return memTrackingUnknown, "no parent images and no dump statistics found"
+	}
+	if dumpStatistics.GetPagesSkippedParent() > 0 {
+		return memTrackingEnabled, fmt.Sprintf(
+			"%s unchanged pages were taken from the previous checkpoint",
+			formatCount(int64(dumpStatistics.GetPagesSkippedParent())),
+		)
+	}
+
+	return memTrackingDisabled, "no pages were taken from a previous checkpoint"
+}
+
+func showMemoryTracking(checkpointDirectory string) {
+	status, reason := getMemoryTracking(checkpointDirectory)
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	setTableHeader(table, []string{
+		"Memory Tracking",
+		"Determined By",
+	})
+	table.Append([]string{status, reason})
+	printCaption("Memory change tracking")
+	table.Render()
+}
+
+// pagesSize is the size of the memory pages in the checkpoint. Newer
+// versions of CRIU can compress the pages images, then the size of the
+// memory is estimated from the pagemap images.
+type pagesSize struct {
+	Stored       int64
+	Uncompressed int64
+	Compressed   bool
+}
+
+// isCompressedPages returns true if the pages image at path is compressed
+func isCompressedPages(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(f, buf); err != nil {
+		// Empty pages images are not compressed
+		return false, nil
+	}
+
+	return binary.LittleEndian.Uint32(buf) == zstdMagic, nil
+}
+
+// storedPagesSizes returns the size of the pages stored in each pages
+// image according to the pagemap images, by the ID of the pages image
+func storedPagesSizes(checkpointDirectory string) (map[uint32]int64, error) {
+	pagemaps, err := filepath.Glob(filepath.Join(imagesDirectory(checkpointDirectory), "pagemap-*.img"))
+	if err != nil {
+		return nil, err
+	}
+	sizes := make(map[uint32]int64)
+	for _, p := range pagemaps {
+		var pid uint32
+		if _, err := fmt.Sscanf(filepath.Base(p), "pagemap-%d.img", &pid); err != nil {
+			continue
+		}
+		head, entries, err := readPagemap(checkpointDirectory, pid)
+		if err != nil {
+			return nil, err
+		}
+		for _, pm := range entries {
+			if pagemapEntryPresent(pm) {
+				sizes[head.GetPagesId()] += int64(pm.GetNrPages()) * pageSize
+			}
+		}
+	}
+
+	return sizes, nil
+}
+
+// getPagesSizes returns the stored and the uncompressed size of the
+// memory pages in the checkpoint
+func getPagesSizes(checkpointDirectory string) (*pagesSize, error) {
+	pages, err := filepath.Glob(filepath.Join(imagesDirectory(checkpointDirectory), "pages-*.img"))
+	if err != nil {
+		return nil, err
+	}
+	size := &pagesSize{}
+	var uncompressed map[uint32]int64
+	for _, p := range pages {
+		fi, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		size.Stored += fi.Size()
+		compressed, err := isCompressedPages(p)
+		if err != nil {
+			return nil, err
+		}
+		var id uint32
+		if _, err := fmt.Sscanf(filepath.Base(p), "pages-%d.img", &id); !compressed || err != nil {
+			size.Uncompressed += fi.Size()
+			continue
+		}
+		size.Compressed = true
+		if uncompressed == nil {
+			if uncompressed, err = storedPagesSizes(checkpointDirectory); err != nil {
+				return nil, fmt.Errorf("unable to estimate the size of compressed pages: %w", err)
+			}
+		}
+		size.Uncompressed += uncompressed[id]
+	}
+
+	return size, nil
+}
+
+// memoryLimit returns the memory limit of the container. It is false
+// if the memory of the container was not limited.
+func memoryLimit(specDump *spec.Spec) (int64, bool) {
+	if specDump.Linux == nil || specDump.Linux.Resources == nil || specDump.Linux.Resources.Memory == nil {
+		return 0, false
+	}
+	limit := specDump.Linux.Resources.Memory.Limit
+	if limit == nil || *limit <= 0 || *limit >= unlimitedMemory {
+		return 0, false
+	}
+
+	return *limit, true
+}
+
+func limitPercentage(size, limit int64) float64 {
+	return float64(size) / float64(limit) * 100
+}
+
+func showMemoryLimitUsage(checkpointDirectory string, specDump *spec.Spec) error {
+	pages, err := getPagesSizes(checkpointDirectory)
+	if err != nil {
+		return fmt.Errorf("unable to display memory usage: %w", err)
+	}
+	size := pages.Uncompressed
+
+	table := tablewriter.NewWriter(os.Stdout)
+	header := []string{"Memory"}
+	row := []string{formatSize(size)}
+	if pages.Compressed {
+		// The memory is estimated, the compressed size is what the
+		// checkpoint occupies on disk
+		header[0] = "Memory (uncompressed)"
+		header = append(header, "Compressed")
+		row = append(row, formatSize(pages.Stored))
+	}
+	header = append(header, "Limit", "Usage")
+	if limit, ok := memoryLimit(specDump); ok {
+		row = append(row, formatSize(limit), formatPercent(limitPercentage(size, limit)))
+	} else {
+		row = append(row, "unlimited", "-")
+	}
+	setTableHeader(table, header)
+	table.Append(row)
+	printCaption("Memory usage")
+	table.Render()
+
+	return nil
+}
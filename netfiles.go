@@ -0,0 +1,156 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to display the network configuration files of container checkpoints
+
+package main
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	metadata "github.com/checkpoint-restore/checkpointctl/lib"
+	spec "github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// networkFiles are the files with the network configuration of a container,
+// which container engines usually generate and bind mount into the container
+var networkFiles = []string{"/etc/resolv.conf", "/etc/hostname", "/etc/hosts"}
+
+// Files larger than this are no configuration files worth displaying
+const maxNetworkFileSize = 64 * 1024
+
+type networkFile struct {
+	Path    string
+	Source  string
+	Content string
+}
+
+// readRootFsDiffFiles returns the files with one of the given paths from
+// the changes to the root file system of the container
+func readRootFsDiffFiles(checkpointDirectory string, paths []string) ([]networkFile, error) {
+	f, err := os.Open(filepath.Join(checkpointDirectory, metadata.RootFsDiffTar))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r, err := decompress(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", metadata.RootFsDiffTar, err)
+	}
+	wanted := make(map[string]bool)
+	for _, p := range paths {
+		wanted[p] = true
+	}
+
+	var files []networkFile
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return files, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", metadata.RootFsDiffTar, err)
+		}
+		name := filepath.Clean("/" + hdr.Name)
+		if !wanted[name] {
+			continue
+		}
+		file := networkFile{Path: name, Source: metadata.RootFsDiffTar}
+		switch hdr.Typeflag {
+		case tar.TypeSymlink:
+			file.Content = fmt.Sprintf("(symbolic link to %s)\n", hdr.Linkname)
+		case tar.TypeReg:
+			if hdr.Size > maxNetworkFileSize {
+				file.Content = fmt.Sprintf("(%s, not displayed)\n", formatSize(hdr.Size))
+				break
+			}
+			content, err := io.ReadAll(tr)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", metadata.RootFsDiffTar, err)
+			}
+			file.Content = string(content)
+		default:
+			continue
+		}
+		files = append(files, file)
+	}
+}
+
+func getNetworkFiles(checkpointDirectory string) ([]networkFile, error) {
+	files, err := readRootFsDiffFiles(checkpointDirectory, networkFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	// Files which were replaced while a process still had them open
+	// are only found in the ghost files
+	ghosts, err := getGhostFiles(checkpointDirectory)
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range ghosts {
+		found := false
+		for _, p := range networkFiles {
+			found = found || g.Path == p
+		}
+		if !found {
+			continue
+		}
+		file := networkFile{Path: g.Path, Source: "ghost file"}
+		if g.Size > maxNetworkFileSize || g.image == "" {
+			file.Content = fmt.Sprintf("(%s, not displayed)\n", formatSize(g.Size))
+		} else {
+			content, err := readGhostFileContent(g)
+			if err != nil {
+				return nil, err
+			}
+			file.Content = string(content)
+		}
+		files = append(files, file)
+	}
+
+	return files, nil
+}
+
+func showNetworkFiles(checkpointDirectory string, specDump *spec.Spec) error {
+	files, err := getNetworkFiles(checkpointDirectory)
+	if err != nil {
+		return fmt.Errorf("unable to display network files: %w", err)
+	}
+
+	printCaption("Network files")
+	included := make(map[string]bool)
+	for _, f := range files {
+		included[f.Path] = true
+		fmt.Printf("%s (from %s):\n", f.Path, f.Source)
+		fmt.Print(f.Content)
+		if !strings.HasSuffix(f.Content, "\n") {
+			fmt.Println()
+		}
+	}
+	for _, p := range networkFiles {
+		if included[p] {
+			continue
+		}
+		for _, m := range specDump.Mounts {
+			if m.Destination == p && m.Type == "bind" {
+				fmt.Printf("%s: bind mounted from %s, not included in checkpoint\n", p, m.Source)
+			}
+		}
+	}
+	if len(files) == 0 {
+		fmt.Println("No network files found in checkpoint")
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to compare the open files of the checkpointed processes
+// with the limit of open files on this host
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/checkpoint-restore/go-criu/v6/crit/images"
+	"golang.org/x/sys/unix"
+)
+
+// processOpenFiles are the file descriptors of a process. Restoring a file
+// descriptor requires a limit of open files above its number, so the
+// highest file descriptor can matter more than their count.
+type processOpenFiles struct {
+	PID     uint32
+	Comm    string
+	Count   int
+	Highest uint32
+}
+
+// readOpenFiles returns the file descriptors of each process. They are
+// stored in fdinfo-<id>.img for the file descriptor table of the process,
+// which processes created with CLONE_FILES share.
+func readOpenFiles(checkpointDirectory string) ([]processOpenFiles, error) {
+	processes, err := readProcesses(checkpointDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []processOpenFiles
+	for _, p := range processes {
+		ids := p.Core.GetIds()
+		if ids == nil || ids.FilesId == nil {
+			continue
+		}
+		name := fmt.Sprintf("fdinfo-%d.img", ids.GetFilesId())
+		if !criuImageExists(checkpointDirectory, name) {
+			continue
+		}
+		img, err := readCriuImage(checkpointDirectory, name)
+		if err != nil {
+			return nil, err
+		}
+		f := processOpenFiles{PID: p.PID, Comm: p.Comm}
+		for _, entry := range img.Entries {
+			fd, ok := entry.Message.(*images.FdinfoEntry)
+			if !ok {
+				return nil, fmt.Errorf("failed to type assert %s", name)
+			}
+			f.Count++
+			if fd.GetFd() > f.Highest {
+				f.Highest = fd.GetFd()
+			}
+		}
+		files = append(files, f)
+	}
+
+	return files, nil
+}
+
+// checkOpenFiles returns a warning for each process with more open files
+// than the hard limit of open files on this host allows. CRIU raises its
+// soft limit up to the hard limit during restore.
+func checkOpenFiles(checkpointDirectory string) ([]string, error) {
+	var limit unix.Rlimit
+	if err := unix.Getrlimit(unix.RLIMIT_NOFILE, &limit); err != nil {
+		return nil, fmt.Errorf("unable to read the limit of open files: %w", err)
+	}
+	files, err := readOpenFiles(checkpointDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	var warnings []string
+	for _, f := range files {
+		if uint64(f.Highest) < limit.Max {
+			continue
+		}
+		warnings = append(warnings, fmt.Sprintf(
+			"process %d (%s) has %s open files (highest file descriptor %d), the limit of open files on this host is %s",
+			f.PID,
+			f.Comm,
+			formatCount(int64(f.Count)),
+			f.Highest,
+			formatCount(int64(limit.Max)),
+		))
+	}
+
+	return warnings, nil
+}
@@ -0,0 +1,400 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to provide machine-readable output of container checkpoints
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	metadata "github.com/checkpoint-restore/checkpointctl/lib"
+	"github.com/checkpoint-restore/go-criu/v6/crit/images"
+	spec "github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	outputTable  = "table"
+	outputJSON   = "json"
+	outputYAML   = "yaml"
+	outputLogfmt = "logfmt"
+	outputSVG    = "svg"
+	outputDOT    = "dot"
+)
+
+// outputFormats are the formats of the --output flag of each subcommand.
+// The first format is the default. Subcommands which print one record per
+// checkpoint can also print a logfmt line, show can also render a view of
+// the checkpoint as SVG or the process tree as a Graphviz graph, and
+// inspect only has a machine-readable representation.
+var outputFormats = map[string][]string{
+	"show":      {outputTable, outputJSON, outputYAML, outputLogfmt, outputSVG, outputDOT},
+	"validate":  {outputTable, outputJSON, outputYAML, outputLogfmt},
+	"verify":    {outputTable, outputJSON, outputYAML, outputLogfmt},
+	"stat":      {outputTable, outputJSON, outputYAML, outputLogfmt},
+	"inspect":   {outputJSON, outputYAML},
+	"preflight": {outputTable, outputJSON, outputYAML},
+	"compat":    {outputTable, outputJSON, outputYAML},
+	"diff":      {outputTable, outputJSON, outputYAML},
+	"drift":     {outputTable, outputJSON, outputYAML},
+}
+
+// addOutputFlag registers the --output flag of cmd with its formats
+func addOutputFlag(cmd *cobra.Command) {
+	formats := outputFormats[cmd.Name()]
+	cmd.Flags().StringP(
+		"output",
+		"o",
+		formats[0],
+		"Output format: "+strings.Join(formats, ", "),
+	)
+}
+
+// setOutputFormat sets outputFormat to the value of the --output flag of
+// cmd and returns an error if it is not one of the formats of cmd
+func setOutputFormat(cmd *cobra.Command) error {
+	format, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return err
+	}
+	formats := outputFormats[cmd.Name()]
+	for _, f := range formats {
+		if format == f {
+			outputFormat = format
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unsupported output format %q (supported: %s)", format, strings.Join(formats, ", "))
+}
+
+// printOutput prints the result of a subcommand in the machine-readable
+// format given with --output. Table output is rendered by each subcommand.
+func printOutput(v interface{}) error {
+	switch outputFormat {
+	case outputJSON:
+		return printJSON(v)
+	case outputYAML:
+		return printYAML(v)
+	case outputLogfmt:
+		if r, ok := v.(logfmtRecord); ok {
+			printLogfmt(r.logfmtFields())
+			return nil
+		}
+	}
+
+	return fmt.Errorf("output format %q has no machine-readable representation", outputFormat)
+}
+
+// memoryOutput is the memory of the checkpoint in relation to the
+// memory limit of the container. Without a limit only the size is set.
+type memoryOutput struct {
+	Size    int64   `json:"size"`
+	Limit   int64   `json:"limit,omitempty"`
+	Percent float64 `json:"percent,omitempty"`
+	// The size of compressed pages images, Size is the estimated
+	// uncompressed size
+	CompressedSize int64 `json:"compressedSize,omitempty"`
+}
+
+type mountOutput struct {
+	Destination string   `json:"destination"`
+	Type        string   `json:"type"`
+	Source      string   `json:"source"`
+	Options     []string `json:"options,omitempty"`
+	MountID     uint32   `json:"mountId,omitempty"`
+	Device      string   `json:"device,omitempty"`
+	SourceSize  *int64   `json:"sourceSize,omitempty"`
+}
+
+// checkpointOutput is the JSON representation of a container checkpoint
+type checkpointOutput struct {
+	Container      string `json:"container"`
+	Image          string `json:"image"`
+	ID             string `json:"id"`
+	ShortID        string `json:"shortId,omitempty"`
+	Runtime        string `json:"runtime"`
+	Created        string `json:"created"`
+	Engine         string `json:"engine"`
+	IP             string `json:"ip,omitempty"`
+	MAC            string `json:"mac,omitempty"`
+	Pod            string `json:"pod,omitempty"`
+	Namespace      string `json:"namespace,omitempty"`
+	StorageDriver  string `json:"storageDriver"`
+	Threads        int    `json:"threads,omitempty"`
+	CheckpointSize int64  `json:"checkpointSize"`
+	RootFsDiffSize int64  `json:"rootFsDiffSize,omitempty"`
+	// Mounts is a pointer, so that the key is only omitted without --mounts
+	Mounts         *[]mountOutput `json:"mounts,omitempty"`
+	MemoryTracking string         `json:"memoryTracking,omitempty"`
+	MemoryUsage    *memoryOutput  `json:"memoryUsage,omitempty"`
+	ImageSizes     *imageSizes    `json:"imageSizes,omitempty"`
+	Duration       string         `json:"duration,omitempty"`
+	CriuVersion    string         `json:"criuVersion,omitempty"`
+	// The statistics are only included with --print-stats
+	DumpStatistics    *images.DumpStatsEntry    `json:"dumpStatistics,omitempty"`
+	RestoreStatistics *images.RestoreStatsEntry `json:"restoreStatistics,omitempty"`
+	// Labels and annotations are copied unchanged for policy engines
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// validateOutputFormat checks the --output flag of the show subcommand.
+// Not all of its options have a machine-readable representation yet.
+func validateOutputFormat(cmd *cobra.Command) error {
+	if err := setOutputFormat(cmd); err != nil {
+		return err
+	}
+	if err := validateView(view); err != nil {
+		return err
+	}
+	if (outputFormat == outputLogfmt || outputFormat == outputSVG || outputFormat == outputDOT) && showMounts {
+		// A single line or a graph has no room for a list of mounts
+		return fmt.Errorf("--output %s does not support --mounts", outputFormat)
+	}
+	if (outputFormat == outputLogfmt || outputFormat == outputSVG || outputFormat == outputDOT) && (showLabels || showAnnots) {
+		return fmt.Errorf("--output %s does not support --labels and --annotations", outputFormat)
+	}
+	if (outputFormat == outputLogfmt || outputFormat == outputSVG || outputFormat == outputDOT) && printStats {
+		return fmt.Errorf("--output %s does not support --print-stats", outputFormat)
+	}
+	if outputFormat == outputDOT && !psTree {
+		return fmt.Errorf("--output %s requires --ps-tree", outputFormat)
+	}
+	if psTree && outputFormat != outputTable && outputFormat != outputDOT {
+		return fmt.Errorf("--output %s does not support --ps-tree", outputFormat)
+	}
+	if outputFormat != outputTable {
+		for _, o := range []struct {
+			name string
+			set  bool
+		}{
+			{"--all", showAll},
+			{"--stats-delta", statsDelta},
+			{"--env", showEnv},
+			{"--cmd", showCmd},
+			{"--timezone", showTZ},
+			{"--mac", macProfile},
+			{"--caps", showCaps},
+			{"--seccomp", showSeccomp},
+			{"--ulimits", showUlimits},
+			{"--lifecycle", lifecycle},
+			{"--validate-spec", validSpec},
+			{"--restore-annotations", restoreAnnots},
+			{"--compare-criu-version", cmpCriuVer},
+			{"--hostname", showHostname},
+			{"--tty", showTTY},
+			{"--net-files", netFiles},
+			{"--shared-memory", sharedMemory},
+			{"--ipc", showIPC},
+			{"--mem-pages", memPages},
+			{"--required-features", reqFeats},
+			{"--proc-ids", procIDs},
+			{"--dump-log", dumpLog},
+			{"--processes", listProcs},
+			{"--sched", showSched},
+			{"--timers", showTimers},
+			{"--ghost-files", ghostFiles},
+			{"--size", sizeBreakdown},
+		} {
+			if o.set {
+				return fmt.Errorf("--output %s does not support %s", outputFormat, o.name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// newCheckpointOutput returns the machine-readable representation of the
+// checkpoint with the sections selected on the command line
+func newCheckpointOutput(checkpointDirectory string, containerConfig *metadata.ContainerConfig, specDump *spec.Spec, ci *containerInfo) (*checkpointOutput, error) {
+	out := &checkpointOutput{
+		Container:     ci.Name,
+		Image:         containerConfig.RootfsImageName,
+		ID:            containerConfig.ID,
+		ShortID:       shortID(containerConfig.ID),
+		Runtime:       containerConfig.OCIRuntime,
+		Created:       ci.Created,
+		Engine:        ci.Engine,
+		IP:            ci.IP,
+		MAC:           ci.MAC,
+		Pod:           ci.Pod,
+		Namespace:     ci.Namespace,
+		StorageDriver: getStorageDriver(containerConfig),
+	}
+	if threads, ok := getThreadCount(checkpointDirectory); ok {
+		out.Threads = threads
+	}
+
+	size, err := getCheckpointSize(checkpointDirectory)
+	if err != nil && !bestEffort {
+		return nil, err
+	}
+	out.CheckpointSize = size
+	if fi, err := os.Lstat(filepath.Join(checkpointDirectory, metadata.RootFsDiffTar)); err == nil {
+		out.RootFsDiffSize = fi.Size()
+	}
+
+	if memTracking {
+		out.MemoryTracking, _ = getMemoryTracking(checkpointDirectory)
+	}
+
+	if memUsage {
+		pages, err := getPagesSizes(checkpointDirectory)
+		if err != nil {
+			return nil, err
+		}
+		size := pages.Uncompressed
+		out.MemoryUsage = &memoryOutput{Size: size}
+		if pages.Compressed {
+			out.MemoryUsage.CompressedSize = pages.Stored
+		}
+		if limit, ok := memoryLimit(specDump); ok {
+			out.MemoryUsage.Limit = limit
+			out.MemoryUsage.Percent = limitPercentage(size, limit)
+		}
+	}
+
+	if imgSizes {
+		if out.ImageSizes, err = getImageSizes(checkpointDirectory, !metadataOnly); err != nil {
+			return nil, err
+		}
+	}
+
+	if showDuration {
+		out.Duration = "unknown"
+		if d, ok := getCheckpointDuration(checkpointDirectory); ok {
+			out.Duration = d.String()
+		}
+	}
+
+	if showVersions {
+		out.CriuVersion = checkpointCriuVersion(checkpointDirectory)
+	}
+
+	if printStats {
+		if out.DumpStatistics, out.RestoreStatistics, err = readCriuStatistics(checkpointDirectory); err != nil {
+			return nil, err
+		}
+	}
+
+	if showLabels {
+		if out.Labels, err = getContainerLabels(containerConfig, specDump); err != nil {
+			return nil, err
+		}
+	}
+
+	if showAnnots {
+		out.Annotations = specDump.Annotations
+	}
+
+	if showMounts {
+		var mountpoints map[string]*images.MntEntry
+		if mountIDs {
+			if mountpoints, err = getMountpoints(checkpointDirectory); err != nil {
+				return nil, err
+			}
+		}
+		// Always emit an array, even if no mount matches the filter
+		mounts := []mountOutput{}
+		for _, m := range selectMounts(specDump.Mounts) {
+			mount := mountOutput{
+				Destination: m.Destination,
+				Type:        m.Type,
+				Source:      mountSource(m.Source),
+				Options:     m.Options,
+			}
+			if mp, ok := mountpoints[m.Destination]; ok {
+				mount.MountID = mp.GetMntId()
+				mount.Device = formatKernelDevice(mp.GetRootDev())
+			}
+			if mountSizes {
+				if size, ok := mountSourceSize(m.Source); ok {
+					mount.SourceSize = &size
+				}
+			}
+			mounts = append(mounts, mount)
+		}
+		out.Mounts = &mounts
+	}
+
+	return out, nil
+}
+
+func showContainerCheckpointOutput(checkpointDirectory string, containerConfig *metadata.ContainerConfig, specDump *spec.Spec, ci *containerInfo) error {
+	out, err := newCheckpointOutput(checkpointDirectory, containerConfig, specDump, ci)
+	if err != nil {
+		return err
+	}
+	if err := printOutput(out); err != nil {
+		return err
+	}
+
+	if checkMounts {
+		checkMountSources(specDump)
+	}
+	if checkProfile {
+		checkAppArmorProfile(specDump)
+	}
+
+	return checkThresholds(checkpointDirectory, out.CheckpointSize)
+}
+
+func printJSON(v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling JSON: %w", err)
+	}
+	fmt.Println(string(data))
+
+	return nil
+}
+
+func printYAML(v interface{}) error {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("error marshalling YAML: %w", err)
+	}
+	fmt.Print(string(data))
+
+	return nil
+}
+
+// logfmtFields returns the checkpoint as key=value pairs. Unlike in the
+// JSON representation sizes are human-readable, unless --raw is given.
+func (o checkpointOutput) logfmtFields() []logfmtField {
+	var threads, rootFsDiffSize string
+	if o.Threads > 0 {
+		threads = strconv.Itoa(o.Threads)
+	}
+	if o.RootFsDiffSize > 0 {
+		rootFsDiffSize = formatSize(o.RootFsDiffSize)
+	}
+
+	return []logfmtField{
+		{"container", o.Container},
+		{"id", o.ID},
+		{"image", o.Image},
+		{"engine", o.Engine},
+		{"runtime", o.Runtime},
+		{"created", o.Created},
+		{"ip", o.IP},
+		{"mac", o.MAC},
+		{"pod", o.Pod},
+		{"namespace", o.Namespace},
+		{"storage_driver", o.StorageDriver},
+		{"threads", threads},
+		{"size", formatSize(o.CheckpointSize)},
+		{"rootfs_diff_size", rootFsDiffSize},
+		{"memory_tracking", o.MemoryTracking},
+		{"duration", o.Duration},
+		{"criu_version", o.CriuVersion},
+	}
+}
@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to map container checkpoints to Kubernetes pods
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// podMapping is the pod context of a single container as
+// provided by the user via --pod-map
+type podMapping struct {
+	Pod       string `json:"pod"`
+	Namespace string `json:"namespace"`
+}
+
+// podMap maps container IDs to pods. It is loaded from the --pod-map file.
+var podMap map[string]podMapping
+
+// loadPodMap reads a JSON or YAML file mapping container IDs to pods:
+//
+//	7eb9680287f1:
+//	  pod: counters
+//	  namespace: default
+func loadPodMap(file string) (map[string]podMapping, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]podMapping)
+	if err := yaml.Unmarshal(content, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse pod mapping %s: %w", file, err)
+	}
+
+	return m, nil
+}
+
+// applyPodMapping fills missing pod information of ci from the pod map.
+// Keys of the map can either be the full container ID or a prefix of it.
+// The full container ID takes precedence, followed by the longest prefix.
+func applyPodMapping(ci *containerInfo, id string) {
+	if id == "" || (ci.Pod != "" && ci.Namespace != "") {
+		return
+	}
+	mapping, ok := podMap[id]
+	if !ok {
+		longest := ""
+		for key, m := range podMap {
+			if key != "" && len(key) > len(longest) && strings.HasPrefix(id, key) {
+				mapping, ok, longest = m, true, key
+			}
+		}
+	}
+	if !ok {
+		return
+	}
+	if ci.Pod == "" {
+		ci.Pod = mapping.Pod
+	}
+	if ci.Namespace == "" {
+		ci.Namespace = mapping.Namespace
+	}
+}
@@ -0,0 +1,243 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to verify the external dependencies of container
+// checkpoints on the restore host
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	metadata "github.com/checkpoint-restore/checkpointctl/lib"
+	"github.com/checkpoint-restore/go-criu/v6/crit/images"
+	"github.com/olekukonko/tablewriter"
+	spec "github.com/opencontainers/runtime-spec/specs-go"
+	"golang.org/x/sys/unix"
+)
+
+// externalReference is something outside of the checkpoint which has to
+// exist on the host to restore the checkpoint
+type externalReference struct {
+	Type      string      `json:"type"`
+	Reference string      `json:"reference"`
+	Status    checkStatus `json:"status"`
+	Details   string      `json:"details,omitempty"`
+}
+
+// preflightOutput is the machine-readable result of the preflight subcommand
+type preflightOutput struct {
+	Input      string              `json:"input"`
+	Ready      bool                `json:"ready"`
+	References []externalReference `json:"references"`
+}
+
+// missingReferences returns the number of references which failed the verification
+func (o *preflightOutput) missingReferences() int {
+	missing := 0
+	for _, r := range o.References {
+		if r.Status == checkFailed {
+			missing++
+		}
+	}
+
+	return missing
+}
+
+func checkBindMounts(specDump *spec.Spec) []externalReference {
+	var refs []externalReference
+	for _, m := range specDump.Mounts {
+		if !isExternalMount(m) {
+			continue
+		}
+		ref := externalReference{
+			Type:      "Bind mount",
+			Reference: m.Source,
+			Status:    checkPassed,
+			Details:   "mounted at " + m.Destination,
+		}
+		if _, err := os.Stat(m.Source); err != nil {
+			ref.Status = checkFailed
+			ref.Details = fmt.Sprintf("mounted at %s: %s", m.Destination, statError(err))
+		}
+		refs = append(refs, ref)
+	}
+
+	return refs
+}
+
+// checkExternalUnixSockets verifies the sockets outside of the container
+// which processes were connected to during checkpointing
+func checkExternalUnixSockets(checkpointDirectory string) ([]externalReference, error) {
+	if !criuImageExists(checkpointDirectory, unixSkImg) {
+		return nil, nil
+	}
+	img, err := readCriuImage(checkpointDirectory, unixSkImg)
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []externalReference
+	for _, entry := range img.Entries {
+		sk, ok := entry.Message.(*images.UnixSkEntry)
+		if !ok {
+			return nil, fmt.Errorf("failed to type assert %s", unixSkImg)
+		}
+		if sk.GetUflags()&unixSkExtern == 0 {
+			continue
+		}
+		name := string(sk.GetName())
+		if strings.HasPrefix(name, "\x00") {
+			// Abstract sockets are bound to a network namespace
+			refs = append(refs, externalReference{
+				Type:      "Unix socket",
+				Reference: "@" + strings.TrimPrefix(name, "\x00"),
+				Status:    checkSkipped,
+				Details:   "abstract socket, cannot be verified",
+			})
+			continue
+		}
+		if !filepath.IsAbs(name) && sk.GetNameDir() != "" {
+			name = filepath.Join(sk.GetNameDir(), name)
+		}
+		ref := externalReference{
+			Type:      "Unix socket",
+			Reference: name,
+			Status:    checkPassed,
+		}
+		fi, err := os.Stat(name)
+		switch {
+		case err != nil:
+			ref.Status = checkFailed
+			ref.Details = statError(err)
+		case fi.Mode()&os.ModeSocket == 0:
+			ref.Status = checkFailed
+			ref.Details = "not a socket"
+		}
+		refs = append(refs, ref)
+	}
+
+	return refs, nil
+}
+
+// deviceTypes are the names of the device types of the OCI runtime spec
+var deviceTypes = map[string]string{
+	"b": "block",
+	"c": "char",
+	"u": "char",
+	"p": "fifo",
+}
+
+func deviceDescription(typ string, major, minor int64) string {
+	kind, ok := deviceTypes[typ]
+	if !ok {
+		kind = typ
+	}
+	if typ == "p" {
+		return kind
+	}
+
+	return fmt.Sprintf("%s %d:%d", kind, major, minor)
+}
+
+// checkDevices verifies that the device nodes of the container exist on
+// the host with the same device numbers
+func checkDevices(specDump *spec.Spec) []externalReference {
+	if specDump.Linux == nil {
+		return nil
+	}
+
+	var refs []externalReference
+	for _, d := range specDump.Linux.Devices {
+		expected := deviceDescription(d.Type, d.Major, d.Minor)
+		ref := externalReference{
+			Type:      "Device",
+			Reference: d.Path,
+			Status:    checkPassed,
+			Details:   expected,
+		}
+		var st unix.Stat_t
+		if err := unix.Stat(d.Path, &st); err != nil {
+			ref.Status = checkFailed
+			ref.Details = fmt.Sprintf("%s: %s", expected, statError(err))
+			refs = append(refs, ref)
+			continue
+		}
+		var typ string
+		switch st.Mode & unix.S_IFMT {
+		case unix.S_IFCHR:
+			typ = "c"
+		case unix.S_IFBLK:
+			typ = "b"
+		case unix.S_IFIFO:
+			typ = "p"
+		default:
+			ref.Status = checkFailed
+			ref.Details = fmt.Sprintf("%s: not a device", expected)
+			refs = append(refs, ref)
+			continue
+		}
+		found := deviceDescription(typ, int64(unix.Major(uint64(st.Rdev))), int64(unix.Minor(uint64(st.Rdev))))
+		if found != expected {
+			ref.Status = checkFailed
+			ref.Details = fmt.Sprintf("%s: found %s", expected, found)
+		}
+		refs = append(refs, ref)
+	}
+
+	return refs
+}
+
+func statError(err error) string {
+	if errors.Is(err, os.ErrNotExist) {
+		return "does not exist"
+	}
+
+	return err.Error()
+}
+
+func getExternalReferences(input, checkpointDirectory string) (*preflightOutput, error) {
+	specDump, _, err := metadata.ReadContainerCheckpointSpecDump(checkpointDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &preflightOutput{Input: input, References: []externalReference{}}
+	out.References = append(out.References, checkBindMounts(specDump)...)
+	if err := checkImageVersion(checkpointDirectory); err != nil {
+		return nil, err
+	}
+	sockets, err := checkExternalUnixSockets(checkpointDirectory)
+	if err != nil {
+		return nil, err
+	}
+	out.References = append(out.References, sockets...)
+	out.References = append(out.References, checkDevices(specDump)...)
+	out.Ready = out.missingReferences() == 0
+
+	return out, nil
+}
+
+func showPreflight(out *preflightOutput) {
+	fmt.Printf("\nVerifying external references of container checkpoint %s\n\n", out.Input)
+	if len(out.References) == 0 {
+		fmt.Println("No external references found")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{
+		"Type",
+		"Reference",
+		"Result",
+		"Details",
+	})
+	for _, r := range out.References {
+		table.Append([]string{r.Type, r.Reference, string(r.Status), r.Details})
+	}
+	table.Render()
+}
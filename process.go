@@ -0,0 +1,370 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to display the processes captured in container checkpoints
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	metadata "github.com/checkpoint-restore/checkpointctl/lib"
+	"github.com/checkpoint-restore/go-criu/v6/crit/images"
+	"github.com/olekukonko/tablewriter"
+)
+
+const (
+	sortByPID    = "pid"
+	sortByMemory = "memory"
+)
+
+var processSortOrders = []string{sortByPID, sortByMemory}
+
+// procFilterRegexp is the compiled expression of --proc-filter
+var procFilterRegexp *regexp.Regexp
+
+// taskStates are the names of the task states in the core images
+// (TASK_ALIVE, TASK_DEAD, TASK_STOPPED, TASK_HELPER, TASK_THREAD, TASK_ZOMBIE)
+var taskStates = map[uint32]string{
+	1: "running",
+	2: "dead",
+	3: "stopped",
+	4: "helper",
+	5: "thread",
+	6: "zombie",
+}
+
+// schedPolicies are the names of the Linux scheduling policies
+var schedPolicies = map[uint32]string{
+	0: "SCHED_OTHER",
+	1: "SCHED_FIFO",
+	2: "SCHED_RR",
+	3: "SCHED_BATCH",
+	5: "SCHED_IDLE",
+	6: "SCHED_DEADLINE",
+}
+
+// Flag of the scheduling policy which is not inherited by child processes
+const schedResetOnFork = 0x40000000
+
+// processInfo combines the pstree and core image data of a single process
+type processInfo struct {
+	PID     uint32
+	PPID    uint32
+	PGID    uint32
+	SID     uint32
+	Threads []uint32
+	Comm    string
+	Core    *images.CoreEntry
+}
+
+// readProcesses returns all processes of the checkpoint in pstree order
+func readProcesses(checkpointDirectory string) ([]*processInfo, error) {
+	if !criuImageExists(checkpointDirectory, pstreeImg) {
+		return nil, fmt.Errorf("%s %w", pstreeImg, metadata.ErrSectionUnavailable)
+	}
+	img, err := readCriuImage(checkpointDirectory, pstreeImg)
+	if err != nil {
+		return nil, err
+	}
+
+	var processes []*processInfo
+	for _, entry := range img.Entries {
+		pstree, ok := entry.Message.(*images.PstreeEntry)
+		if !ok {
+			return nil, fmt.Errorf("failed to type assert %s", pstreeImg)
+		}
+		core, err := readCore(checkpointDirectory, pstree.GetPid())
+		if err != nil {
+			return nil, err
+		}
+		processes = append(processes, &processInfo{
+			PID:     pstree.GetPid(),
+			PPID:    pstree.GetPpid(),
+			PGID:    pstree.GetPgid(),
+			SID:     pstree.GetSid(),
+			Threads: pstree.GetThreads(),
+			Comm:    core.GetTc().GetComm(),
+			Core:    core,
+		})
+	}
+
+	return processes, nil
+}
+
+// countThreads returns the number of threads of all processes of the
+// checkpoint. CRIU writes a core image for each thread listed in pstree.img.
+func countThreads(checkpointDirectory string) (int, error) {
+	img, err := readCriuImage(checkpointDirectory, pstreeImg)
+	if err != nil {
+		return 0, err
+	}
+	threads := 0
+	for _, entry := range img.Entries {
+		pstree, ok := entry.Message.(*images.PstreeEntry)
+		if !ok {
+			return 0, fmt.Errorf("failed to type assert %s", pstreeImg)
+		}
+		threads += len(pstree.GetThreads())
+	}
+
+	return threads, nil
+}
+
+// readCore decodes the core image of a process or thread
+func readCore(checkpointDirectory string, pid uint32) (*images.CoreEntry, error) {
+	name := fmt.Sprintf("core-%d.img", pid)
+	img, err := readCriuImage(checkpointDirectory, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(img.Entries) == 0 {
+		return nil, fmt.Errorf("%s does not contain any entries", name)
+	}
+	core, ok := img.Entries[0].Message.(*images.CoreEntry)
+	if !ok {
+		return nil, fmt.Errorf("failed to type assert %s", name)
+	}
+
+	return core, nil
+}
+
+// readFs decodes the fs image with the umask, cwd and root of a process
+func readFs(checkpointDirectory string, pid uint32) (*images.FsEntry, error) {
+	name := fmt.Sprintf("fs-%d.img", pid)
+	img, err := readCriuImage(checkpointDirectory, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(img.Entries) == 0 {
+		return nil, fmt.Errorf("%s does not contain any entries", name)
+	}
+	fs, ok := img.Entries[0].Message.(*images.FsEntry)
+	if !ok {
+		return nil, fmt.Errorf("failed to type assert %s", name)
+	}
+
+	return fs, nil
+}
+
+// pidSelected returns true if no --pid filter was given
+// or if pid is one of the selected processes
+func pidSelected(pid uint32) bool {
+	if len(pids) == 0 {
+		return true
+	}
+	for _, p := range pids {
+		if uint32(p) == pid {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setupProcessFilter compiles the expression of --proc-filter. A plain
+// string matches all commands which contain it. A value which is not a
+// valid regular expression, like c++, is matched as a plain string.
+func setupProcessFilter() {
+	if procFilter == "" {
+		return
+	}
+	re, err := regexp.Compile(procFilter)
+	if err != nil {
+		re = regexp.MustCompile(regexp.QuoteMeta(procFilter))
+	}
+	procFilterRegexp = re
+}
+
+// commandSelected returns true if no --proc-filter was given
+// or if the command of the process matches it
+func commandSelected(p *processInfo) bool {
+	return procFilterRegexp == nil || procFilterRegexp.MatchString(p.Comm)
+}
+
+// processSelected returns true if the process passes --pid and --proc-filter
+func processSelected(p *processInfo) bool {
+	return pidSelected(p.PID) && commandSelected(p)
+}
+
+func showProcessIDs(checkpointDirectory string) error {
+	processes, err := readProcesses(checkpointDirectory)
+	if err != nil {
+		return fmt.Errorf("unable to display process IDs: %w", err)
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	setTableHeader(table, []string{
+		"PID",
+		"PGID",
+		"SID",
+		"Umask",
+		"Command",
+	})
+	for _, p := range processes {
+		if !processSelected(p) {
+			continue
+		}
+		umask := "-"
+		if fs, err := readFs(checkpointDirectory, p.PID); err == nil && fs.Umask != nil {
+			umask = fmt.Sprintf("%04o", fs.GetUmask())
+		}
+		table.Append([]string{
+			fmt.Sprintf("%d", p.PID),
+			fmt.Sprintf("%d", p.PGID),
+			fmt.Sprintf("%d", p.SID),
+			umask,
+			p.Comm,
+		})
+	}
+	printCaption("Process IDs")
+	table.Render()
+
+	return nil
+}
+
+func validateProcessSortOrder(order string) error {
+	for _, o := range processSortOrders {
+		if order == o {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unsupported sort order %q (supported: %s)", order, strings.Join(processSortOrders, ", "))
+}
+
+func taskState(core *images.CoreEntry) string {
+	state := core.GetTc().GetTaskState()
+	if name, ok := taskStates[state]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("unknown (%d)", state)
+}
+
+// mappedMemory returns the size of all memory mappings of a process.
+// It is false if the checkpoint contains no mm image for the process.
+func mappedMemory(checkpointDirectory string, pid uint32) (uint64, bool) {
+	if !criuImageExists(checkpointDirectory, fmt.Sprintf("mm-%d.img", pid)) {
+		return 0, false
+	}
+	mm, err := readMm(checkpointDirectory, pid)
+	if err != nil {
+		return 0, false
+	}
+	var size uint64
+	for _, vma := range mm.GetVmas() {
+		size += vma.GetEnd() - vma.GetStart()
+	}
+
+	return size, true
+}
+
+// showProcesses prints a flat list of all processes of the checkpoint,
+// which is easier to search with grep than a process tree
+func showProcesses(checkpointDirectory, order string) error {
+	processes, err := readProcesses(checkpointDirectory)
+	if err != nil {
+		return fmt.Errorf("unable to display processes: %w", err)
+	}
+
+	memory := make(map[uint32]uint64)
+	for _, p := range processes {
+		if size, ok := mappedMemory(checkpointDirectory, p.PID); ok {
+			memory[p.PID] = size
+		}
+	}
+
+	sort.SliceStable(processes, func(i, j int) bool {
+		if order == sortByMemory && memory[processes[i].PID] != memory[processes[j].PID] {
+			return memory[processes[i].PID] > memory[processes[j].PID]
+		}
+		return processes[i].PID < processes[j].PID
+	})
+
+	table := tablewriter.NewWriter(os.Stdout)
+	setTableHeader(table, []string{
+		"PID",
+		"PPID",
+		"Command",
+		"Threads",
+		"State",
+		"Memory",
+	})
+	for _, p := range processes {
+		if !processSelected(p) {
+			continue
+		}
+		size := "-"
+		if m, ok := memory[p.PID]; ok {
+			size = formatSize(int64(m))
+		}
+		table.Append([]string{
+			fmt.Sprintf("%d", p.PID),
+			fmt.Sprintf("%d", p.PPID),
+			p.Comm,
+			fmt.Sprintf("%d", len(p.Threads)),
+			taskState(p.Core),
+			size,
+		})
+	}
+	printCaption("Processes")
+	table.Render()
+
+	return nil
+}
+
+func schedPolicy(policy uint32) string {
+	name, ok := schedPolicies[policy&^schedResetOnFork]
+	if !ok {
+		name = fmt.Sprintf("unknown (%d)", policy&^schedResetOnFork)
+	}
+	if policy&schedResetOnFork != 0 {
+		name += " (reset on fork)"
+	}
+
+	return name
+}
+
+// showScheduling prints the scheduling settings of the main thread of
+// each process. The default policy and nice value are displayed as well
+// to show that they have been captured.
+func showScheduling(checkpointDirectory string) error {
+	processes, err := readProcesses(checkpointDirectory)
+	if err != nil {
+		return fmt.Errorf("unable to display scheduling settings: %w", err)
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	setTableHeader(table, []string{
+		"PID",
+		"Command",
+		"Policy",
+		"Nice",
+		"Priority",
+	})
+	for _, p := range processes {
+		if !processSelected(p) {
+			continue
+		}
+		tc := p.Core.GetThreadCore()
+		if tc == nil {
+			table.Append([]string{fmt.Sprintf("%d", p.PID), p.Comm, "-", "-", "-"})
+			continue
+		}
+		table.Append([]string{
+			fmt.Sprintf("%d", p.PID),
+			p.Comm,
+			schedPolicy(tc.GetSchedPolicy()),
+			fmt.Sprintf("%d", tc.GetSchedNice()),
+			fmt.Sprintf("%d", tc.GetSchedPrio()),
+		})
+	}
+	printCaption("Scheduling")
+	table.Render()
+
+	return nil
+}
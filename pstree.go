@@ -0,0 +1,194 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to display the process tree of container checkpoints
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// processNode is a process with its child processes
+type processNode struct {
+	process  *processInfo
+	children []*processNode
+}
+
+// buildProcessTree returns the process trees of the checkpoint in pstree order
+func buildProcessTree(checkpointDirectory string) ([]*processNode, error) {
+	processes, err := readProcesses(checkpointDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[uint32]*processNode)
+	for _, p := range processes {
+		nodes[p.PID] = &processNode{process: p}
+	}
+	var roots []*processNode
+	for _, p := range processes {
+		if parent, ok := nodes[p.PPID]; ok && p.PPID != p.PID {
+			parent.children = append(parent.children, nodes[p.PID])
+			continue
+		}
+		roots = append(roots, nodes[p.PID])
+	}
+
+	return roots, nil
+}
+
+// readProcessArgs returns the command line of a process, which is read
+// from the memory of the process like /proc/<pid>/cmdline
+func readProcessArgs(checkpointDirectory string, pid uint32) ([]string, error) {
+	mm, err := readMm(checkpointDirectory, pid)
+	if err != nil {
+		return nil, err
+	}
+	data, err := readProcessMemory(checkpointDirectory, pid, mm.GetMmArgStart(), mm.GetMmArgEnd())
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.Split(strings.TrimRight(string(data), "\x00"), "\x00"), nil
+}
+
+// filterProcessTree returns the processes matching --proc-filter. Their
+// ancestors are kept to show where the processes are in the tree.
+func filterProcessTree(nodes []*processNode) []*processNode {
+	if procFilterRegexp == nil {
+		return nodes
+	}
+	var filtered []*processNode
+	for _, n := range nodes {
+		children := filterProcessTree(n.children)
+		if len(children) > 0 || commandSelected(n.process) {
+			filtered = append(filtered, &processNode{process: n.process, children: children})
+		}
+	}
+
+	return filtered
+}
+
+func processLabel(p *processInfo) string {
+	return fmt.Sprintf("%d %s (%s)", p.PID, p.Comm, plural(len(p.Threads), "thread"))
+}
+
+// processCommandLine returns the command line of a process for the process
+// tree with --ps-tree-cmd. It is empty if the memory of the process which
+// contains the command line is not part of the checkpoint.
+func processCommandLine(checkpointDirectory string, p *processInfo) string {
+	if !psTreeCmd {
+		return ""
+	}
+	args, err := readProcessArgs(checkpointDirectory, p.PID)
+	if err != nil {
+		return ""
+	}
+	for i, a := range args {
+		args[i] = displayValue(a)
+	}
+
+	return strings.Join(args, " ")
+}
+
+func processTreeLabel(checkpointDirectory string, p *processInfo) string {
+	if cmdline := processCommandLine(checkpointDirectory, p); cmdline != "" {
+		return fmt.Sprintf("%s [%s]", processLabel(p), cmdline)
+	}
+
+	return processLabel(p)
+}
+
+func writeProcessNode(w io.Writer, checkpointDirectory string, n *processNode, prefix string, last bool) {
+	branch, indent := "├── ", "│   "
+	if last {
+		branch, indent = "└── ", "    "
+	}
+	fmt.Fprintf(w, "%s%s%s\n", prefix, branch, processTreeLabel(checkpointDirectory, n.process))
+	for i, c := range n.children {
+		writeProcessNode(w, checkpointDirectory, c, prefix+indent, i == len(n.children)-1)
+	}
+}
+
+func showProcessTree(checkpointDirectory string) error {
+	roots, err := buildProcessTree(checkpointDirectory)
+	if err != nil {
+		return fmt.Errorf("unable to display process tree: %w", err)
+	}
+
+	printCaption("Process tree")
+	roots = filterProcessTree(roots)
+	if len(roots) == 0 {
+		fmt.Println("No processes match --proc-filter")
+		return nil
+	}
+	for _, r := range roots {
+		fmt.Println(processTreeLabel(checkpointDirectory, r.process))
+		for i, c := range r.children {
+			writeProcessNode(os.Stdout, checkpointDirectory, c, "", i == len(r.children)-1)
+		}
+	}
+
+	return nil
+}
+
+// dotQuote returns s as a quoted Graphviz ID
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// writeProcessTreeDOT writes the process trees as a Graphviz graph with
+// an edge from every parent process to each of its children
+func writeProcessTreeDOT(w io.Writer, checkpointDirectory, title string, roots []*processNode) {
+	fmt.Fprintln(w, "digraph \"process tree\" {")
+	fmt.Fprintf(w, "\tlabel=%s;\n", dotQuote(title))
+	fmt.Fprintln(w, "\tlabelloc=t;")
+	fmt.Fprintln(w, "\tnode [shape=box];")
+
+	var nodes func(n *processNode)
+	nodes = func(n *processNode) {
+		label := fmt.Sprintf("%d\n%s\n%s", n.process.PID, n.process.Comm, plural(len(n.process.Threads), "thread"))
+		if cmdline := processCommandLine(checkpointDirectory, n.process); cmdline != "" {
+			label += "\n" + cmdline
+		}
+		fmt.Fprintf(w, "\t%d [label=%s];\n", n.process.PID, dotQuote(label))
+		for _, c := range n.children {
+			nodes(c)
+		}
+	}
+	var edges func(n *processNode)
+	edges = func(n *processNode) {
+		for _, c := range n.children {
+			fmt.Fprintf(w, "\t%d -> %d;\n", n.process.PID, c.process.PID)
+			edges(c)
+		}
+	}
+	for _, r := range roots {
+		nodes(r)
+	}
+	for _, r := range roots {
+		edges(r)
+	}
+	fmt.Fprintln(w, "}")
+}
+
+func showProcessTreeDOT(checkpointDirectory string, ci *containerInfo) error {
+	if err := checkImageVersion(checkpointDirectory); err != nil {
+		return err
+	}
+	roots, err := buildProcessTree(checkpointDirectory)
+	if err != nil {
+		return fmt.Errorf("unable to display process tree: %w", err)
+	}
+
+	title := "Process tree"
+	if ci.Name != "" {
+		title += " of container " + ci.Name
+	}
+	writeProcessTreeDOT(os.Stdout, checkpointDirectory, title, filterProcessTree(roots))
+
+	return nil
+}
@@ -0,0 +1,61 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to read container checkpoints from OCI images in a registry
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/containers/storage/pkg/archive"
+	"github.com/containers/storage/pkg/unshare"
+	"github.com/google/go-containerregistry/pkg/authn"
+	crname "github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+// isImageReference returns true if input is not a local file but the
+// reference of an image in a registry like registry.example.com/chk:tag.
+// The registry has to be part of the reference to not mistake the name
+// of a missing local file for an image on Docker Hub.
+func isImageReference(input string) bool {
+	if _, err := os.Stat(input); err == nil {
+		return false
+	}
+	if _, err := os.Stat(input + ".001"); err == nil {
+		// Split archives are specified by the name without part suffix
+		return false
+	}
+	registry, _, found := strings.Cut(input, "/")
+	if !found || (!strings.ContainsAny(registry, ".:") && registry != "localhost") {
+		return false
+	}
+	_, err := crname.ParseReference(input)
+
+	return err == nil
+}
+
+// pullCheckpointImage unpacks the file system of the checkpoint image
+// reference into dir. Credentials are taken from the Docker config.
+func pullCheckpointImage(reference, dir string) error {
+	ref, err := crname.ParseReference(reference)
+	if err != nil {
+		return err
+	}
+	img, err := remote.Image(ref, remote.WithAuthFromKeychain(authn.DefaultKeychain))
+	if err != nil {
+		return fmt.Errorf("failed to pull %s: %w", reference, err)
+	}
+
+	// The checkpoint archive is added to the image as one layer
+	// which contains the unpacked checkpoint.
+	layers := mutate.Extract(img)
+	defer layers.Close()
+
+	return archive.Untar(layers, dir, &archive.TarOptions{
+		InUserNS: unshare.IsRootless(),
+	})
+}
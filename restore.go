@@ -0,0 +1,123 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to suggest commands to restore container checkpoints
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	metadata "github.com/checkpoint-restore/checkpointctl/lib"
+)
+
+// Options of 'podman container restore' for CRIU features which
+// have to be enabled again during restore
+var podmanRestoreOptions = map[string]bool{
+	"--tcp-established": true,
+	"--file-locks":      true,
+}
+
+// restoreSuggestion is a suggested command to restore a checkpoint together
+// with everything which has to be prepared manually
+type restoreSuggestion struct {
+	Engine  string
+	Command string
+	Notes   []string
+}
+
+func getRestoreHint(input, checkpointDirectory string) (*restoreSuggestion, error) {
+	containerConfig, _, err := metadata.ReadContainerCheckpointConfigDump(checkpointDirectory)
+	if err != nil {
+		return nil, err
+	}
+	specDump, _, err := metadata.ReadContainerCheckpointSpecDump(checkpointDirectory)
+	if err != nil {
+		return nil, err
+	}
+	ci, err := getContainerInfo(checkpointDirectory, containerConfig, specDump)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkImageVersion(checkpointDirectory); err != nil {
+		return nil, err
+	}
+	features, err := getRequiredFeatures(checkpointDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	hint := &restoreSuggestion{Engine: ci.Engine}
+	var args []string
+	switch ci.Engine {
+	case "Podman":
+		args = []string{"podman", "container", "restore", "--import", input}
+		for _, f := range features {
+			if podmanRestoreOptions[f.Option] {
+				args = append(args, f.Option)
+				continue
+			}
+			hint.Notes = append(hint.Notes, fmt.Sprintf(
+				"the CRIU option %s (%s) has to be set in the CRIU configuration file of the OCI runtime",
+				f.Option, f.Feature,
+			))
+		}
+	case "CRI-O", "containerd":
+		args = []string{"crictl", "create", "<POD_ID>", "container-config.json", "pod-config.json"}
+		hint.Notes = append(hint.Notes, fmt.Sprintf(
+			"set the image of the container in container-config.json to %s", input,
+		))
+		for _, f := range features {
+			hint.Notes = append(hint.Notes, fmt.Sprintf(
+				"the CRIU option %s (%s) has to be set in the CRIU configuration file of the OCI runtime",
+				f.Option, f.Feature,
+			))
+		}
+	default:
+		// Without a known container engine only CRIU itself can be used
+		args = []string{"criu", "restore", "--images-dir", imagesDirectoryName(checkpointDirectory)}
+		for _, f := range features {
+			args = append(args, f.Option)
+		}
+		hint.Notes = append(hint.Notes, fmt.Sprintf(
+			"unpack %s and run the command from the unpacked directory", input,
+		))
+	}
+	hint.Command = strings.Join(args, " ")
+
+	for _, m := range specDump.Mounts {
+		if isExternalMount(m) {
+			hint.Notes = append(hint.Notes, fmt.Sprintf(
+				"bind mount source %s (mounted at %s) has to exist on the restore host", m.Source, m.Destination,
+			))
+		}
+	}
+	for _, f := range features {
+		if f.Option == "--tcp-established" && ci.IP != "" {
+			hint.Notes = append(hint.Notes, fmt.Sprintf(
+				"established TCP connections can only be restored with the original IP address %s", ci.IP,
+			))
+		}
+	}
+
+	return hint, nil
+}
+
+func showRestoreHint(input, checkpointDirectory string) error {
+	hint, err := getRestoreHint(input, checkpointDirectory)
+	if err != nil {
+		return fmt.Errorf("unable to suggest a restore command: %w", err)
+	}
+
+	fmt.Printf("\nSuggested restore command for %s checkpoint %s\n\n", hint.Engine, input)
+	fmt.Println(hint.Command)
+	if len(hint.Notes) > 0 {
+		fmt.Println("\nNotes")
+		for _, n := range hint.Notes {
+			fmt.Printf("- %s\n", n)
+		}
+	}
+
+	return nil
+}
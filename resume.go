@@ -0,0 +1,107 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to continue the display of many checkpoints after an
+// interruption
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// resumeRecord is a line of the state file of --resume-from
+type resumeRecord struct {
+	Path string `json:"path"`
+}
+
+// resumeState records the checkpoints which have been processed. The state
+// file contains one JSON object per line and is appended to after each
+// checkpoint, so that it is complete up to the last checkpoint if the run
+// is interrupted.
+type resumeState struct {
+	file      *os.File
+	processed map[string]bool
+}
+
+// openResumeState reads the checkpoints processed by earlier runs from the
+// state file at path, which is created if it does not exist
+func openResumeState(path string) (*resumeState, error) {
+	s := &resumeState{processed: make(map[string]bool)}
+
+	content, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	lines := bytes.Split(content, []byte("\n"))
+	incomplete := 0
+	for i, line := range lines {
+		if len(line) == 0 {
+			continue
+		}
+		var r resumeRecord
+		if err := json.Unmarshal(line, &r); err != nil {
+			// A run interrupted while recording a checkpoint leaves an
+			// incomplete last line, the checkpoint is processed again
+			if i == len(lines)-1 {
+				incomplete = len(line)
+				break
+			}
+			return nil, fmt.Errorf("failed to read line %d of %s: %w", i+1, path, err)
+		}
+		s.processed[r.Path] = true
+	}
+	if incomplete > 0 {
+		if err := os.Truncate(path, int64(len(content)-incomplete)); err != nil {
+			return nil, fmt.Errorf("failed to remove the incomplete last line of %s: %w", path, err)
+		}
+	}
+
+	if s.file, err = os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644); err != nil {
+		return nil, err
+	}
+	// A complete last line might lack the newline, which the next record
+	// must not continue
+	if incomplete == 0 && len(content) > 0 && content[len(content)-1] != '\n' {
+		if _, err := s.file.Write([]byte("\n")); err != nil {
+			s.file.Close()
+			return nil, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+
+	return s, nil
+}
+
+// pending returns the inputs which have not been processed yet
+func (s *resumeState) pending(inputs []string) []string {
+	var pending []string
+	for _, input := range inputs {
+		if s.processed[checkpointKey(input)] {
+			fmt.Fprintf(os.Stderr, "Note: skipping %s, which has already been processed\n", input)
+			continue
+		}
+		pending = append(pending, input)
+	}
+
+	return pending
+}
+
+// record adds a processed checkpoint to the state file
+func (s *resumeState) record(input string) error {
+	line, err := json.Marshal(resumeRecord{Path: checkpointKey(input)})
+	if err != nil {
+		return err
+	}
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to record %s in %s: %w", input, s.file.Name(), err)
+	}
+
+	return s.file.Sync()
+}
+
+func (s *resumeState) Close() error {
+	return s.file.Close()
+}
@@ -0,0 +1,175 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to display pod sandbox checkpoints created by CRI-O
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	metadata "github.com/checkpoint-restore/checkpointctl/lib"
+	"github.com/olekukonko/tablewriter"
+)
+
+var (
+	namespaceModes = map[int32]string{
+		0: "pod",
+		1: "container",
+		2: "node",
+		3: "target",
+	}
+	portProtocols = map[int32]string{
+		0: "TCP",
+		1: "UDP",
+		2: "SCTP",
+	}
+)
+
+// isSandboxCheckpoint returns true if the checkpoint is a pod sandbox
+// checkpoint, which contains pod.dump instead of config.dump
+func isSandboxCheckpoint(checkpointDirectory string) bool {
+	_, err := os.Lstat(filepath.Join(checkpointDirectory, metadata.PodDumpFile))
+	return err == nil
+}
+
+func namespaceMode(mode int32) string {
+	if name, ok := namespaceModes[mode]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("unknown (%d)", mode)
+}
+
+// sharedNamespaces returns the mode of the network, PID and IPC namespace
+// of the sandbox. Without namespace options all of them belong to the pod.
+func sharedNamespaces(podConfig *metadata.PodSandboxConfig) string {
+	options := &metadata.NamespaceOption{}
+	if podConfig.Linux != nil && podConfig.Linux.SecurityContext != nil && podConfig.Linux.SecurityContext.NamespaceOptions != nil {
+		options = podConfig.Linux.SecurityContext.NamespaceOptions
+	}
+
+	return fmt.Sprintf(
+		"network: %s, pid: %s, ipc: %s",
+		namespaceMode(options.Network),
+		namespaceMode(options.Pid),
+		namespaceMode(options.Ipc),
+	)
+}
+
+func showSandboxCheckpoint(input, checkpointDirectory string) error {
+	if outputFormat != outputTable {
+		return fmt.Errorf("--output %s is not supported for pod sandbox checkpoints", outputFormat)
+	}
+	podConfig, _, err := metadata.ReadPodSandboxCheckpointConfig(checkpointDirectory)
+	if err != nil {
+		return err
+	}
+	podOptions, _, err := metadata.ReadPodSandboxCheckpointOptions(checkpointDirectory)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	printCaption("Displaying pod sandbox checkpoint data from %s\n", input)
+
+	podMetadata := podConfig.Metadata
+	if podMetadata == nil {
+		podMetadata = &metadata.PodSandboxMetadata{}
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	header := []string{
+		"Sandbox",
+		"Namespace",
+	}
+	row := []string{
+		podMetadata.Name,
+		podMetadata.Namespace,
+	}
+	if podOptions.SandboxID != "" {
+		header = append(header, "ID")
+		row = append(row, shortID(podOptions.SandboxID))
+	}
+	header = append(header, "UID", "Hostname", "Shared Namespaces")
+	row = append(row, podMetadata.UID, podConfig.Hostname, sharedNamespaces(podConfig))
+	setTableHeader(table, header)
+	table.Append(row)
+	table.Render()
+
+	showSandboxNetwork(podConfig)
+
+	printCaption("Containers")
+	if len(podOptions.Containers) == 0 {
+		fmt.Println("No containers found in checkpoint")
+		return nil
+	}
+	var names []string
+	for name := range podOptions.Containers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	table = tablewriter.NewWriter(os.Stdout)
+	setTableHeader(table, []string{
+		"Name",
+		"ID",
+	})
+	for _, name := range names {
+		table.Append([]string{name, shortID(podOptions.Containers[name])})
+	}
+	table.Render()
+
+	return nil
+}
+
+// showSandboxNetwork displays the DNS configuration and the port mappings
+// of the sandbox, which have to be available on the restore host
+func showSandboxNetwork(podConfig *metadata.PodSandboxConfig) {
+	printCaption("Network configuration")
+	if dns := podConfig.DNSConfig; dns != nil {
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetAutoWrapText(false)
+		setTableHeader(table, []string{
+			"DNS Servers",
+			"Searches",
+			"Options",
+		})
+		table.Append([]string{
+			strings.Join(dns.Servers, ", "),
+			strings.Join(dns.Searches, ", "),
+			strings.Join(dns.Options, ", "),
+		})
+		table.Render()
+	}
+	if len(podConfig.PortMappings) == 0 {
+		fmt.Println("No port mappings configured")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	setTableHeader(table, []string{
+		"Protocol",
+		"Container Port",
+		"Host Port",
+		"Host IP",
+	})
+	for _, p := range podConfig.PortMappings {
+		protocol, ok := portProtocols[p.Protocol]
+		if !ok {
+			protocol = fmt.Sprintf("unknown (%d)", p.Protocol)
+		}
+		hostIP := p.HostIP
+		if hostIP == "" {
+			hostIP = "-"
+		}
+		table.Append([]string{
+			protocol,
+			fmt.Sprintf("%d", p.ContainerPort),
+			fmt.Sprintf("%d", p.HostPort),
+			hostIP,
+		})
+	}
+	table.Render()
+}
@@ -0,0 +1,112 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to display the seccomp configuration of container checkpoints
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	spec "github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// seccompNotifySyscalls returns the system calls which are forwarded to a
+// user space agent with SCMP_ACT_NOTIFY. With a notifying default action
+// all system calls without a rule are forwarded.
+func seccompNotifySyscalls(seccomp *spec.LinuxSeccomp) []string {
+	var syscalls []string
+	if seccomp.DefaultAction == spec.ActNotify {
+		syscalls = append(syscalls, "(default action)")
+	}
+	for _, s := range seccomp.Syscalls {
+		if s.Action == spec.ActNotify {
+			syscalls = append(syscalls, s.Names...)
+		}
+	}
+
+	return syscalls
+}
+
+func usesSeccompNotify(seccomp *spec.LinuxSeccomp) bool {
+	return seccomp.ListenerPath != "" || len(seccompNotifySyscalls(seccomp)) > 0
+}
+
+func showSeccompProfile(checkpointDirectory string, specDump *spec.Spec) error {
+	printCaption("Seccomp")
+	if specDump.Linux == nil || specDump.Linux.Seccomp == nil {
+		fmt.Println("No seccomp profile configured")
+	} else {
+		seccomp := specDump.Linux.Seccomp
+		var architectures []string
+		for _, a := range seccomp.Architectures {
+			architectures = append(architectures, string(a))
+		}
+		if len(architectures) == 0 {
+			architectures = []string{"-"}
+		}
+		notify := "no"
+		if usesSeccompNotify(seccomp) {
+			notify = "YES"
+			if syscalls := seccompNotifySyscalls(seccomp); len(syscalls) > 0 {
+				notify += ": " + strings.Join(syscalls, ", ")
+			}
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetAutoWrapText(false)
+		setTableHeader(table, []string{
+			"Default Action",
+			"Architectures",
+			"Rules",
+			"User Notification",
+		})
+		table.Append([]string{
+			string(seccomp.DefaultAction),
+			strings.Join(architectures, ", "),
+			fmt.Sprintf("%d", len(seccomp.Syscalls)),
+			notify,
+		})
+		table.Render()
+		if seccomp.ListenerPath != "" {
+			fmt.Printf("Seccomp listener: %s\n", seccomp.ListenerPath)
+		}
+		if usesSeccompNotify(seccomp) {
+			fmt.Fprintln(os.Stderr, "Warning: the container uses seccomp user notification, "+
+				"which is not supported by CRIU, the container may not restore cleanly")
+		}
+	}
+
+	if !criuImageExists(checkpointDirectory, pstreeImg) {
+		return nil
+	}
+	if err := checkImageVersion(checkpointDirectory); err != nil {
+		return err
+	}
+	processes, err := readProcesses(checkpointDirectory)
+	if err != nil {
+		return fmt.Errorf("unable to display seccomp modes: %w", err)
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	setTableHeader(table, []string{
+		"PID",
+		"Command",
+		"Seccomp Mode",
+	})
+	for _, p := range processes {
+		if !processSelected(p) {
+			continue
+		}
+		table.Append([]string{
+			fmt.Sprintf("%d", p.PID),
+			p.Comm,
+			p.Core.GetThreadCore().GetSeccompMode().String(),
+		})
+	}
+	table.Render()
+
+	return nil
+}
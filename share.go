@@ -0,0 +1,194 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to create redacted checkpoint metadata bundles
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	metadata "github.com/checkpoint-restore/checkpointctl/lib"
+	"github.com/checkpoint-restore/go-criu/v6/crit"
+	"github.com/checkpoint-restore/go-criu/v6/crit/images"
+)
+
+const (
+	redactIP   = "ip"
+	redactPath = "path"
+	redactEnv  = "env"
+
+	redactedValue = "***"
+)
+
+var redactableFields = []string{redactIP, redactPath, redactEnv}
+
+type shareMount struct {
+	Destination string `json:"destination"`
+	Type        string `json:"type,omitempty"`
+	Source      string `json:"source,omitempty"`
+}
+
+// shareBundle is the content of a checkpoint metadata bundle which can
+// be attached to bug reports
+type shareBundle struct {
+	Name           string                 `json:"name"`
+	Image          string                 `json:"image,omitempty"`
+	Runtime        string                 `json:"runtime,omitempty"`
+	Engine         string                 `json:"engine"`
+	Created        string                 `json:"created,omitempty"`
+	IP             string                 `json:"ip,omitempty"`
+	MAC            string                 `json:"mac,omitempty"`
+	CheckpointSize int64                  `json:"checkpointSize"`
+	RootFsDiffSize int64                  `json:"rootFsDiffSize,omitempty"`
+	Args           []string               `json:"args,omitempty"`
+	Env            []string               `json:"env,omitempty"`
+	Mounts         []shareMount           `json:"mounts,omitempty"`
+	DumpStatistics *images.DumpStatsEntry `json:"dumpStatistics,omitempty"`
+	ProcessTree    *crit.PsTree           `json:"processTree,omitempty"`
+	Redacted       []string               `json:"redacted,omitempty"`
+	// Omitted lists the optional parts which could not be included
+	Omitted []string `json:"omitted,omitempty"`
+}
+
+func validateRedactFields(fields []string) error {
+	for _, f := range fields {
+		found := false
+		for _, r := range redactableFields {
+			if f == r {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("unknown redaction field %q (supported: %s)", f, strings.Join(redactableFields, ", "))
+		}
+	}
+
+	return nil
+}
+
+func createShareBundle(checkpointDirectory string, redact []string) (*shareBundle, error) {
+	containerConfig, _, err := metadata.ReadContainerCheckpointConfigDump(checkpointDirectory)
+	if err != nil {
+		return nil, err
+	}
+	specDump, _, err := metadata.ReadContainerCheckpointSpecDump(checkpointDirectory)
+	if err != nil {
+		return nil, err
+	}
+	ci, err := getContainerInfo(checkpointDirectory, containerConfig, specDump)
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := getCheckpointSize(checkpointDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := &shareBundle{
+		Name:           ci.Name,
+		Image:          containerConfig.RootfsImageName,
+		Runtime:        containerConfig.OCIRuntime,
+		Engine:         ci.Engine,
+		Created:        ci.Created,
+		IP:             ci.IP,
+		MAC:            ci.MAC,
+		CheckpointSize: size,
+		Redacted:       redact,
+	}
+
+	if fi, err := os.Lstat(filepath.Join(checkpointDirectory, metadata.RootFsDiffTar)); err == nil {
+		bundle.RootFsDiffSize = fi.Size()
+	}
+
+	if specDump.Process != nil {
+		bundle.Args = specDump.Process.Args
+		bundle.Env = specDump.Process.Env
+	}
+
+	for _, m := range specDump.Mounts {
+		bundle.Mounts = append(bundle.Mounts, shareMount{
+			Destination: m.Destination,
+			Type:        m.Type,
+			Source:      m.Source,
+		})
+	}
+
+	// Statistics and the process tree are optional parts of a checkpoint
+	if dumpStatistics, err := crit.GetDumpStats(checkpointDirectory); err == nil {
+		bundle.DumpStatistics = dumpStatistics
+	}
+	if err := checkImageVersion(checkpointDirectory); err != nil {
+		bundle.Omitted = append(bundle.Omitted, fmt.Sprintf("processTree: %v", err))
+	} else {
+		c := crit.New("", "", imagesDirectory(checkpointDirectory), false, false)
+		if psTree, err := c.ExplorePs(); err == nil {
+			bundle.ProcessTree = psTree
+		}
+	}
+
+	for _, r := range redact {
+		bundle.redact(r)
+	}
+
+	return bundle, nil
+}
+
+func (b *shareBundle) redact(field string) {
+	switch field {
+	case redactIP:
+		if b.IP != "" {
+			b.IP = redactedValue
+		}
+		if b.MAC != "" {
+			b.MAC = redactedValue
+		}
+	case redactPath:
+		for i := range b.Mounts {
+			if filepath.IsAbs(b.Mounts[i].Source) {
+				b.Mounts[i].Source = redactedValue
+			}
+		}
+		// Arguments can be paths or options with a path, like --config=/etc/app
+		for i, a := range b.Args {
+			name, value, found := strings.Cut(a, "=")
+			switch {
+			case filepath.IsAbs(a):
+				b.Args[i] = redactedValue
+			case found && filepath.IsAbs(value):
+				b.Args[i] = name + "=" + redactedValue
+			}
+		}
+	case redactEnv:
+		for i, e := range b.Env {
+			name, _, _ := strings.Cut(e, "=")
+			b.Env[i] = name + "=" + redactedValue
+		}
+		// Options like --password=secret are masked like environment variables
+		for i, a := range b.Args {
+			if name, _, found := strings.Cut(a, "="); found {
+				b.Args[i] = name + "=" + redactedValue
+			}
+		}
+	}
+}
+
+func writeShareBundle(bundle *shareBundle, output string) error {
+	if output == "" {
+		data, err := json.MarshalIndent(bundle, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error marshalling JSON: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	_, err := metadata.WriteJSONFile(bundle, filepath.Dir(output), filepath.Base(output))
+
+	return err
+}
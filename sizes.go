@@ -0,0 +1,241 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to break down the size of container checkpoints
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// Images of processes and other objects end with the ID of the object,
+// like core-7.img or tcp-stream-1.img
+var imageID = regexp.MustCompile(`-[0-9]+$`)
+
+// Category of files in the images directory which are no CRIU images
+const otherFiles = "other"
+
+// fileSize is the size of a file relative to the directory it was found in
+type fileSize struct {
+	Path     string `json:"path"`
+	Category string `json:"category"`
+	Size     int64  `json:"size"`
+}
+
+type categorySize struct {
+	Category string `json:"category"`
+	Files    int    `json:"files"`
+	Size     int64  `json:"size"`
+	// The estimated size of compressed pages images
+	Uncompressed int64 `json:"uncompressedSize,omitempty"`
+}
+
+// imageSizes is the size of the CRIU images of a checkpoint per file and
+// per category, both sorted by size
+type imageSizes struct {
+	Files      []fileSize     `json:"files"`
+	Categories []categorySize `json:"categories"`
+	Total      int64          `json:"total"`
+}
+
+// walkFileSizes returns the size of every regular file below root
+func walkFileSizes(root string) ([]fileSize, error) {
+	var files []fileSize
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, fileSize{Path: rel, Size: info.Size()})
+		return nil
+	})
+
+	return files, err
+}
+
+// imageCategory returns the type of a CRIU image like "pages" or "core"
+func imageCategory(path string) string {
+	name := filepath.Base(path)
+	if !strings.HasSuffix(name, ".img") {
+		return otherFiles
+	}
+
+	return imageID.ReplaceAllString(strings.TrimSuffix(name, ".img"), "")
+}
+
+// getImageSizes returns the sizes of the CRIU images. The uncompressed size
+// of compressed memory pages is only determined with decodePages, as it is
+// read from the pagemap images.
+func getImageSizes(checkpointDirectory string, decodePages bool) (*imageSizes, error) {
+	files, err := walkFileSizes(imagesDirectory(checkpointDirectory))
+	if err != nil {
+		return nil, err
+	}
+
+	sizes := &imageSizes{Files: []fileSize{}, Categories: []categorySize{}}
+	categories := make(map[string]*categorySize)
+	for _, f := range files {
+		f.Category = imageCategory(f.Path)
+		sizes.Files = append(sizes.Files, f)
+		sizes.Total += f.Size
+		c, ok := categories[f.Category]
+		if !ok {
+			c = &categorySize{Category: f.Category}
+			categories[f.Category] = c
+		}
+		c.Files++
+		c.Size += f.Size
+	}
+	if c, ok := categories["pages"]; ok && decodePages {
+		pages, err := getPagesSizes(checkpointDirectory)
+		if err != nil {
+			return nil, err
+		}
+		if pages.Compressed {
+			c.Uncompressed = pages.Uncompressed
+		}
+	}
+	for _, c := range categories {
+		sizes.Categories = append(sizes.Categories, *c)
+	}
+
+	sort.Slice(sizes.Files, func(i, j int) bool {
+		if sizes.Files[i].Size != sizes.Files[j].Size {
+			return sizes.Files[i].Size > sizes.Files[j].Size
+		}
+		return sizes.Files[i].Path < sizes.Files[j].Path
+	})
+	sort.Slice(sizes.Categories, func(i, j int) bool {
+		if sizes.Categories[i].Size != sizes.Categories[j].Size {
+			return sizes.Categories[i].Size > sizes.Categories[j].Size
+		}
+		return sizes.Categories[i].Category < sizes.Categories[j].Category
+	})
+
+	return sizes, nil
+}
+
+func showImageSizes(checkpointDirectory string) error {
+	// Only the size of the pages images is unpacked with --metadata-only,
+	// which is not enough to detect compressed pages
+	sizes, err := getImageSizes(checkpointDirectory, !metadataOnly)
+	if err != nil {
+		return fmt.Errorf("unable to display image sizes: %w", err)
+	}
+
+	// Compressed pages are displayed next to their uncompressed size
+	var compressed *categorySize
+	for i := range sizes.Categories {
+		if sizes.Categories[i].Uncompressed > 0 {
+			compressed = &sizes.Categories[i]
+		}
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	header := []string{
+		"Category",
+		"Files",
+		"Size",
+	}
+	if compressed != nil {
+		header = append(header, "Uncompressed")
+	}
+	setTableHeader(table, header)
+	files := 0
+	for _, c := range sizes.Categories {
+		row := []string{c.Category, formatCount(int64(c.Files)), formatSize(c.Size)}
+		if compressed != nil {
+			uncompressed := "-"
+			if c.Uncompressed > 0 {
+				uncompressed = formatSize(c.Uncompressed)
+			}
+			row = append(row, uncompressed)
+		}
+		table.Append(row)
+		files += c.Files
+	}
+	total := []string{"Total", formatCount(int64(files)), formatSize(sizes.Total)}
+	if compressed != nil {
+		total = append(total, formatSize(sizes.Total-compressed.Size+compressed.Uncompressed))
+	}
+	table.Append(total)
+	printCaption("Image sizes")
+	table.Render()
+
+	return nil
+}
+
+// getSizeBreakdown returns the size of the files and directories of the
+// checkpoint archive and of the CRIU images, sorted by size. Empty files
+// are left out.
+func getSizeBreakdown(checkpointDirectory string) ([]fileSize, error) {
+	top, err := dirEntrySizes(checkpointDirectory)
+	if err != nil {
+		return nil, err
+	}
+	imagesName := imagesDirectoryName(checkpointDirectory)
+	imageFiles, err := dirEntrySizes(filepath.Join(checkpointDirectory, imagesName))
+	if err != nil {
+		return nil, err
+	}
+
+	var sizes []fileSize
+	for _, f := range top {
+		// The images are listed one by one instead
+		if f.Path != imagesName && f.Size > 0 {
+			sizes = append(sizes, f)
+		}
+	}
+	for _, f := range imageFiles {
+		if f.Size > 0 {
+			f.Path = filepath.Join(imagesName, f.Path)
+			sizes = append(sizes, f)
+		}
+	}
+
+	sort.Slice(sizes, func(i, j int) bool {
+		if sizes[i].Size != sizes[j].Size {
+			return sizes[i].Size > sizes[j].Size
+		}
+		return sizes[i].Path < sizes[j].Path
+	})
+
+	return sizes, nil
+}
+
+func showSizeBreakdown(checkpointDirectory string) error {
+	sizes, err := getSizeBreakdown(checkpointDirectory)
+	if err != nil {
+		return fmt.Errorf("unable to display size breakdown: %w", err)
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	setTableHeader(table, []string{
+		"Path",
+		"Size",
+	})
+	var total int64
+	for _, f := range sizes {
+		table.Append([]string{f.Path, formatSize(f.Size)})
+		total += f.Size
+	}
+	table.Append([]string{"Total", formatSize(total)})
+	printCaption("Size breakdown")
+	table.Render()
+
+	return nil
+}
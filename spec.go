@@ -0,0 +1,244 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to validate the spec of a container checkpoint against
+// the OCI runtime specification
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	spec "github.com/opencontainers/runtime-spec/specs-go"
+)
+
+var errSpecInvalid = errors.New("spec.dump violates the OCI runtime specification")
+
+// Patterns of the OCI runtime-spec JSON schema (schema/defs.json and
+// schema/config-linux.json)
+var (
+	rlimitPattern     = regexp.MustCompile(`^RLIMIT_[A-Z]+$`)
+	capabilityPattern = regexp.MustCompile(`^CAP_[A-Z_]+$`)
+)
+
+// Allowed values of the enums of the OCI runtime-spec JSON schema
+var (
+	namespaceTypes     = []string{"mount", "pid", "network", "uts", "ipc", "user", "cgroup", "time"}
+	deviceCgroupTypes  = []string{"a", "c", "b"}
+	rootfsPropagations = []string{"private", "shared", "slave", "unbindable"}
+)
+
+// specViolation is a field of the spec which does not conform to the
+// OCI runtime specification. Path is the JSON path of the field.
+type specViolation struct {
+	Path    string
+	Problem string
+}
+
+type specValidation struct {
+	violations []specViolation
+}
+
+func (v *specValidation) add(field, format string, args ...any) {
+	v.violations = append(v.violations, specViolation{field, fmt.Sprintf(format, args...)})
+}
+
+func (v *specValidation) required(field, value string) bool {
+	if value == "" {
+		v.add(field, "is required")
+		return false
+	}
+
+	return true
+}
+
+func (v *specValidation) absolute(field, value string) {
+	if v.required(field, value) && !path.IsAbs(value) {
+		v.add(field, "%q is not an absolute path", value)
+	}
+}
+
+func (v *specValidation) oneOf(field, value string, allowed []string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	v.add(field, "%q is not one of %s", value, strings.Join(allowed, ", "))
+}
+
+// validateSpec checks the structure of the spec against the constraints of
+// the OCI runtime-spec JSON schema and the Linux specific requirements of
+// the specification. Fields which the runtime fills with defaults are only
+// checked if they are set.
+func validateSpec(specDump *spec.Spec) []specViolation {
+	v := &specValidation{}
+
+	v.required("ociVersion", specDump.Version)
+	if specDump.Root != nil {
+		v.required("root.path", specDump.Root.Path)
+	}
+	if specDump.Process != nil {
+		validateSpecProcess(v, specDump.Process)
+	}
+	for i, m := range specDump.Mounts {
+		v.absolute(fmt.Sprintf("mounts[%d].destination", i), m.Destination)
+	}
+	if specDump.Hooks != nil {
+		for _, hooks := range []struct {
+			name  string
+			hooks []spec.Hook
+		}{
+			{"prestart", specDump.Hooks.Prestart},
+			{"createRuntime", specDump.Hooks.CreateRuntime},
+			{"createContainer", specDump.Hooks.CreateContainer},
+			{"startContainer", specDump.Hooks.StartContainer},
+			{"poststart", specDump.Hooks.Poststart},
+			{"poststop", specDump.Hooks.Poststop},
+		} {
+			for i, h := range hooks.hooks {
+				v.absolute(fmt.Sprintf("hooks.%s[%d].path", hooks.name, i), h.Path)
+			}
+		}
+	}
+	if specDump.Linux != nil {
+		validateSpecLinux(v, specDump.Linux)
+	}
+
+	return v.violations
+}
+
+func validateSpecProcess(v *specValidation, p *spec.Process) {
+	if len(p.Args) == 0 && p.CommandLine == "" {
+		v.add("process.args", "at least one entry is required")
+	}
+	v.absolute("process.cwd", p.Cwd)
+	for i, e := range p.Env {
+		if !strings.Contains(e, "=") {
+			v.add(fmt.Sprintf("process.env[%d]", i), "%q is not of the form KEY=value", e)
+		}
+	}
+	for i, r := range p.Rlimits {
+		if !rlimitPattern.MatchString(r.Type) {
+			v.add(fmt.Sprintf("process.rlimits[%d].type", i), "%q is not a valid rlimit", r.Type)
+		}
+		if r.Soft > r.Hard {
+			v.add(fmt.Sprintf("process.rlimits[%d]", i), "soft limit %d exceeds hard limit %d", r.Soft, r.Hard)
+		}
+	}
+	if p.Capabilities != nil {
+		for _, set := range []struct {
+			name string
+			caps []string
+		}{
+			{"bounding", p.Capabilities.Bounding},
+			{"effective", p.Capabilities.Effective},
+			{"inheritable", p.Capabilities.Inheritable},
+			{"permitted", p.Capabilities.Permitted},
+			{"ambient", p.Capabilities.Ambient},
+		} {
+			for i, c := range set.caps {
+				if !capabilityPattern.MatchString(c) {
+					v.add(fmt.Sprintf("process.capabilities.%s[%d]", set.name, i), "%q is not a valid capability", c)
+				}
+			}
+		}
+	}
+}
+
+func validateSpecLinux(v *specValidation, l *spec.Linux) {
+	seen := make(map[spec.LinuxNamespaceType]bool)
+	for i, ns := range l.Namespaces {
+		field := fmt.Sprintf("linux.namespaces[%d].type", i)
+		v.oneOf(field, string(ns.Type), namespaceTypes)
+		if seen[ns.Type] {
+			v.add(field, "namespace %q is specified more than once", ns.Type)
+		}
+		seen[ns.Type] = true
+	}
+	for _, mappings := range []struct {
+		name     string
+		mappings []spec.LinuxIDMapping
+	}{
+		{"uidMappings", l.UIDMappings},
+		{"gidMappings", l.GIDMappings},
+	} {
+		for i, m := range mappings.mappings {
+			if m.Size == 0 {
+				v.add(fmt.Sprintf("linux.%s[%d].size", mappings.name, i), "must not be 0")
+			}
+		}
+	}
+	for i, d := range l.Devices {
+		v.absolute(fmt.Sprintf("linux.devices[%d].path", i), d.Path)
+		if _, ok := deviceTypes[d.Type]; !ok {
+			v.add(fmt.Sprintf("linux.devices[%d].type", i), "%q is not one of b, c, u, p", d.Type)
+		}
+	}
+	if l.Resources != nil {
+		for i, d := range l.Resources.Devices {
+			if d.Type != "" {
+				v.oneOf(fmt.Sprintf("linux.resources.devices[%d].type", i), d.Type, deviceCgroupTypes)
+			}
+		}
+	}
+	if l.RootfsPropagation != "" {
+		v.oneOf("linux.rootfsPropagation", l.RootfsPropagation, rootfsPropagations)
+	}
+	if l.Seccomp != nil {
+		v.required("linux.seccomp.defaultAction", string(l.Seccomp.DefaultAction))
+		for i, s := range l.Seccomp.Syscalls {
+			if len(s.Names) == 0 {
+				v.add(fmt.Sprintf("linux.seccomp.syscalls[%d].names", i), "at least one entry is required")
+			}
+			v.required(fmt.Sprintf("linux.seccomp.syscalls[%d].action", i), string(s.Action))
+		}
+	}
+	for i, p := range l.MaskedPaths {
+		v.absolute(fmt.Sprintf("linux.maskedPaths[%d]", i), p)
+	}
+	for i, p := range l.ReadonlyPaths {
+		v.absolute(fmt.Sprintf("linux.readonlyPaths[%d]", i), p)
+	}
+}
+
+// showSpecValidation prints the violations of the OCI runtime specification
+// found in the spec. The violations are only an advisory, unless --strict
+// is given.
+func showSpecValidation(specDump *spec.Spec) error {
+	violations := validateSpec(specDump)
+
+	printCaption("Spec validation")
+	if len(violations) == 0 {
+		fmt.Println("No violations of the OCI runtime specification found in spec.dump")
+		return nil
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	setTableHeader(table, []string{
+		"Field",
+		"Problem",
+	})
+	for _, violation := range violations {
+		table.Append([]string{violation.Path, violation.Problem})
+	}
+	table.Render()
+
+	if strict {
+		return fmt.Errorf("%w: %s", errSpecInvalid, plural(len(violations), "violation"))
+	}
+	fmt.Fprintf(
+		os.Stderr,
+		"Warning: spec.dump has %s of the OCI runtime specification, "+
+			"which might cause the restore to fail\n",
+		plural(len(violations), "violation"),
+	)
+
+	return nil
+}
@@ -0,0 +1,125 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to display the sizes of container checkpoints without
+// decoding any of their contents
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	metadata "github.com/checkpoint-restore/checkpointctl/lib"
+	"github.com/olekukonko/tablewriter"
+)
+
+// statOutput is the result of the stat subcommand. All sizes are in bytes.
+type statOutput struct {
+	Input string `json:"input"`
+	// The size of the archive files, which is not known for checkpoints
+	// pulled from a registry
+	ArchiveSize    int64          `json:"archiveSize,omitempty"`
+	Total          int64          `json:"total"`
+	CheckpointSize int64          `json:"checkpointSize"`
+	RootFsDiffSize int64          `json:"rootFsDiffSize"`
+	Categories     []categorySize `json:"categories"`
+}
+
+func (o statOutput) logfmtFields() []logfmtField {
+	var archiveSize string
+	if o.ArchiveSize > 0 {
+		archiveSize = formatSize(o.ArchiveSize)
+	}
+
+	return []logfmtField{
+		{"input", o.Input},
+		{"archive_size", archiveSize},
+		{"total", formatSize(o.Total)},
+		{"checkpoint_size", formatSize(o.CheckpointSize)},
+		{"rootfs_diff_size", formatSize(o.RootFsDiffSize)},
+	}
+}
+
+// archiveSize returns the size of all parts of a local checkpoint archive
+func archiveSize(input string) (int64, error) {
+	parts, err := getArchiveParts(input)
+	if err != nil {
+		return 0, err
+	}
+	var size int64
+	for _, p := range parts {
+		fi, err := os.Stat(p)
+		if err != nil {
+			return 0, err
+		}
+		size += fi.Size()
+	}
+
+	return size, nil
+}
+
+func getStatOutput(input, checkpointDirectory string) (*statOutput, error) {
+	out := &statOutput{Input: input}
+	if !isImageReference(input) {
+		size, err := archiveSize(input)
+		if err != nil {
+			return nil, err
+		}
+		out.ArchiveSize = size
+	}
+
+	var err error
+	if out.Total, err = dirSize(checkpointDirectory); err != nil {
+		return nil, err
+	}
+	if out.CheckpointSize, err = getCheckpointSize(checkpointDirectory); err != nil {
+		return nil, err
+	}
+	if fi, err := os.Lstat(filepath.Join(checkpointDirectory, metadata.RootFsDiffTar)); err == nil {
+		out.RootFsDiffSize = fi.Size()
+	}
+	// The sizes are taken from the file system only, without decoding the
+	// pagemap images for the uncompressed size of compressed pages
+	sizes, err := getImageSizes(checkpointDirectory, false)
+	if err != nil {
+		return nil, err
+	}
+	out.Categories = sizes.Categories
+
+	return out, nil
+}
+
+func showCheckpointStat(out *statOutput) {
+	printCaption("Sizes of container checkpoint %s\n", out.Input)
+
+	archive := "-"
+	if out.ArchiveSize > 0 {
+		archive = formatSize(out.ArchiveSize)
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	setTableHeader(table, []string{
+		"Archive",
+		"Total",
+		"Checkpoint",
+		"Rootfs Diff",
+	})
+	table.Append([]string{
+		archive,
+		formatSize(out.Total),
+		formatSize(out.CheckpointSize),
+		formatSize(out.RootFsDiffSize),
+	})
+	table.Render()
+
+	table = tablewriter.NewWriter(os.Stdout)
+	setTableHeader(table, []string{
+		"Category",
+		"Files",
+		"Size",
+	})
+	for _, c := range out.Categories {
+		table.Append([]string{c.Category, formatCount(int64(c.Files)), formatSize(c.Size)})
+	}
+	printCaption("Image sizes")
+	table.Render()
+}
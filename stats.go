@@ -0,0 +1,232 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to display the CRIU statistics of container checkpoints
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	metadata "github.com/checkpoint-restore/checkpointctl/lib"
+	"github.com/checkpoint-restore/go-criu/v6/crit"
+	"github.com/checkpoint-restore/go-criu/v6/crit/images"
+	"github.com/olekukonko/tablewriter"
+)
+
+// statisticsExist returns true if the CRIU statistics with the given name
+// were written next to the CRIU images
+func statisticsExist(checkpointDirectory, name string) bool {
+	_, err := os.Stat(filepath.Join(checkpointDirectory, name))
+	return err == nil
+}
+
+// readDumpStats and readRestoreStats decode the CRIU statistics which
+// are stored next to the CRIU images of the checkpoint
+func readDumpStats(checkpointDirectory string) (*images.DumpStatsEntry, error) {
+	if !statisticsExist(checkpointDirectory, crit.StatsDump) {
+		return nil, fmt.Errorf("%s %w", crit.StatsDump, metadata.ErrSectionUnavailable)
+	}
+	dumpStatistics, err := crit.GetDumpStats(checkpointDirectory)
+	if path := filepath.Join(checkpointDirectory, crit.StatsDump); err != nil && isUnsupportedImage(path, err) {
+		return nil, criuImageError(checkpointDirectory, path, err)
+	}
+
+	return dumpStatistics, err
+}
+
+func readRestoreStats(checkpointDirectory string) (*images.RestoreStatsEntry, error) {
+	if !statisticsExist(checkpointDirectory, crit.StatsRestore) {
+		return nil, fmt.Errorf("%s %w", crit.StatsRestore, metadata.ErrSectionUnavailable)
+	}
+	restoreStatistics, err := crit.GetRestoreStats(checkpointDirectory)
+	if path := filepath.Join(checkpointDirectory, crit.StatsRestore); err != nil && isUnsupportedImage(path, err) {
+		return nil, criuImageError(checkpointDirectory, path, err)
+	}
+
+	return restoreStatistics, err
+}
+
+// readCriuStatistics returns the dump and the restore statistics of the
+// checkpoint. The restore statistics are nil if the checkpoint has not been
+// restored from its location. Without any statistics the error about the
+// missing dump statistics is returned.
+func readCriuStatistics(checkpointDirectory string) (*images.DumpStatsEntry, *images.RestoreStatsEntry, error) {
+	var restoreStatistics *images.RestoreStatsEntry
+	if statisticsExist(checkpointDirectory, crit.StatsRestore) {
+		var err error
+		if restoreStatistics, err = readRestoreStats(checkpointDirectory); err != nil {
+			return nil, nil, fmt.Errorf("unable to display restore statistics: %w", err)
+		}
+		if !statisticsExist(checkpointDirectory, crit.StatsDump) {
+			return nil, restoreStatistics, nil
+		}
+	}
+	dumpStatistics, err := readDumpStats(checkpointDirectory)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to display checkpointing statistics: %w", err)
+	}
+
+	return dumpStatistics, restoreStatistics, nil
+}
+
+// showCriuStatistics displays the dump statistics and, if the checkpoint
+// has been restored, the restore statistics
+func showCriuStatistics(checkpointDirectory string) error {
+	dumpStatistics, restoreStatistics, err := readCriuStatistics(checkpointDirectory)
+	if err != nil {
+		return err
+	}
+	if dumpStatistics != nil {
+		showDumpStatistics(dumpStatistics)
+	}
+	if restoreStatistics != nil {
+		showRestoreStatistics(restoreStatistics)
+	}
+
+	return nil
+}
+
+func showDumpStatistics(dumpStatistics *images.DumpStatsEntry) {
+	table := tablewriter.NewWriter(os.Stdout)
+	setTableHeader(table, []string{
+		"Freezing Time",
+		"Frozen Time",
+		"Memdump Time",
+		"Memwrite Time",
+		"Pages Scanned",
+		"Pages Written",
+	})
+	table.Append([]string{
+		fmt.Sprintf("%d us", dumpStatistics.GetFreezingTime()),
+		fmt.Sprintf("%d us", dumpStatistics.GetFrozenTime()),
+		fmt.Sprintf("%d us", dumpStatistics.GetMemdumpTime()),
+		fmt.Sprintf("%d us", dumpStatistics.GetMemwriteTime()),
+		formatCount(int64(dumpStatistics.GetPagesScanned())),
+		formatCount(int64(dumpStatistics.GetPagesWritten())),
+	})
+	printCaption("CRIU dump statistics")
+	table.Render()
+}
+
+func showRestoreStatistics(restoreStatistics *images.RestoreStatsEntry) {
+	table := tablewriter.NewWriter(os.Stdout)
+	header := []string{
+		"Forking Time",
+		"Restore Time",
+		"Pages Compared",
+		"Pages Skipped COW",
+	}
+	row := []string{
+		fmt.Sprintf("%d us", restoreStatistics.GetForkingTime()),
+		fmt.Sprintf("%d us", restoreStatistics.GetRestoreTime()),
+		formatCount(int64(restoreStatistics.GetPagesCompared())),
+		formatCount(int64(restoreStatistics.GetPagesSkippedCow())),
+	}
+	// Older versions of CRIU do not record the number of restored pages
+	if restoreStatistics.PagesRestored != nil {
+		header = append(header, "Pages Restored")
+		row = append(row, formatCount(int64(restoreStatistics.GetPagesRestored())))
+	}
+	setTableHeader(table, header)
+	table.Append(row)
+	printCaption("CRIU restore statistics")
+	table.Render()
+}
+
+// statsMetric is a metric recorded by both, CRIU dump and CRIU restore
+type statsMetric struct {
+	Name    string
+	Dump    int64
+	Restore int64
+}
+
+// showStatsComparison displays the metrics which are recorded in the dump
+// and in the restore statistics of a checkpoint which has been restored
+// from the same location side by side. The timings of dump and restore
+// measure different steps and are not compared.
+func showStatsComparison(checkpointDirectory string) error {
+	dumpStatistics, err := readDumpStats(checkpointDirectory)
+	if err != nil {
+		return fmt.Errorf("unable to display dump statistics: %w", err)
+	}
+	restoreStatistics, err := readRestoreStats(checkpointDirectory)
+	if err != nil {
+		return fmt.Errorf("unable to display restore statistics: %w", err)
+	}
+
+	var metrics []statsMetric
+	// Older versions of CRIU do not record the number of restored pages
+	if restoreStatistics.PagesRestored != nil {
+		metrics = append(metrics, statsMetric{
+			Name:    "Pages",
+			Dump:    int64(dumpStatistics.GetPagesWritten()),
+			Restore: int64(restoreStatistics.GetPagesRestored()),
+		})
+	}
+	if len(metrics) == 0 {
+		return fmt.Errorf(
+			"unable to display dump and restore statistics: restored pages in %s %w",
+			crit.StatsRestore, metadata.ErrSectionUnavailable,
+		)
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	setTableHeader(table, []string{
+		"Metric",
+		"Dump",
+		"Restore",
+		"Delta",
+	})
+	for _, m := range metrics {
+		delta := formatCount(m.Restore - m.Dump)
+		if m.Restore >= m.Dump {
+			delta = "+" + delta
+		}
+		table.Append([]string{
+			m.Name,
+			formatCount(m.Dump),
+			formatCount(m.Restore),
+			delta,
+		})
+	}
+	printCaption("CRIU dump and restore statistics")
+	table.Render()
+
+	return nil
+}
+
+// getCheckpointDuration returns the wall-clock time the container was
+// blocked by checkpointing. CRIU does not record the start and end of
+// the dump, but the container is blocked from the start of freezing
+// until it is unfrozen, which is the sum of the freezing and the frozen
+// time. It is false if the checkpoint contains no dump statistics.
+func getCheckpointDuration(checkpointDirectory string) (time.Duration, bool) {
+	dumpStatistics, err := crit.GetDumpStats(checkpointDirectory)
+	if err != nil {
+		return 0, false
+	}
+	us := dumpStatistics.GetFreezingTime() + dumpStatistics.GetFrozenTime()
+
+	return time.Duration(us) * time.Microsecond, true
+}
+
+func showCheckpointDuration(checkpointDirectory string) {
+	duration := "unknown"
+	source := "no dump statistics found"
+	if d, ok := getCheckpointDuration(checkpointDirectory); ok {
+		duration = d.String()
+		source = "freezing time + frozen time"
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	setTableHeader(table, []string{
+		"Duration",
+		"Derived From",
+	})
+	table.Append([]string{duration, source})
+	printCaption("Checkpoint duration")
+	table.Render()
+}
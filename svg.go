@@ -0,0 +1,183 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to render the memory of container checkpoints as SVG
+
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	viewMemory = "memory"
+
+	svgWidth     = 1200
+	svgMargin    = 10
+	svgTitle     = 30
+	svgRowHeight = 20
+	// Approximate width of a character of the 12px label font
+	svgCharWidth = 7
+)
+
+var svgViews = []string{viewMemory}
+
+func validateView(view string) error {
+	for _, v := range svgViews {
+		if view == v {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unsupported view %q (supported: %s)", view, strings.Join(svgViews, ", "))
+}
+
+// memoryNode is a process with the size of its own memory pages and of
+// the memory pages of all its descendants
+type memoryNode struct {
+	process  *processInfo
+	own      uint64
+	total    uint64
+	children []*memoryNode
+}
+
+// buildMemoryTree returns the process trees of the checkpoint with the
+// memory pages stored for each process. It also returns the depth of the
+// deepest tree.
+func buildMemoryTree(checkpointDirectory string) ([]*memoryNode, int, error) {
+	tree, err := buildProcessTree(checkpointDirectory)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var build func(n *processNode, depth int) (*memoryNode, int, error)
+	build = func(n *processNode, depth int) (*memoryNode, int, error) {
+		// The size of the memory mappings would be dominated by reserved
+		// but unused address space, like the heap arenas of Go or Java
+		size, err := dumpedMemory(checkpointDirectory, n.process.PID)
+		if err != nil {
+			return nil, 0, err
+		}
+		m := &memoryNode{process: n.process, own: size, total: size}
+		deepest := depth
+		for _, c := range n.children {
+			child, d, err := build(c, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			if d > deepest {
+				deepest = d
+			}
+			m.total += child.total
+			m.children = append(m.children, child)
+		}
+		return m, deepest, nil
+	}
+	var roots []*memoryNode
+	depth := 0
+	for _, r := range tree {
+		root, d, err := build(r, 1)
+		if err != nil {
+			return nil, 0, err
+		}
+		if d > depth {
+			depth = d
+		}
+		roots = append(roots, root)
+	}
+
+	return roots, depth, nil
+}
+
+// svgColor returns a warm color like in flame graphs, which is the same
+// for all processes with the same command
+func svgColor(comm string) string {
+	h := fnv.New32a()
+	h.Write([]byte(comm))
+	v := h.Sum32()
+
+	return fmt.Sprintf("rgb(%d,%d,%d)", 200+v%55, (v>>8)%230, (v>>16)%55)
+}
+
+func svgEscape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+
+	return b.String()
+}
+
+func writeMemoryNode(w io.Writer, n *memoryNode, x float64, depth int, scale float64) {
+	width := float64(n.total) * scale
+	if width < 0.5 {
+		return
+	}
+	y := svgTitle + depth*svgRowHeight
+	label := fmt.Sprintf("%s (%d) %s", n.process.Comm, n.process.PID, formatSize(int64(n.total)))
+	fmt.Fprintf(w, "<g>\n<title>%s, %s own</title>\n", svgEscape(label), formatSize(int64(n.own)))
+	fmt.Fprintf(
+		w, "<rect x=\"%.1f\" y=\"%d\" width=\"%.1f\" height=\"%d\" fill=\"%s\" stroke=\"white\"/>\n",
+		x, y, width, svgRowHeight, svgColor(n.process.Comm),
+	)
+	if chars := int(width-6) / svgCharWidth; chars >= 3 {
+		if runes := []rune(label); len(runes) > chars {
+			label = string(runes[:chars-2]) + ".."
+		}
+		fmt.Fprintf(w, "<text x=\"%.1f\" y=\"%d\">%s</text>\n", x+3, y+14, svgEscape(label))
+	}
+	fmt.Fprintln(w, "</g>")
+
+	for _, c := range n.children {
+		writeMemoryNode(w, c, x, depth+1, scale)
+		x += float64(c.total) * scale
+	}
+}
+
+// writeMemorySVG renders the memory of the processes as an icicle graph.
+// The width of each process is the size of the memory pages of the
+// process and its descendants, the children are drawn below their parent.
+func writeMemorySVG(w io.Writer, title string, roots []*memoryNode, depth int) error {
+	var total uint64
+	for _, r := range roots {
+		total += r.total
+	}
+	if total == 0 {
+		return fmt.Errorf("no memory pages found in checkpoint")
+	}
+
+	height := svgTitle + depth*svgRowHeight + svgMargin
+	fmt.Fprintf(
+		w, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" font-family=\"monospace\" font-size=\"12\">\n",
+		svgWidth, height,
+	)
+	fmt.Fprintf(w, "<text x=\"%d\" y=\"20\" font-size=\"16\">%s</text>\n", svgMargin, svgEscape(title))
+	scale := float64(svgWidth-2*svgMargin) / float64(total)
+	x := float64(svgMargin)
+	for _, r := range roots {
+		writeMemoryNode(w, r, x, 0, scale)
+		x += float64(r.total) * scale
+	}
+	fmt.Fprintln(w, "</svg>")
+
+	return nil
+}
+
+func showMemorySVG(checkpointDirectory string, ci *containerInfo) error {
+	if err := checkImageVersion(checkpointDirectory); err != nil {
+		return err
+	}
+	roots, depth, err := buildMemoryTree(checkpointDirectory)
+	if err != nil {
+		return fmt.Errorf("unable to render memory: %w", err)
+	}
+
+	title := "Memory pages by process"
+	if ci.Name != "" {
+		title += " of container " + ci.Name
+	}
+
+	return writeMemorySVG(os.Stdout, title, roots, depth)
+}
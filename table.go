@@ -0,0 +1,30 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to render the table output of the show subcommand
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// printCaption prints the caption of a section of the table output after
+// a blank line. With --quiet only the tables themselves are printed.
+func printCaption(format string, a ...interface{}) {
+	if quiet {
+		return
+	}
+	fmt.Printf("\n"+format+"\n", a...)
+}
+
+// setTableHeader sets the header of a table unless it is left out with
+// --no-header, which allows to append the rows of several checkpoints
+// to the same stream
+func setTableHeader(table *tablewriter.Table, header []string) {
+	if noHeader {
+		return
+	}
+	table.SetHeader(header)
+}
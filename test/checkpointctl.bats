@@ -6,6 +6,8 @@ else
 fi
 TEST_TMP_DIR1=""
 TEST_TMP_DIR2=""
+# Numbers are formatted according to the locale of the environment
+export LC_ALL=C
 
 function checkpointctl() {
 	# shellcheck disable=SC2086
@@ -44,7 +46,111 @@ function teardown() {
 	touch "$TEST_TMP_DIR1"/empty.tar
 	checkpointctl show "$TEST_TMP_DIR1"/empty.tar
 	[ "$status" -eq 1 ]
-	[[ ${lines[0]} == *"config.dump: no such file or directory"* ]]
+	[[ ${lines[0]} == *"empty.tar is not a container checkpoint: neither config.dump nor spec.dump found" ]]
+}
+
+@test "Run checkpointctl show with gzip compressed tar file" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar czf "$TEST_TMP_DIR2"/test.tar.gz . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar.gz
+	[ "$status" -eq 0 ]
+	[[ ${lines[0]} == "Displaying container checkpoint data from "* ]]
+}
+
+@test "Run checkpointctl show with several tar files" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test1.tar . )
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test2.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test1.tar "$TEST_TMP_DIR2"/test2.tar
+	[ "$status" -eq 0 ]
+	[[ ${lines[0]} == "Displaying container checkpoint data from $TEST_TMP_DIR2/test1.tar" ]]
+	[[ ${output} == *"Displaying container checkpoint data from $TEST_TMP_DIR2/test2.tar"* ]]
+}
+
+@test "Run checkpointctl show with several tar files and one broken" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	touch "$TEST_TMP_DIR2"/empty.tar
+	checkpointctl show "$TEST_TMP_DIR2"/empty.tar "$TEST_TMP_DIR2"/test.tar
+	[ "$status" -eq 0 ]
+	[[ ${lines[0]} == "Error: $TEST_TMP_DIR2/empty.tar: "*"is not a container checkpoint"* ]]
+	[[ ${lines[1]} == "Displaying container checkpoint data from $TEST_TMP_DIR2/test.tar" ]]
+}
+
+@test "Run checkpointctl show with several broken tar files" {
+	touch "$TEST_TMP_DIR2"/empty1.tar "$TEST_TMP_DIR2"/empty2.tar
+	checkpointctl show "$TEST_TMP_DIR2"/empty1.tar "$TEST_TMP_DIR2"/empty2.tar
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == "Error: $TEST_TMP_DIR2/empty1.tar: "* ]]
+	[[ ${lines[1]} == "Error: $TEST_TMP_DIR2/empty2.tar: "* ]]
+	[[ ${lines[2]} == "Error: none of the 2 checkpoints could be displayed: "* ]]
+}
+
+@test "Run checkpointctl show with several tar files from unknown container manager" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	echo '{"annotations": {"io.container.manager": "custom-tool"}}' > "$TEST_TMP_DIR1"/spec.dump
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/one.tar . )
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/two.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/one.tar "$TEST_TMP_DIR2"/two.tar
+	[ "$status" -eq 2 ]
+	[[ "$output" == *"Error: none of the 2 checkpoints could be displayed: "* ]]
+	touch "$TEST_TMP_DIR2"/empty.tar
+	checkpointctl show "$TEST_TMP_DIR2"/one.tar "$TEST_TMP_DIR2"/empty.tar
+	[ "$status" -eq 1 ]
+}
+
+@test "Run checkpointctl show with several tar files and --output=yaml" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test1.tar . )
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test2.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test1.tar "$TEST_TMP_DIR2"/test2.tar --output=yaml
+	[ "$status" -eq 0 ]
+	[[ $(grep -c '^container:' <<< "$output") -eq 2 ]]
+	[[ $(grep -c '^---$' <<< "$output") -eq 1 ]]
+}
+
+@test "Run checkpointctl show with the same tar file twice" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	ln -s test.tar "$TEST_TMP_DIR2"/link.tar
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar "$TEST_TMP_DIR2"/link.tar "$TEST_TMP_DIR2"/../"$(basename "$TEST_TMP_DIR2")"/test.tar
+	[ "$status" -eq 0 ]
+	[[ ${lines[0]} == "Note: skipping $TEST_TMP_DIR2/link.tar, which is the same checkpoint as $TEST_TMP_DIR2/test.tar" ]]
+	[[ ${lines[1]} == "Note: skipping $TEST_TMP_DIR2/../"*"/test.tar, which is the same checkpoint as $TEST_TMP_DIR2/test.tar" ]]
+	[[ $(grep -c '^Displaying container checkpoint data' <<< "$output") -eq 1 ]]
+}
+
+@test "Run checkpointctl show with the same tar file twice and --allow-duplicates" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar "$TEST_TMP_DIR2"/test.tar --allow-duplicates
+	[ "$status" -eq 0 ]
+	[[ "$output" != *"Note: skipping"* ]]
+	[[ $(grep -c '^Displaying container checkpoint data' <<< "$output") -eq 2 ]]
+}
+
+@test "Run checkpointctl validate with the same tar file twice" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl validate "$TEST_TMP_DIR2"/test.tar "$TEST_TMP_DIR2"/test.tar -o json
+	[ "$status" -eq 0 ]
+	[[ ${lines[0]} == "Note: skipping $TEST_TMP_DIR2/test.tar, which is the same checkpoint as $TEST_TMP_DIR2/test.tar" ]]
+	[[ $(grep -c '"input":' <<< "$output") -eq 1 ]]
 }
 
 @test "Run checkpointctl show with tar file with empty config.dump" {
@@ -102,13 +208,24 @@ function teardown() {
 	[[ ${lines[4]} == *"containerd"* ]]
 }
 
+@test "Run checkpointctl show with tar file from containerd with invalid status" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	echo "invalid" > "$TEST_TMP_DIR1"/status
+	echo "{}" >  "$TEST_TMP_DIR1"/spec.dump
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == *"getting container checkpoint information failed: failed to unmarshal "*"/status: invalid character"* ]]
+}
+
 @test "Run checkpointctl show with tar file and --print-stats and missing stats-dump" {
 	cp test/config.dump "$TEST_TMP_DIR1"
 	cp test/spec.dump "$TEST_TMP_DIR1"
 	mkdir "$TEST_TMP_DIR1"/checkpoint
 	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
 	checkpointctl show "$TEST_TMP_DIR2"/test.tar --print-stats
-	[ "$status" -eq 1 ]
+	[ "$status" -eq 3 ]
 	[[ ${lines[6]} == *"unable to display checkpointing statistics"* ]]
 }
 
@@ -136,6 +253,140 @@ function teardown() {
 	[[ ${lines[10]} == *"446571 us"* ]]
 }
 
+@test "Run checkpointctl show with tar file and --print-stats and valid stats-dump and stats-restore" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	cp test/stats-dump "$TEST_TMP_DIR1"
+	cp test/stats-restore "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --print-stats
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == *"CRIU dump statistics"* ]]
+	[[ ${lines[10]} == *"446571 us"* ]]
+	[[ ${lines[12]} == *"CRIU restore statistics"* ]]
+	[[ ${lines[14]} == *"FORKING TIME"*"RESTORE TIME"*"PAGES RESTORED"* ]]
+	[[ ${lines[16]} == *"1542 us"*"312573 us"*"88689 |"* ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --print-stats --output json
+	[ "$status" -eq 0 ]
+	[[ "$output" == *'"dumpStatistics": {'* ]]
+	[[ "$output" == *'"restoreStatistics": {'*'"restore_time": 312573'* ]]
+}
+
+@test "Run checkpointctl show with tar file and --print-stats and only stats-restore" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	cp test/stats-restore "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --print-stats
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == *"CRIU restore statistics"* ]]
+	[[ ${output} != *"CRIU dump statistics"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --print-stats and invalid stats-restore" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	cp test/stats-dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"/stats-restore
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --print-stats
+	[ "$status" -eq 1 ]
+	[[ ${lines[6]} == *"unable to display restore statistics"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --print-stats and --locale" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	cp test/stats-dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --print-stats --locale en_US.UTF-8
+	[ "$status" -eq 0 ]
+	[[ ${lines[10]} == *"492,153 |"*"88,689 |"* ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --print-stats --locale de_DE.UTF-8
+	[ "$status" -eq 0 ]
+	[[ ${lines[10]} == *"492.153 |"*"88.689 |"* ]]
+	run env LC_ALL=de_CH.UTF-8 $CHECKPOINTCTL show "$TEST_TMP_DIR2"/test.tar --print-stats
+	[ "$status" -eq 0 ]
+	[[ ${lines[10]} == *"492'153 "*"88'689 "* ]]
+}
+
+@test "Run checkpointctl show with tar file and --raw" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	cp test/stats-dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --print-stats --shared-memory --locale en_US.UTF-8 --raw
+	[ "$status" -eq 0 ]
+	[[ ${lines[4]} != *" B "* ]]
+	[[ ${lines[10]} == *"Shared"*"73728"* ]]
+	[[ ${lines[18]} == *"492153 |"*"88689 |"* ]]
+}
+
+@test "Run checkpointctl show with tar file and unsupported --locale" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --locale xx_XX
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == *'unsupported locale "xx_XX"'* ]]
+}
+
+@test "Run checkpointctl show with tar file and --output json and --locale" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	head -c 8192 /dev/zero > "$TEST_TMP_DIR1"/checkpoint/pages-1.img
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar -o json --locale de_DE.UTF-8
+	[ "$status" -eq 0 ]
+	[[ "$output" == *'"checkpointSize": 8192'* ]]
+}
+
+@test "Run checkpointctl show with tar file and --stats-delta and missing stats-restore" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	cp test/stats-dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --stats-delta
+	[ "$status" -eq 3 ]
+	[[ ${lines[6]} == *"unable to display restore statistics"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --stats-delta and valid stats" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	cp test/stats-dump "$TEST_TMP_DIR1"
+	cp test/stats-restore "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --stats-delta
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == *"CRIU dump and restore statistics"* ]]
+	[[ ${lines[8]} == *"METRIC"*"DUMP"*"RESTORE"*"DELTA"* ]]
+	[[ ${lines[10]} == *"Pages"*"88689"*"88689"*"+0"* ]]
+	[[ "$output" != *"Time"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --stats-delta and stats-restore without restored pages" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	cp test/stats-dump "$TEST_TMP_DIR1"
+	cp test/stats-restore.no-pages-restored "$TEST_TMP_DIR1"/stats-restore
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --stats-delta
+	[ "$status" -eq 3 ]
+	[[ ${lines[6]} == *"restored pages in stats-restore not found in checkpoint"* ]]
+}
+
 @test "Run checkpointctl show with tar file and --mounts and valid spec.dump" {
 	cp test/config.dump "$TEST_TMP_DIR1"
 	cp test/spec.dump "$TEST_TMP_DIR1"
@@ -148,6 +399,41 @@ function teardown() {
 	[[ ${lines[10]} == *"/proc"* ]]
 }
 
+@test "Run checkpointctl show with tar file and --mounts and legacy spec.dump" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump.legacy "$TEST_TMP_DIR1"/spec.dump
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --mounts
+	[ "$status" -eq 0 ]
+	[[ ${lines[4]} == *"Podman"* ]]
+	[[ ${lines[10]} == *"/proc"* ]]
+	[[ ${lines[11]} == *"/data"*"bind"*"../srv/data"* ]]
+}
+
+@test "Run checkpointctl show with tar file and legacy spec.dump with rlimits and no process" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump.legacy.rlimits "$TEST_TMP_DIR1"/spec.dump
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	# The rlimits are moved to a new process object
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --validate-spec
+	[ "$status" -eq 0 ]
+	[[ "$output" == *"| process.rlimits[0] | soft limit 4096 exceeds hard limit 1024 |"* ]]
+}
+
+@test "Run checkpointctl share with tar file and legacy spec.dump" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump.legacy "$TEST_TMP_DIR1"/spec.dump
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl share "$TEST_TMP_DIR2"/test.tar --redact ip
+	[ "$status" -eq 0 ]
+	[[ "$output" == *'"/counter"'* ]]
+	[[ "$output" == *'"TERM=xterm"'* ]]
+	[[ "$output" == *'"source": "/srv/data"'* ]]
+}
+
 @test "Run checkpointctl show with tar file and --mounts and --full-paths and valid spec.dump" {
 	cp test/config.dump "$TEST_TMP_DIR1"
 	cp test/spec.dump "$TEST_TMP_DIR1"
@@ -189,3 +475,2691 @@ function teardown() {
 	[ "$status" -eq 0 ]
 	[[ ${lines[4]} == *"CRI-O"* ]]
 }
+
+@test "Run checkpointctl validate with tar file without descriptors.json" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl validate "$TEST_TMP_DIR2"/test.tar
+	[ "$status" -eq 0 ]
+	[[ ${lines[7]} == *"descriptors.json"*"SKIPPED"* ]]
+}
+
+@test "Run checkpointctl validate with tar file and no checkpoint directory" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl validate "$TEST_TMP_DIR2"/test.tar
+	[ "$status" -eq 1 ]
+	[[ ${lines[6]} == *"checkpoint"*"FAILED"*"not found"* ]]
+	[[ ${lines[7]} == *"rootfs-diff.tar"*"SKIPPED"*"not included in checkpoint"* ]]
+	[[ ${lines[9]} == *"1 of 1 checkpoint(s) failed validation"* ]]
+}
+
+@test "Run checkpointctl validate with tar file and matching descriptors.json" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/stats-dump "$TEST_TMP_DIR1"/checkpoint
+	echo '[{"name": "stats-dump", "size": 54}]' > "$TEST_TMP_DIR1"/checkpoint/descriptors.json
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl validate "$TEST_TMP_DIR2"/test.tar
+	[ "$status" -eq 0 ]
+	[[ ${lines[7]} == *"descriptors.json"*"OK"*"1 files"* ]]
+}
+
+@test "Run checkpointctl validate with tar file and --size-audit" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/stats-dump "$TEST_TMP_DIR1"/checkpoint
+	echo '[{"name": "stats-dump", "size": 54}]' > "$TEST_TMP_DIR1"/checkpoint/descriptors.json
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl validate "$TEST_TMP_DIR2"/test.tar --size-audit
+	[ "$status" -eq 0 ]
+	[[ ${lines[8]} == *"size audit"*"OK"*"on disk 54 B, declared 54 B, delta +0 B (+0.0%)"* ]]
+	echo '[{"name": "stats-dump", "size": 4096}]' > "$TEST_TMP_DIR1"/checkpoint/descriptors.json
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl validate "$TEST_TMP_DIR2"/test.tar --size-audit
+	[ "$status" -eq 1 ]
+	[[ ${lines[8]} == *"size audit"*"FAILED"*"on disk 54 B, declared 4.0 KiB, delta -3.9 KiB (-98.7%)"* ]]
+	checkpointctl validate "$TEST_TMP_DIR2"/test.tar
+	[ "$status" -eq 0 ]
+	[[ "$output" != *"size audit"* ]]
+}
+
+@test "Run checkpointctl validate with tar file and --size-audit without declared sizes" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/stats-dump "$TEST_TMP_DIR1"/checkpoint
+	echo '[{"name": "stats-dump"}]' > "$TEST_TMP_DIR1"/checkpoint/descriptors.json
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl validate "$TEST_TMP_DIR2"/test.tar --size-audit
+	[ "$status" -eq 0 ]
+	[[ ${lines[8]} == *"size audit"*"SKIPPED"*"descriptors.json declares no sizes"* ]]
+}
+
+@test "Run checkpointctl validate with tar file and descriptors.json of the OCI runtime" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/stats-dump "$TEST_TMP_DIR1"/checkpoint
+	echo '["/dev/null","pipe:[52487]","pipe:[52488]"]' > "$TEST_TMP_DIR1"/checkpoint/descriptors.json
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl validate "$TEST_TMP_DIR2"/test.tar --size-audit
+	[ "$status" -eq 0 ]
+	[[ ${lines[7]} == *"descriptors.json"*"SKIPPED"*"descriptors.json lists file descriptors, not images"* ]]
+	[[ ${lines[8]} == *"size audit"*"SKIPPED"*"descriptors.json lists file descriptors, not images"* ]]
+	[[ "$output" != *"cannot unmarshal"* ]]
+}
+
+@test "Run checkpointctl validate with tar file and invalid descriptors.json" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	echo '["/dev/null"' > "$TEST_TMP_DIR1"/checkpoint/descriptors.json
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl validate "$TEST_TMP_DIR2"/test.tar
+	[ "$status" -eq 1 ]
+	[[ ${lines[7]} == *"descriptors.json"*"FAILED"*"failed to unmarshal"* ]]
+}
+
+@test "Run checkpointctl validate with tar file and incomplete descriptors.json" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/stats-dump "$TEST_TMP_DIR1"/checkpoint
+	echo '[{"name": "pstree.img"}]' > "$TEST_TMP_DIR1"/checkpoint/descriptors.json
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl validate "$TEST_TMP_DIR2"/test.tar
+	[ "$status" -eq 1 ]
+	[[ ${lines[7]} == *"FAILED"*"missing: pstree.img; extra: stats-dump"* ]]
+}
+
+@test "Run checkpointctl validate with tar file and valid CRIU images" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl validate "$TEST_TMP_DIR2"/test.tar
+	[ "$status" -eq 0 ]
+	[[ ${lines[8]} == *"CRIU images"*"OK"*"14 images"* ]]
+}
+
+@test "Run checkpointctl validate with tar file and raw CRIU images" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* test/tmpfs/* test/compressed-pages/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl validate "$TEST_TMP_DIR2"/test.tar
+	[ "$status" -eq 0 ]
+	[[ ${lines[8]} == *"CRIU images"*"OK"*"17 images"* ]]
+}
+
+@test "Run checkpointctl validate with tar file and broken CRIU images" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	rm "$TEST_TMP_DIR1"/checkpoint/pstree.img
+	cp test/spec.dump "$TEST_TMP_DIR1"/checkpoint/fs-1.img
+	head -c 100 /dev/zero > "$TEST_TMP_DIR1"/checkpoint/pages-1.img
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl validate "$TEST_TMP_DIR2"/test.tar
+	[ "$status" -eq 1 ]
+	[[ ${lines[8]} == *"CRIU images"*"FAILED"*"fs-1.img: Unknown magic"* ]]
+	[[ ${lines[8]} == *"pages-1.img: size 100 is not a multiple of the page size"* ]]
+	[[ ${lines[8]} == *"missing: pstree.img"* ]]
+}
+
+@test "Run checkpointctl validate with tar file and rootfs-diff.tar" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint "$TEST_TMP_DIR1"/rootfs
+	head -c 3000 /dev/zero > "$TEST_TMP_DIR1"/rootfs/data
+	echo "nameserver 10.0.0.1" > "$TEST_TMP_DIR1"/rootfs/resolv.conf
+	( cd "$TEST_TMP_DIR1"/rootfs && tar czf "$TEST_TMP_DIR1"/rootfs-diff.tar data resolv.conf )
+	rm -r "$TEST_TMP_DIR1"/rootfs
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl validate "$TEST_TMP_DIR2"/test.tar
+	[ "$status" -eq 0 ]
+	[[ ${lines[9]} == *"rootfs-diff.tar"*"OK"*"2 entries, 2.9 KiB"* ]]
+	head -c 100 "$TEST_TMP_DIR1"/rootfs-diff.tar > "$TEST_TMP_DIR1"/truncated.tar
+	mv "$TEST_TMP_DIR1"/truncated.tar "$TEST_TMP_DIR1"/rootfs-diff.tar
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl validate "$TEST_TMP_DIR2"/test.tar
+	[ "$status" -eq 1 ]
+	[[ ${lines[9]} == *"rootfs-diff.tar"*"FAILED"*"unexpected EOF"* ]]
+}
+
+@test "Run checkpointctl validate with --only-invalid" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/valid.tar . )
+	rm -r "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/invalid.tar . )
+	echo "not a tar file" > "$TEST_TMP_DIR2"/broken.tar
+	checkpointctl validate --only-invalid "$TEST_TMP_DIR2"/valid.tar "$TEST_TMP_DIR2"/invalid.tar "$TEST_TMP_DIR2"/broken.tar
+	[ "$status" -eq 1 ]
+	[[ "$output" != *"/valid.tar"* ]]
+	[[ ${lines[0]} == *"invalid.tar"* ]]
+	[[ ${lines[9]} == *"broken.tar"* ]]
+	[[ ${lines[13]} == *"archive"*"FAILED"*"unpacking of checkpoint archive"* ]]
+	[[ ${lines[15]} == *"2 of 3 checkpoint(s) failed validation"* ]]
+}
+
+@test "Run checkpointctl validate with --only-invalid and only valid checkpoints" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl validate --only-invalid "$TEST_TMP_DIR2"/test.tar
+	[ "$status" -eq 0 ]
+	[[ "$output" == "" ]]
+}
+
+@test "Run checkpointctl share with tar file" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl share "$TEST_TMP_DIR2"/test.tar
+	[ "$status" -eq 0 ]
+	[[ "$output" == *'"engine": "Podman"'* ]]
+	[[ "$output" == *'"source": "***"'* ]]
+	[[ "$output" != *"overlay-containers"* ]]
+}
+
+@test "Run checkpointctl share with tar file and --redact and --output" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl share "$TEST_TMP_DIR2"/test.tar --redact=env -o "$TEST_TMP_DIR2"/bundle.json
+	[ "$status" -eq 0 ]
+	grep -q "overlay-containers" "$TEST_TMP_DIR2"/bundle.json
+}
+
+@test "Run checkpointctl share with tar file and process arguments" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	jq '.process.args = ["/usr/bin/counter", "--config=/etc/counter.conf", "--token=secret", "8088"]' \
+		test/spec.dump.process > "$TEST_TMP_DIR1"/spec.dump
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl share "$TEST_TMP_DIR2"/test.tar
+	[ "$status" -eq 0 ]
+	[[ $(echo "$output" | jq -c '.args') == '["***","--config=***","--token=***","8088"]' ]]
+	checkpointctl share "$TEST_TMP_DIR2"/test.tar --redact=path
+	[ "$status" -eq 0 ]
+	[[ $(echo "$output" | jq -c '.args') == '["***","--config=***","--token=secret","8088"]' ]]
+	checkpointctl share "$TEST_TMP_DIR2"/test.tar --redact=ip
+	[ "$status" -eq 0 ]
+	[[ $(echo "$output" | jq -c '.args') == '["/usr/bin/counter","--config=/etc/counter.conf","--token=secret","8088"]' ]]
+}
+
+@test "Run checkpointctl share with tar file and unsupported image format version" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	cp test/inventory.img.unsupported "$TEST_TMP_DIR1"/checkpoint/inventory.img
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl share "$TEST_TMP_DIR2"/test.tar
+	[ "$status" -eq 0 ]
+	[[ $(echo "$output" | jq -r '.engine') == "Podman" ]]
+	[[ $(echo "$output" | jq -r '.processTree') == "null" ]]
+	[[ $(echo "$output" | jq -r '.omitted[0]') == "processTree: CRIU image format version 3 is not supported"* ]]
+}
+
+@test "Run checkpointctl share with tar file and unknown --redact field" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl share "$TEST_TMP_DIR2"/test.tar --redact=foo
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == *'unknown redaction field "foo"'* ]]
+}
+
+@test "Run checkpointctl show with tar file and --timezone" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump.process "$TEST_TMP_DIR1"/spec.dump
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --timezone
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == *"Timezone and locale"* ]]
+	[[ ${lines[10]} == *"Europe/Berlin"*"yes (../Europe/Berlin)"*"de_DE.UTF-8"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --timezone without process" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --timezone
+	[ "$status" -eq 0 ]
+	[[ ${lines[10]} == *"| no "* ]]
+}
+
+@test "Run checkpointctl show with tar file and --required-features and missing inventory.img" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --required-features
+	[ "$status" -eq 1 ]
+	[[ ${lines[6]} == *"unable to display required CRIU features"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --required-features" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/inventory.img "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --required-features
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == *"Required CRIU restore options"* ]]
+	[[ ${lines[7]} == *"No additional CRIU restore options required"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --required-features and TCP connections and file locks" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/inventory.img test/images/file-locks.img "$TEST_TMP_DIR1"/checkpoint
+	touch "$TEST_TMP_DIR1"/checkpoint/tcp-stream-1.img
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --required-features
+	[ "$status" -eq 0 ]
+	[[ ${lines[10]} == *"--tcp-established"* ]]
+	[[ ${lines[11]} == *"--file-locks"* ]]
+	[[ ${lines[13]} == "criu restore --tcp-established --file-locks" ]]
+}
+
+@test "Run checkpointctl show with checkpoints of all container engines" {
+	# fixture | options | expected container details
+	while IFS='|' read -r fixture options expected; do
+		rm -rf "${TEST_TMP_DIR1:?}"/*
+		cp test/engines/"$fixture"/* "$TEST_TMP_DIR1"
+		mkdir "$TEST_TMP_DIR1"/checkpoint
+		( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/"$fixture".tar . )
+		# shellcheck disable=SC2086
+		checkpointctl show "$TEST_TMP_DIR2"/"$fixture".tar $options
+		[ "$status" -eq 0 ]
+		# shellcheck disable=SC2053
+		[[ ${lines[4]} == $expected ]]
+	done <<-EOT
+		podman||*counter*counter:latest*7eb9680287f1*crun*2023-03-01T10:00:00Z*Podman*10.88.0.5*92:d0:c6:0a:29:33*btrfs*
+		cri-o||*counter*a1b2c3d4e5f6*runc*2023-03-01T10:00:00.000000000Z*CRI-O*10.85.0.12,*fd00::c*0a:58:0a:55:00:0c*counter-pod*default*
+		containerd||*counter*0f1e2d3c4b5a*runc*2023-03-01T*containerd*counter-pod*kube-system*
+		unknown|--best-effort|*counter*ffeeddccbbaa*2023-03-01T10:00:00Z*unknown*
+	EOT
+}
+
+@test "Run checkpointctl show with tar file from unknown container manager" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	echo '{"annotations": {"io.container.manager": "custom-tool"}}' > "$TEST_TMP_DIR1"/spec.dump
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar
+	[ "$status" -eq 2 ]
+	[[ ${lines[0]} == *"unknown container manager found: custom-tool"* ]]
+}
+
+@test "Run checkpointctl show with tar file from unknown container manager and --best-effort" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	echo '{"annotations": {"io.container.manager": "custom-tool"}}' > "$TEST_TMP_DIR1"/spec.dump
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --best-effort
+	[ "$status" -eq 0 ]
+	[[ ${lines[4]} == *"unknown"* ]]
+	[[ ${lines[6]} == *"Annotations"* ]]
+	[[ ${lines[10]} == *"io.container.manager"*"custom-tool"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --proc-ids and missing pstree.img" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --proc-ids
+	[ "$status" -eq 3 ]
+	[[ ${lines[6]} == *"unable to display process IDs: pstree.img not found in checkpoint"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --proc-ids" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --proc-ids
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == *"Process IDs"* ]]
+	[[ ${lines[8]} == *"UMASK"* ]]
+	[[ ${lines[10]} == *"1 |    1 |   1 |  0022 | counter"* ]]
+	[[ ${lines[12]} == *"9 |    7 |   1 |  0077 | sleep"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --proc-ids and --pid" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --proc-ids --pid 9
+	[ "$status" -eq 0 ]
+	[[ ${lines[10]} == *"sleep"* ]]
+	[[ ${lines[11]} == "+-----+"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --hostname" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump.process "$TEST_TMP_DIR1"/spec.dump
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --hostname
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == *"Hostname"* ]]
+	[[ ${lines[8]} == *"SPEC HOSTNAME"*"RUNTIME HOSTNAME"*"CHANGED"* ]]
+	[[ ${lines[10]} == *"counter"*"counter-7d4b9"*"yes"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --hostname without UTS namespace" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump.process "$TEST_TMP_DIR1"/spec.dump
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	rm "$TEST_TMP_DIR1"/checkpoint/utsns-12.img
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --hostname
+	[ "$status" -eq 0 ]
+	[[ ${lines[10]} == *"counter"*"| -"*"| -"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --tty" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump.tty "$TEST_TMP_DIR1"/spec.dump
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* test/tty/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --tty
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == "Terminal" ]]
+	[[ ${lines[8]} == *"TERMINAL"*"CONSOLE SIZE"* ]]
+	[[ ${lines[10]} == *"yes"*"80x24"* ]]
+	[[ ${lines[12]} == "Captured terminals" ]]
+	[[ ${lines[14]} == *"ID"*"TYPE"*"DEVICE"*"SESSION"*"PROCESS GROUP"*"WINDOW SIZE"*"MODE"*"QUEUED DATA"* ]]
+	[[ ${lines[16]} == *"PTY"*"/dev/pts/0"*"80x24"*"canonical, echo"*"6 B"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --tty without tty images" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --tty
+	[ "$status" -eq 0 ]
+	[[ ${lines[10]} == *"no"*"-"* ]]
+	[[ ${lines[12]} == "No tty images found in checkpoint" ]]
+}
+
+@test "Run checkpointctl show with tar file and --shared-memory" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --shared-memory
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == *"Shared memory between processes"* ]]
+	[[ ${lines[10]} == *"Shared"*"2 |"*"4 |"*"72.0 KiB"* ]]
+	[[ ${lines[11]} == *"Private"*"4 |"*"4 |"*"32.0 KiB"* ]]
+	[[ ${lines[12]} == *"Total"*"6 |"*"8 |"*"104.0 KiB"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --shared-memory and missing mm.img" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	rm "$TEST_TMP_DIR1"/checkpoint/mm-7.img
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --shared-memory
+	[ "$status" -eq 1 ]
+	[[ ${lines[6]} == *"unable to display shared memory"*"mm-7.img"* ]]
+}
+
+@test "Run checkpointctl show with tar file and invalid --warn-size" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --warn-size=big
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == *"invalid value for --warn-size"* ]]
+}
+
+@test "Run checkpointctl show with tar file and exceeded --warn-size" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --warn-size=100B
+	[ "$status" -eq 0 ]
+	[[ "$output" == *"Warning: checkpoint size"*"exceeds threshold 100 B"* ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --warn-size=1MiB
+	[ "$status" -eq 0 ]
+	[[ "$output" != *"Warning"* ]]
+}
+
+@test "Run checkpointctl show with tar file and exceeded --warn-size and --strict" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --warn-size=100B --strict
+	[ "$status" -eq 1 ]
+	[[ "$output" == *"Error: checkpoint exceeds the configured thresholds"* ]]
+}
+
+@test "Run checkpointctl show with several tar files and exceeded --warn-size and --strict" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/small.tar . )
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/large.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/large.tar "$TEST_TMP_DIR2"/small.tar --warn-size=500B --strict
+	[ "$status" -eq 1 ]
+	[[ "$output" == *"Error: $TEST_TMP_DIR2/large.tar: checkpoint exceeds the configured thresholds"* ]]
+	[[ "$output" == *"Displaying container checkpoint data from $TEST_TMP_DIR2/small.tar"* ]]
+	[[ "$output" == *"Error: 1 of the 2 checkpoints failed --strict: checkpoint exceeds the configured thresholds"* ]]
+	checkpointctl show "$TEST_TMP_DIR2"/large.tar "$TEST_TMP_DIR2"/small.tar --warn-size=500B
+	[ "$status" -eq 0 ]
+}
+
+@test "Run checkpointctl show with tar file and --max-open-files" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* test/open-files/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	run bash -c "ulimit -n 64 && $CHECKPOINTCTL show $TEST_TMP_DIR2/test.tar --max-open-files"
+	[ "$status" -eq 0 ]
+	[[ "$output" == *"Warning: process 1 (counter) has 70 open files (highest file descriptor 69), the limit of open files on this host is 64"* ]]
+	[[ "$output" != *"process 7 (sh)"* ]]
+	[[ "$output" != *"process 9 (sleep)"* ]]
+	run bash -c "ulimit -n 64 && $CHECKPOINTCTL show $TEST_TMP_DIR2/test.tar --max-open-files --strict"
+	[ "$status" -eq 1 ]
+	[[ "$output" == *"Error: checkpoint exceeds the configured thresholds"* ]]
+	run bash -c "ulimit -n 128 && $CHECKPOINTCTL show $TEST_TMP_DIR2/test.tar --max-open-files --strict"
+	[ "$status" -eq 0 ]
+	[[ "$output" != *"Warning:"* ]]
+}
+
+@test "Run checkpointctl show with tar file and exceeded --warn-dump-time" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	cp test/stats-dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --warn-dump-time=1s
+	[ "$status" -eq 0 ]
+	[[ "$output" == *"Warning: checkpoint frozen time 1.376964s exceeds threshold 1s"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --check-mounts" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump.legacy "$TEST_TMP_DIR1"/spec.dump
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --check-mounts
+	[ "$status" -eq 0 ]
+	[[ "$output" == *"Warning: source /srv/data of bind mount /data does not exist"* ]]
+	[[ "$output" != *"/proc"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --check-mounts and container storage mounts" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --check-mounts
+	[ "$status" -eq 0 ]
+	[[ "$output" != *"Warning"* ]]
+}
+
+@test "Run checkpointctl show with split tar file" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar czf "$TEST_TMP_DIR2"/test.tar.gz . )
+	split -b 512 -a 3 --numeric-suffixes=1 "$TEST_TMP_DIR2"/test.tar.gz "$TEST_TMP_DIR2"/test.tar.gz.
+	rm "$TEST_TMP_DIR2"/test.tar.gz
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar.gz.001
+	[ "$status" -eq 0 ]
+	[[ ${lines[4]} == *"Podman"* ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar.gz
+	[ "$status" -eq 0 ]
+	[[ ${lines[4]} == *"Podman"* ]]
+}
+
+@test "Run checkpointctl show with split tar file and missing part" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar czf "$TEST_TMP_DIR2"/test.tar.gz . )
+	split -b 100 -a 3 --numeric-suffixes=1 "$TEST_TMP_DIR2"/test.tar.gz "$TEST_TMP_DIR2"/test.tar.gz.
+	rm "$TEST_TMP_DIR2"/test.tar.gz "$TEST_TMP_DIR2"/test.tar.gz.002
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar.gz.001
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == *"is incomplete: part $TEST_TMP_DIR2/test.tar.gz.002 is missing"* ]]
+}
+
+@test "Run checkpointctl show with unreachable checkpoint image" {
+	checkpointctl show localhost:1/checkpoint:latest
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == *"failed to pull localhost:1/checkpoint:latest"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --output json" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --output json
+	[ "$status" -eq 0 ]
+	[[ ${lines[0]} == "{" ]]
+	[[ "$output" == *'"engine": "Podman"'* ]]
+	[[ "$output" != *'"mounts"'* ]]
+}
+
+@test "Run checkpointctl show with tar file and --print-stats and --output json" {
+	cp test/engines/podman/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	cp test/stats-dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --print-stats --output json
+	[ "$status" -eq 0 ]
+	[[ "$output" == *'"id": "7eb9680287f1f3ad4b6c2d1f8e3e2f7b9f0c1a2b3c4d5e6f708192a3b4c5d6e7"'* ]]
+	[[ "$output" == *'"shortId": "7eb9680287f1"'* ]]
+	[[ "$output" == *'"dumpStatistics": {'*'"memwrite_time": 446571'* ]]
+	[[ "$output" != *"Displaying container checkpoint data"* ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --print-stats --output logfmt
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == *"--output logfmt does not support --print-stats"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --mounts and --output json" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --mounts -o json
+	[ "$status" -eq 0 ]
+	[[ "$output" == *'"mounts": ['* ]]
+	[[ "$output" == *'"destination": "/proc"'* ]]
+	[[ "$output" == *'"source": "../userdata/hostname"'* ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --mounts --full-paths -o json
+	[ "$status" -eq 0 ]
+	[[ "$output" == *'"source": "/run/containers/storage/overlay-containers/'* ]]
+}
+
+@test "Run checkpointctl show with tar file and unsupported --output" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --output xml
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == *'unsupported output format "xml"'* ]]
+}
+
+@test "Run checkpointctl show with tar file (CRI-O) and --pod-map" {
+	echo '{"id": "7eb9680287f1a99d1f5ebd4ba8f2ab72fb5c54da1b3ae84a9159ca5e31d32dd0"}' > "$TEST_TMP_DIR1"/config.dump
+	cp test/spec.dump.cri-o "$TEST_TMP_DIR1"/spec.dump
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	printf '7eb9680287f1:\n  pod: counters\n  namespace: default\n' > "$TEST_TMP_DIR2"/pods.yaml
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --pod-map "$TEST_TMP_DIR2"/pods.yaml
+	[ "$status" -eq 0 ]
+	[[ ${lines[2]} == *"POD"*"NAMESPACE"* ]]
+	[[ ${lines[4]} == *"counters"*"default"* ]]
+	echo '{"0123456789ab": {"pod": "other"}}' > "$TEST_TMP_DIR2"/pods.json
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --pod-map "$TEST_TMP_DIR2"/pods.json
+	[ "$status" -eq 0 ]
+	[[ ${lines[2]} != *"POD"* ]]
+}
+
+@test "Run checkpointctl show with tar file (CRI-O) and --pod-map with overlapping prefixes" {
+	echo '{"id": "7eb9680287f1a99d1f5ebd4ba8f2ab72fb5c54da1b3ae84a9159ca5e31d32dd0"}' > "$TEST_TMP_DIR1"/config.dump
+	cp test/spec.dump.cri-o "$TEST_TMP_DIR1"/spec.dump
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	printf '7eb:\n  pod: short\n7eb968:\n  pod: long\n7e:\n  pod: shorter\n' > "$TEST_TMP_DIR2"/pods.yaml
+	# The map is iterated in random order, the longest prefix must always win
+	for _ in 1 2 3 4 5; do
+		checkpointctl show "$TEST_TMP_DIR2"/test.tar --pod-map "$TEST_TMP_DIR2"/pods.yaml
+		[ "$status" -eq 0 ]
+		[[ ${lines[4]} == *"| long "* ]]
+	done
+	printf '7eb968:\n  pod: long\n7eb9680287f1a99d1f5ebd4ba8f2ab72fb5c54da1b3ae84a9159ca5e31d32dd0:\n  pod: exact\n' > "$TEST_TMP_DIR2"/pods.yaml
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --pod-map "$TEST_TMP_DIR2"/pods.yaml
+	[ "$status" -eq 0 ]
+	[[ ${lines[4]} == *"| exact "* ]]
+}
+
+@test "Run checkpointctl show with tar file and invalid --pod-map" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump.cri-o "$TEST_TMP_DIR1"/spec.dump
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	echo '[1, 2' > "$TEST_TMP_DIR2"/pods.yaml
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --pod-map "$TEST_TMP_DIR2"/pods.yaml
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == *"failed to parse pod mapping"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --proc-ids and unsupported image format version" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	cp test/inventory.img.unsupported "$TEST_TMP_DIR1"/checkpoint/inventory.img
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --proc-ids
+	[ "$status" -eq 1 ]
+	[[ ${lines[6]} == *"CRIU image format version 3 is not supported by this build of checkpointctl (supported: 1-2)"* ]]
+}
+
+@test "Run checkpointctl restore-hint with Podman checkpoint" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump.process "$TEST_TMP_DIR1"/spec.dump
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl restore-hint "$TEST_TMP_DIR2"/test.tar
+	[ "$status" -eq 0 ]
+	[[ ${lines[0]} == "Suggested restore command for Podman checkpoint $TEST_TMP_DIR2/test.tar" ]]
+	[[ ${lines[1]} == "podman container restore --import $TEST_TMP_DIR2/test.tar --file-locks" ]]
+	[[ ${lines[3]} == *"bind mount source /usr/share/zoneinfo/Europe/Berlin (mounted at /etc/localtime)"* ]]
+}
+
+@test "Run checkpointctl restore-hint with CRI-O checkpoint" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump.cri-o "$TEST_TMP_DIR1"/spec.dump
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl restore-hint "$TEST_TMP_DIR2"/test.tar
+	[ "$status" -eq 0 ]
+	[[ ${lines[0]} == *"for CRI-O checkpoint"* ]]
+	[[ ${lines[1]} == "crictl create <POD_ID> container-config.json pod-config.json" ]]
+	[[ ${lines[3]} == *"set the image of the container in container-config.json to $TEST_TMP_DIR2/test.tar"* ]]
+	[[ ${lines[4]} == *"CRIU option --file-locks (file locks) has to be set"* ]]
+}
+
+@test "Run checkpointctl restore-hint with tar file and missing CRIU images" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl restore-hint "$TEST_TMP_DIR2"/test.tar
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == *"unable to suggest a restore command"* ]]
+}
+
+@test "Run checkpointctl diff with identical checkpoints" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl diff --stat "$TEST_TMP_DIR2"/test.tar "$TEST_TMP_DIR2"/test.tar
+	[ "$status" -eq 0 ]
+	[[ ${lines[1]} == "No differences found" ]]
+}
+
+@test "Run checkpointctl diff with --stat" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/before.tar . )
+	cp test/spec.dump.process "$TEST_TMP_DIR1"/spec.dump
+	head -c 2048 /dev/zero > "$TEST_TMP_DIR1"/checkpoint/pages-1.img
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/after.tar . )
+	checkpointctl diff --stat "$TEST_TMP_DIR2"/before.tar "$TEST_TMP_DIR2"/after.tar
+	[ "$status" -eq 0 ]
+	[[ ${lines[1]} == "1 mount added, 1 mount removed, size +2.0 KiB, 4 env vars added" ]]
+	[[ ${lines[3]} == *"FIELD"*"before.tar"*"after.tar"* ]]
+	[[ ${lines[5]} == *"CHKPT Size"*"0 B"*"2.0 KiB"* ]]
+	[[ ${lines[7]} == "Mounts" ]]
+	[[ ${lines[11]} == *"/etc/localtime"*"added"* ]]
+	[[ ${lines[12]} == *"/etc/hostname"*"removed"* ]]
+	[[ ${lines[14]} == "Environment variables" ]]
+	[[ ${lines[18]} == *"HOSTNAME"*"added"* ]]
+}
+
+@test "Run checkpointctl diff without --stat" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/before.tar . )
+	cp test/spec.dump.process "$TEST_TMP_DIR1"/spec.dump
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/after.tar . )
+	checkpointctl diff "$TEST_TMP_DIR2"/before.tar "$TEST_TMP_DIR2"/after.tar
+	[ "$status" -eq 0 ]
+	[[ ${lines[1]} == "Mounts" ]]
+	[[ "$output" != *"added,"* ]]
+}
+
+@test "Run checkpointctl diff with --ps-tree" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/before.tar . )
+	cp test/ps-tree/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/after.tar . )
+	checkpointctl diff --ps-tree --stat "$TEST_TMP_DIR2"/before.tar "$TEST_TMP_DIR2"/after.tar
+	[ "$status" -eq 0 ]
+	[[ ${lines[1]} == *"1 process added, 1 process changed" ]]
+	[[ ${lines[7]} == "Processes" ]]
+	[[ ${lines[11]} == *"counter/nginx (PID 10)"*"added"* ]]
+	[[ ${lines[12]} == *"counter/sh/tail (PID 9, was sleep)"*"changed"* ]]
+	checkpointctl diff --ps-tree -o json "$TEST_TMP_DIR2"/after.tar "$TEST_TMP_DIR2"/before.tar
+	[ "$status" -eq 0 ]
+	[[ "$output" == *'"removed": ['*'"counter/nginx (PID 10)"'* ]]
+	checkpointctl diff --ps-tree "$TEST_TMP_DIR2"/before.tar "$TEST_TMP_DIR2"/before.tar
+	[ "$status" -eq 0 ]
+	[[ ${lines[1]} == "No differences found" ]]
+	checkpointctl diff -o json "$TEST_TMP_DIR2"/before.tar "$TEST_TMP_DIR2"/after.tar
+	[ "$status" -eq 0 ]
+	[[ "$output" != *'"processes"'* ]]
+}
+
+@test "Run checkpointctl diff with --ps-tree and no CRIU images" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl diff --ps-tree "$TEST_TMP_DIR2"/test.tar "$TEST_TMP_DIR2"/test.tar
+	[ "$status" -eq 3 ]
+	[[ ${lines[0]} == *"unable to compare process trees: pstree.img not found in checkpoint"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --mem-tracking" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --mem-tracking
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == "Memory change tracking" ]]
+	[[ ${lines[10]} == *"unknown"*"no parent images and no dump statistics found"* ]]
+	cp test/stats-dump "$TEST_TMP_DIR1"
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --mem-tracking
+	[ "$status" -eq 0 ]
+	[[ ${lines[10]} == *"disabled"*"no pages were taken from a previous checkpoint"* ]]
+	cp test/stats-dump.pre-copy "$TEST_TMP_DIR1"/stats-dump
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --mem-tracking
+	[ "$status" -eq 0 ]
+	[[ ${lines[10]} == *"enabled"*"80312 unchanged pages were taken from the previous checkpoint"* ]]
+}
+
+@test "Run checkpointctl show with tar file with parent images and --mem-tracking" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint "$TEST_TMP_DIR1"/pre-checkpoint
+	ln -s ../pre-checkpoint "$TEST_TMP_DIR1"/checkpoint/parent
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --mem-tracking -o json
+	[ "$status" -eq 0 ]
+	[[ "$output" == *'"memoryTracking": "enabled"'* ]]
+}
+
+@test "Run checkpointctl show with tar file and --duration" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --duration
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == "Checkpoint duration" ]]
+	[[ ${lines[10]} == *"unknown"*"no dump statistics found"* ]]
+	cp test/stats-dump "$TEST_TMP_DIR1"
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --duration
+	[ "$status" -eq 0 ]
+	[[ ${lines[10]} == *"1.482369s"*"freezing time + frozen time"* ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --duration -o json
+	[ "$status" -eq 0 ]
+	[[ "$output" == *'"duration": "1.482369s"'* ]]
+}
+
+@test "Run checkpointctl show with tar file and --output yaml" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar -o yaml
+	[ "$status" -eq 0 ]
+	[[ "$output" == *"engine: Podman"* ]]
+}
+
+@test "Run checkpointctl validate with tar files and --output json" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/valid.tar . )
+	rm "$TEST_TMP_DIR1"/spec.dump
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/invalid.tar . )
+	checkpointctl validate "$TEST_TMP_DIR2"/valid.tar "$TEST_TMP_DIR2"/invalid.tar -o json
+	[ "$status" -eq 1 ]
+	[[ "$output" == *'"input": "'"$TEST_TMP_DIR2"'/valid.tar",'*'"valid": true'* ]]
+	[[ "$output" == *'"input": "'"$TEST_TMP_DIR2"'/invalid.tar",'*'"valid": false'* ]]
+	[[ "$output" == *'"status": "FAILED"'* ]]
+	[[ "$output" == *"1 of 2 checkpoint(s) failed validation"* ]]
+}
+
+@test "Run checkpointctl validate with tar file and --output yaml" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl validate "$TEST_TMP_DIR2"/test.tar -o yaml
+	[ "$status" -eq 0 ]
+	[[ ${lines[0]} == "- checks:" ]]
+	[[ "$output" == *"valid: true"* ]]
+}
+
+@test "Run checkpointctl diff with --output json" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/before.tar . )
+	cp test/spec.dump.process "$TEST_TMP_DIR1"/spec.dump
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/after.tar . )
+	checkpointctl diff --stat -o json "$TEST_TMP_DIR2"/before.tar "$TEST_TMP_DIR2"/after.tar
+	[ "$status" -eq 0 ]
+	[[ "$output" == *'"identical": false'* ]]
+	[[ "$output" == *'"stat": "1 mount added, 1 mount removed, 4 env vars added"'* ]]
+	[[ "$output" == *'"added": ['*'"/etc/localtime"'* ]]
+}
+
+@test "Run checkpointctl diff with --output yaml and identical checkpoints" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl diff -o yaml "$TEST_TMP_DIR2"/test.tar "$TEST_TMP_DIR2"/test.tar
+	[ "$status" -eq 0 ]
+	[[ "$output" == *"identical: true"* ]]
+}
+
+@test "Run checkpointctl validate and diff with unsupported --output" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl validate "$TEST_TMP_DIR2"/test.tar -o xml
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == *'unsupported output format "xml" (supported: table, json, yaml, logfmt)'* ]]
+	checkpointctl diff "$TEST_TMP_DIR2"/test.tar "$TEST_TMP_DIR2"/test.tar -o xml
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == *'unsupported output format "xml"'* ]]
+}
+
+@test "Run checkpointctl show with tar file and --mac" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump.mac "$TEST_TMP_DIR1"/spec.dump
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --mac
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == "Mandatory access control" ]]
+	[[ ${lines[10]} == *"checkpointctl-test-profile"*"system_u:system_r:container_t:s0:c100,c200"* ]]
+	[[ "$output" != *"Warning"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --check-apparmor" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump.mac "$TEST_TMP_DIR1"/spec.dump
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --check-apparmor
+	[ "$status" -eq 0 ]
+	[[ "$output" == *"Warning: AppArmor profile checkpointctl-test-profile"* ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --check-apparmor -o json
+	[ "$status" -eq 0 ]
+	[[ "$output" == *"Warning: AppArmor profile checkpointctl-test-profile"* ]]
+}
+
+@test "Run checkpointctl show with tar file without AppArmor profile and --check-apparmor" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --check-apparmor
+	[ "$status" -eq 0 ]
+	[[ "$output" != *"Warning"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --processes" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --processes
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == "Processes" ]]
+	[[ ${lines[8]} == *"PID"*"PPID"*"COMMAND"*"THREADS"*"STATE"*"MEMORY"* ]]
+	[[ ${lines[10]} == *"1 |    0 | counter |       1 | running | 88.0 KiB"* ]]
+	[[ ${lines[11]} == *"7 |    1 | sh      |       2 | running | 72.0 KiB"* ]]
+	[[ ${lines[12]} == *"9 |    7 | sleep   |       1 | running | 16.0 KiB"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --proc-filter" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --processes --proc-filter count
+	[ "$status" -eq 0 ]
+	[[ ${lines[10]} == *"1 |    0 | counter |"* ]]
+	[[ ${lines[11]} == "+-"* ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --processes --ps-tree --proc-filter '^s(h|leep)$'
+	[ "$status" -eq 0 ]
+	[[ ${lines[10]} == *"7 |    1 | sh "* ]]
+	[[ ${lines[11]} == *"9 |    7 | sleep "* ]]
+	[[ ${lines[13]} == "Process tree" ]]
+	# The ancestors of matching processes are kept in the tree
+	[[ ${lines[14]} == "1 counter (1 thread)" ]]
+	[[ ${lines[15]} == "└── 7 sh (2 threads)" ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --ps-tree --proc-filter nginx
+	[ "$status" -eq 0 ]
+	[[ ${lines[7]} == "No processes match --proc-filter" ]]
+	# Values which are not a valid regular expression match as plain strings
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --ps-tree --proc-filter 'c++'
+	[ "$status" -eq 0 ]
+	[[ ${lines[7]} == "No processes match --proc-filter" ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --ps-tree --proc-filter 'slee('
+	[ "$status" -eq 0 ]
+	[[ ${lines[7]} == "No processes match --proc-filter" ]]
+}
+
+@test "Run checkpointctl show with tar file and --processes and --sort-by memory" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	rm "$TEST_TMP_DIR1"/checkpoint/mm-1.img
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --processes --sort-by memory
+	[ "$status" -eq 0 ]
+	[[ ${lines[10]} == *"7 |"*"sh"* ]]
+	[[ ${lines[11]} == *"9 |"*"sleep"* ]]
+	[[ ${lines[12]} == *"1 |"*"counter"*"-"* ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --processes --pid 9
+	[ "$status" -eq 0 ]
+	[[ ${lines[10]} == *"sleep"* ]]
+	[[ ${lines[11]} == "+-----+"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --processes and unsupported --sort-by" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --processes --sort-by name
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == *'unsupported sort order "name" (supported: pid, memory)'* ]]
+}
+
+@test "Run checkpointctl show with tar file and --env and --cmd" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump.env "$TEST_TMP_DIR1"/spec.dump
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --env --cmd
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == "Environment variables" ]]
+	[[ ${lines[10]} == *"PATH"*"/usr/bin:/bin"* ]]
+	[[ ${lines[11]} == *"CERT"*"| $(printf 'A%.0s' {1..80})... |" ]]
+	[[ ${lines[12]} == *"COLOR"*'\x1b[31mred\x1b[0m'* ]]
+	[[ ${lines[15]} == "Command line" ]]
+	[[ ${lines[21]} == *"2 | echo one\necho two"* ]]
+	[[ ${lines[23]} == "Working directory: /srv/app" ]]
+	[[ "$output" != *$'\x1b'* ]]
+}
+
+@test "Run checkpointctl show with tar file and --env and --max-value-len" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump.env "$TEST_TMP_DIR1"/spec.dump
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --env --max-value-len 10
+	[ "$status" -eq 0 ]
+	[[ ${lines[11]} == *"CERT"*"| AAAAAAAAAA... |" ]]
+	[[ ${lines[12]} == *"COLOR"*'| \x1b[31mre... |' ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --env --no-truncate
+	[ "$status" -eq 0 ]
+	[[ ${lines[11]} == *"| $(printf 'A%.0s' {1..200}) |" ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --env --max-value-len 0
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == *"--max-value-len must be at least 1"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --all" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	cp test/stats-dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --all --pid 9
+	[ "$status" -eq 0 ]
+	[[ "$output" == *"Overview of Mounts"* ]]
+	[[ "$output" == *"Environment variables"* ]]
+	[[ "$output" == *"Required CRIU restore options"* ]]
+	[[ "$output" == *"Processes"*"|   9 |    7 | sleep   |"* ]]
+	[[ "$output" != *"| counter |"* ]]
+	[[ "$output" == *"CRIU dump statistics"* ]]
+	[[ "$output" == *"Warning: unable to display restore statistics"* ]]
+	[[ "$output" == *"Checkpoint duration"* ]]
+}
+
+@test "Run checkpointctl show with tar file without CRIU images and --all" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --all
+	[ "$status" -eq 0 ]
+	[[ "$output" == *"Warning: unable to display process IDs"* ]]
+	[[ "$output" == *"Memory change tracking"* ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --all -o json
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == *"--output json does not support --all"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --dump-log" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	cp test/dump.log "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --dump-log
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == "CRIU dump log" ]]
+	[[ ${lines[7]} == "(00.000000) Will allow link remaps on FUSE" ]]
+	[[ ${lines[17]} == "dump.log: 1 error, 1 warning" ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --dump-log --dump-log-lines 3
+	[ "$status" -eq 0 ]
+	[[ ${lines[7]} == *"Error (criu/files-reg.c:1718)"* ]]
+	[[ ${lines[10]} == "dump.log: 1 error, 1 warning (last 3 of 10 lines shown)" ]]
+}
+
+@test "Run checkpointctl show with tar file with dump.log in the images directory and --dump-log" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/dump.log "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --dump-log --dump-log-lines 1
+	[ "$status" -eq 0 ]
+	[[ ${lines[7]} == "(00.051337) Dumping finished successfully" ]]
+}
+
+@test "Run checkpointctl show with tar file without dump.log and --dump-log" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --dump-log
+	[ "$status" -eq 0 ]
+	[[ ${lines[7]} == "No dump.log included in checkpoint" ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --dump-log --dump-log-lines -1
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == *"--dump-log-lines must not be negative"* ]]
+}
+
+@test "Run checkpointctl drift with Pod manifest" {
+	cp test/engines/cri-o/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump.process "$TEST_TMP_DIR1"/spec.dump
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl drift "$TEST_TMP_DIR2"/test.tar test/pod.yaml
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == *"contains multiple containers, select one with --container"* ]]
+	checkpointctl drift "$TEST_TMP_DIR2"/test.tar test/pod.yaml --container counter
+	[ "$status" -eq 0 ]
+	[[ ${lines[0]} == *"with container counter of test/pod.yaml" ]]
+	[[ ${lines[4]} == *"Command"*"/usr/bin/counter --port 8080"*"/usr/bin/counter --port 8088"* ]]
+	[[ ${lines[5]} == *"Env TZ"*"UTC"*"Europe/Berlin"* ]]
+	[[ ${lines[6]} == *"Env API_TOKEN"*"(valueFrom)"*"-"* ]]
+	[[ ${lines[7]} == *"Mount /etc/localtime"*"read-only"*"read-write"* ]]
+	[[ ${lines[8]} == *"Mount /data"*"mounted"*"-"* ]]
+	[[ ${lines[9]} == *"Memory Limit"*"128.0 MiB"*"-"* ]]
+	[[ ${lines[10]} == *"CPU Limit"*"0.5 CPUs"*"-"* ]]
+	[[ "$output" != *"Image"* ]]
+}
+
+@test "Run checkpointctl drift with container manifest" {
+	cp test/engines/cri-o/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump.process "$TEST_TMP_DIR1"/spec.dump
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl drift "$TEST_TMP_DIR2"/test.tar test/container.yaml
+	[ "$status" -eq 0 ]
+	[[ ${lines[1]} == "No divergences found" ]]
+}
+
+@test "Run checkpointctl drift with --output json" {
+	cp test/engines/cri-o/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump.process "$TEST_TMP_DIR1"/spec.dump
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl drift "$TEST_TMP_DIR2"/test.tar test/pod.yaml --container sidecar -o json
+	[ "$status" -eq 0 ]
+	[[ "$output" == *'"diverged": true'* ]]
+	[[ "$output" == *'"field": "Image"'*'"declared": "registry.example.com/sidecar:1.0"'* ]]
+	checkpointctl drift "$TEST_TMP_DIR2"/test.tar test/pod.yaml --container missing
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == *'container "missing" not found in manifest test/pod.yaml'* ]]
+}
+
+@test "Run checkpointctl show with tar file and storage driver" {
+	cp test/engines/podman/* "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar
+	[ "$status" -eq 0 ]
+	[[ ${lines[2]} == *"STORAGE DRIVER"* ]]
+	[[ ${lines[4]} == *"btrfs"* ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar -o json
+	[ "$status" -eq 0 ]
+	[[ "$output" == *'"storageDriver": "btrfs"'* ]]
+}
+
+@test "Run checkpointctl show with tar file and storage driver not recorded" {
+	cp test/engines/cri-o/* "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	# The storage driver is not derived from the paths of the rootfs
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar
+	[ "$status" -eq 0 ]
+	[[ ${lines[2]} != *"STORAGE DRIVER"* ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar -o json
+	[ "$status" -eq 0 ]
+	[[ "$output" == *'"storageDriver": "unknown"'* ]]
+}
+
+@test "Run checkpointctl show with tar file and thread count" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar
+	[ "$status" -eq 0 ]
+	[[ ${lines[2]} == *"THREADS"* ]]
+	[[ ${lines[4]} == *"|       4 |"* ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar -o json
+	[ "$status" -eq 0 ]
+	[[ "$output" == *'"threads": 4'* ]]
+	rm "$TEST_TMP_DIR1"/checkpoint/pstree.img
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar
+	[ "$status" -eq 0 ]
+	[[ ${lines[2]} != *"THREADS"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --proc-ids and CRIU image not supported by crit" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	printf '\x19\x43\x56\x54\xef\xbe\xad\xde' > "$TEST_TMP_DIR1"/checkpoint/pstree.img
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --proc-ids
+	[ "$status" -eq 1 ]
+	[[ ${lines[6]} == *"pstree.img has an image type which is not supported by the embedded crit library"* ]]
+	[[ ${lines[6]} == *"a build of checkpointctl with a newer go-criu version is required"* ]]
+	cp test/dump.log "$TEST_TMP_DIR1"
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --proc-ids
+	[ "$status" -eq 1 ]
+	[[ ${lines[6]} == *"the checkpoint was created with CRIU 3.17.1"* ]]
+}
+
+@test "Run checkpointctl validate with tar file and CRIU image not supported by crit" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	printf '\x19\x43\x56\x54\xef\xbe\xad\xde' > "$TEST_TMP_DIR1"/checkpoint/new-1.img
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl validate "$TEST_TMP_DIR2"/test.tar
+	[ "$status" -eq 1 ]
+	[[ ${lines[8]} == *"CRIU images"*"FAILED"*"new-1.img has an image type which is not supported by the embedded crit library"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --output logfmt" {
+	cp test/engines/cri-o/config.dump "$TEST_TMP_DIR1"
+	cp test/engines/cri-o/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	head -c 8192 /dev/zero > "$TEST_TMP_DIR1"/checkpoint/pages-1.img
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar -o logfmt --locale de_DE.UTF-8
+	[ "$status" -eq 0 ]
+	[ "${#lines[@]}" -eq 1 ]
+	[[ ${lines[0]} == "container=counter id=a1b2c3d4e5f6"*" engine=CRI-O "*" pod=counter-pod namespace=default "*' size="8.0 KiB"' ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar -o logfmt --raw
+	[ "$status" -eq 0 ]
+	[[ ${lines[0]} == *" size=8192" ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar -o logfmt --mounts
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == *"--output logfmt does not support --mounts"* ]]
+}
+
+@test "Run checkpointctl validate with tar files and --output logfmt" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/valid.tar . )
+	rm "$TEST_TMP_DIR1"/spec.dump
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/invalid.tar . )
+	checkpointctl validate "$TEST_TMP_DIR2"/valid.tar "$TEST_TMP_DIR2"/invalid.tar -o logfmt
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == "input=$TEST_TMP_DIR2/valid.tar valid=true config.dump=OK spec.dump=OK checkpoint=OK descriptors.json=SKIPPED criu_images=SKIPPED rootfs-diff.tar=SKIPPED" ]]
+	[[ ${lines[1]} == "input=$TEST_TMP_DIR2/invalid.tar valid=false "*"spec.dump=FAILED"*' problems="spec.dump: '* ]]
+	[[ ${lines[2]} == *"1 of 2 checkpoint(s) failed validation"* ]]
+}
+
+@test "Run checkpointctl diff with --output logfmt" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl diff "$TEST_TMP_DIR2"/test.tar "$TEST_TMP_DIR2"/test.tar -o logfmt
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == *'unsupported output format "logfmt" (supported: table, json, yaml)'* ]]
+}
+
+@test "Run checkpointctl show with tar file and --sched" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --sched
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == "Scheduling" ]]
+	[[ ${lines[8]} == *"POLICY"*"NICE"*"PRIORITY"* ]]
+	[[ ${lines[10]} == *"1 | counter | SCHED_OTHER |    0 |        0 |" ]]
+	[[ ${lines[11]} == *"7 | sh      | SCHED_OTHER |    5 |        0 |" ]]
+	[[ ${lines[12]} == *"9 | sleep   | SCHED_RR    |    0 |       10 |" ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --sched --pid 9
+	[ "$status" -eq 0 ]
+	[[ ${lines[10]} == *"sleep"*"SCHED_RR"* ]]
+	[[ ${lines[11]} == "+-----+"* ]]
+}
+
+@test "Run checkpointctl preflight with tar file" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump.external "$TEST_TMP_DIR1"/spec.dump
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/inventory.img "$TEST_TMP_DIR1"/checkpoint
+	cp test/unixsk.img.extern "$TEST_TMP_DIR1"/checkpoint/unixsk.img
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl preflight "$TEST_TMP_DIR2"/test.tar
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == *"Verifying external references of container checkpoint"* ]]
+	[[ ${lines[4]} == *"Bind mount  | /srv/data          | FAILED  | mounted at /data: does not exist"* ]]
+	[[ ${lines[5]} == *"Bind mount  | /etc/hosts         | OK      | mounted at /etc/host-hosts"* ]]
+	[[ ${lines[6]} == *"Unix socket | /run/ext/host.sock | FAILED  | does not exist"* ]]
+	[[ ${lines[7]} == *"Unix socket | @ext-abstract      | SKIPPED | abstract socket, cannot be verified"* ]]
+	[[ ${lines[8]} == *"Device      | /dev/null          | OK      | char 1:3"* ]]
+	[[ ${lines[9]} == *"Device      | /dev/nvidia0       | FAILED  | char 195:0: does not exist"* ]]
+	[[ ${lines[11]} == *"Error: 3 of 6 external reference(s) missing"* ]]
+}
+
+@test "Run checkpointctl preflight with tar file and --best-effort and --output json" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump.external "$TEST_TMP_DIR1"/spec.dump
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl preflight "$TEST_TMP_DIR2"/test.tar --best-effort -o json
+	[ "$status" -eq 0 ]
+	[[ "$output" == *'"ready": false'* ]]
+	[[ "$output" == *'"reference": "/dev/nvidia0",'*'"status": "FAILED"'* ]]
+	[[ "$output" == *"Warning: 2 of 4 external reference(s) missing"* ]]
+}
+
+@test "Run checkpointctl preflight with tar file without external references" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl preflight "$TEST_TMP_DIR2"/test.tar
+	[ "$status" -eq 0 ]
+	[[ ${lines[1]} == "No external references found" ]]
+}
+
+@test "Run checkpointctl show with tar file and --mem-usage" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump.env "$TEST_TMP_DIR1"/spec.dump
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	head -c 1048576 /dev/zero > "$TEST_TMP_DIR1"/checkpoint/pages-1.img
+	head -c 1048576 /dev/zero > "$TEST_TMP_DIR1"/checkpoint/pages-2.img
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --mem-usage
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == "Memory usage" ]]
+	[[ ${lines[8]} == *"MEMORY"*"LIMIT"*"USAGE"* ]]
+	[[ ${lines[10]} == *"2.0 MiB"*"16.0 MiB"*"12.5%"* ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --mem-usage -o json
+	[ "$status" -eq 0 ]
+	[[ "$output" == *'"memoryUsage": {'*'"size": 2097152,'*'"limit": 16777216,'*'"percent": 12.5'* ]]
+}
+
+@test "Run checkpointctl show with tar file and --mem-usage without memory limit" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump.legacy "$TEST_TMP_DIR1"/spec.dump
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	head -c 8192 /dev/zero > "$TEST_TMP_DIR1"/checkpoint/pages-1.img
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --mem-usage
+	[ "$status" -eq 0 ]
+	[[ ${lines[10]} == *"8.0 KiB"*"unlimited"*"-"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --image-sizes" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	head -c 8192 /dev/zero > "$TEST_TMP_DIR1"/checkpoint/pages-1.img
+	head -c 4096 /dev/zero > "$TEST_TMP_DIR1"/checkpoint/pages-2.img
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --image-sizes
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == "Image sizes" ]]
+	[[ ${lines[8]} == *"CATEGORY"*"FILES"*"SIZE"* ]]
+	[[ ${lines[10]} == *"pages"*"2 | 12.0 KiB"* ]]
+	[[ ${lines[11]} == *"core"*"4 |"* ]]
+	[[ ${lines[18]} == *"Total"*"16 |"* ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --image-sizes -o json
+	[ "$status" -eq 0 ]
+	[[ "$output" == *'"imageSizes": {'*'"files": ['*'"path": "pages-1.img",'*'"category": "pages",'*'"size": 8192'*'"path": "pages-2.img",'* ]]
+	[[ "$output" == *'"categories": ['*'"category": "pages",'*'"files": 2,'*'"size": 12288'* ]]
+	[[ "$output" == *'"total": '* ]]
+}
+
+@test "Run checkpointctl show with tar file and --size" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	head -c 8192 /dev/zero > "$TEST_TMP_DIR1"/checkpoint/pages-1.img
+	head -c 4096 /dev/zero > "$TEST_TMP_DIR1"/checkpoint/pages-2.img
+	touch "$TEST_TMP_DIR1"/checkpoint/empty.img
+	head -c 20000 /dev/zero > "$TEST_TMP_DIR1"/rootfs-diff.tar
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --size
+	[ "$status" -eq 0 ]
+	[[ ${lines[2]} != *"CHKPT SIZE"* ]]
+	[[ ${lines[6]} == "Size breakdown" ]]
+	[[ ${lines[8]} == *"PATH"*"SIZE"* ]]
+	[[ ${lines[10]} == *"rootfs-diff.tar"*"19.5 KiB"* ]]
+	[[ ${lines[11]} == *"checkpoint/pages-1.img"*"8.0 KiB"* ]]
+	[[ ${lines[12]} == *"checkpoint/pages-2.img"*"4.0 KiB"* ]]
+	[[ ${lines[15]} == *"Total"*"31.9 KiB"* ]]
+	[[ ${output} != *"empty.img"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --size and --output=json" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --size --output=json
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == "Error: --output json does not support --size" ]]
+}
+
+@test "Run checkpointctl show with tar file and --ipc" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* test/ipc/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --ipc
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == "IPC objects" ]]
+	[[ ${lines[8]} == *"TYPE"*"OBJECTS"*"SIZE"*"DETAILS"* ]]
+	[[ ${lines[10]} == *"Shared memory  |       2 | 12.0 KiB | 1 with huge pages"* ]]
+	[[ ${lines[11]} == *"Semaphore sets |       2 | 10 B     | 5 semaphore(s)"* ]]
+	[[ ${lines[12]} == *"Message queues |       1 | 17 B     | 2 message(s)"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --ipc without IPC namespace" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --ipc
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == "IPC objects" ]]
+	[[ ${lines[7]} == "No IPC namespace found in checkpoint" ]]
+	cp test/ipc/ipcns-var-13.img "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --ipc
+	[ "$status" -eq 0 ]
+	[[ ${lines[7]} == "No IPC objects found in checkpoint" ]]
+}
+
+@test "Run checkpointctl show with tar file and --timers" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	cp test/timers/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --timers
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == "Timers" ]]
+	[[ ${lines[8]} == *"PID"*"TIMER"*"CLOCK"*"INTERVAL"*"EXPIRES IN"* ]]
+	[[ ${lines[10]} == *"1 | counter | ITIMER_REAL   | CLOCK_MONOTONIC | 5s       | 3.25s"* ]]
+	[[ ${lines[11]} == *"1 | counter | POSIX timer 0 | CLOCK_MONOTONIC | -        | 1.5s"* ]]
+	[[ ${lines[12]} == *"1 | counter | POSIX timer 1 | CLOCK_REALTIME  | 1m0s     | disarmed"* ]]
+	[[ ${lines[13]} == *"9 | sleep   | POSIX timer 0 | CLOCK_BOOTTIME  | -        | 2m0s"* ]]
+	[[ ${lines[15]} == "Warning: 3 armed timer(s) use a clock which counts from the boot of the host"* ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --timers --pid 9
+	[ "$status" -eq 0 ]
+	[[ ${lines[10]} == *"9 | sleep   | POSIX timer 0 | CLOCK_BOOTTIME | -        | 2m0s"* ]]
+	[[ ${lines[12]} == "Warning: 1 armed timer(s)"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --timers with time namespace" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	cp test/timers/* test/timens-1.img "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --timers
+	[ "$status" -eq 0 ]
+	[[ "$output" != *"Warning"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --timers without timers" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --timers
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == "Timers" ]]
+	[[ ${lines[7]} == "No timers found in checkpoint" ]]
+}
+
+@test "Run checkpointctl show with tar file and --net-files" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir -p "$TEST_TMP_DIR1"/checkpoint "$TEST_TMP_DIR1"/rootfs/etc
+	cp test/images/* test/net-files/* "$TEST_TMP_DIR1"/checkpoint
+	echo "nameserver 10.0.0.1" > "$TEST_TMP_DIR1"/rootfs/etc/resolv.conf
+	( cd "$TEST_TMP_DIR1"/rootfs && tar czf "$TEST_TMP_DIR1"/rootfs-diff.tar etc )
+	rm -r "$TEST_TMP_DIR1"/rootfs
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --net-files
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == "Network files" ]]
+	[[ ${lines[7]} == "/etc/resolv.conf (from rootfs-diff.tar):" ]]
+	[[ ${lines[8]} == "nameserver 10.0.0.1" ]]
+	[[ ${lines[9]} == "/etc/hosts (from ghost file):" ]]
+	[[ ${lines[10]} == "127.0.0.1 localhost" ]]
+	[[ ${lines[11]} == "10.88.0.5 counter" ]]
+	[[ ${lines[12]} == "/etc/hostname: bind mounted from /run/containers/storage/"*"/userdata/hostname, not included in checkpoint" ]]
+}
+
+@test "Run checkpointctl show with tar file and --net-files without network files" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --net-files
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == "Network files" ]]
+	[[ ${lines[7]} == "/etc/hostname: bind mounted from "* ]]
+	[[ ${lines[8]} == "No network files found in checkpoint" ]]
+}
+
+@test "Run checkpointctl show with tar file and --ghost-files" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* test/ghost-files/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --ghost-files
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == "Ghost files" ]]
+	[[ ${lines[8]} == *"PATH"*"SIZE"*"IMAGE SIZE"* ]]
+	[[ ${lines[10]} == *"/tmp/cache.db    | 1.0 MiB | 4.0 KiB"* ]]
+	[[ ${lines[11]} == *"/var/log/app.log | 2.9 KiB | 2.9 KiB"* ]]
+	[[ ${lines[12]} == *"Total"*"7.0 KiB"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --ghost-files without ghost files" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --ghost-files
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == "Ghost files" ]]
+	[[ ${lines[7]} == "No ghost files found in checkpoint" ]]
+}
+
+@test "Run checkpointctl show with tar file and --seccomp" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump.legacy "$TEST_TMP_DIR1"/spec.dump
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --seccomp
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == "Seccomp" ]]
+	[[ ${lines[8]} == *"DEFAULT ACTION"*"USER NOTIFICATION"* ]]
+	[[ ${lines[10]} == *"SCMP_ACT_ALLOW | -             |     1 | no"* ]]
+	[[ ${lines[13]} == *"PID | COMMAND | SECCOMP MODE"* ]]
+	[[ ${lines[15]} == *"1 | counter | disabled"* ]]
+	[[ "$output" != *"Warning"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --seccomp and user notification" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump.seccomp "$TEST_TMP_DIR1"/spec.dump
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --seccomp
+	[ "$status" -eq 0 ]
+	[[ ${lines[10]} == *"SCMP_ACT_ALLOW | SCMP_ARCH_X86_64 |     2 | YES: mount, umount2"* ]]
+	[[ ${lines[12]} == "Seccomp listener: /run/seccomp-agent.sock" ]]
+	[[ "$output" == *"Warning: the container uses seccomp user notification"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --mounts and --mount-ids" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump.legacy "$TEST_TMP_DIR1"/spec.dump
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --mounts --mount-ids
+	[ "$status" -eq 0 ]
+	[[ ${lines[8]} == *"MOUNT ID"*"DEVICE"* ]]
+	[[ ${lines[10]} == *"/proc"*"| -        | -      |" ]]
+	cp test/mountpoints.img.legacy "$TEST_TMP_DIR1"/checkpoint/mountpoints-13.img
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --mounts --mount-ids
+	[ "$status" -eq 0 ]
+	[[ ${lines[10]} == *"/proc"*"|      301 | 0:53   |" ]]
+	[[ ${lines[11]} == *"/data"*"|      302 | 253:1  |" ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --mounts --mount-ids -o json
+	[ "$status" -eq 0 ]
+	[[ "$output" == *'"destination": "/data",'*'"mountId": 302,'*'"device": "253:1"'* ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --mount-ids
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == *"Cannot use --mount-ids without --mounts option"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --output svg" {
+	cp test/engines/cri-o/config.dump "$TEST_TMP_DIR1"
+	cp test/engines/cri-o/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	cp test/mem-pages/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --output svg --view memory
+	[ "$status" -eq 0 ]
+	[[ ${lines[0]} == "<svg "*'width="1200" height="100"'* ]]
+	[[ ${lines[1]} == *">Memory pages by process of container counter</text>" ]]
+	[[ "$output" == *"<title>counter (1) 28.0 KiB, 20.0 KiB own</title>"*'<rect x="10.0" y="30" width="1180.0"'* ]]
+	[[ "$output" == *"<title>sh (7) 8.0 KiB, 8.0 KiB own</title>"*'<rect x="10.0" y="50" width="337.1"'* ]]
+	# The only page of process 9 is stored in the parent checkpoint
+	[[ "$output" != *"<title>sleep (9)"* ]]
+	[[ ${lines[-1]} == "</svg>" ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --output svg --view files
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == *'unsupported view "files" (supported: memory)'* ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --output svg --print-stats
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == *"--output svg does not support --print-stats"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --output svg and missing pagemap" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --output svg
+	[ "$status" -eq 3 ]
+	[[ ${lines[0]} == *"unable to render memory: pagemap-1.img not found in checkpoint"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --ps-tree" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --ps-tree
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == "Process tree" ]]
+	[[ ${lines[7]} == "1 counter (1 thread)" ]]
+	[[ ${lines[8]} == "└── 7 sh (2 threads)" ]]
+	[[ ${lines[9]} == "    └── 9 sleep (1 thread)" ]]
+}
+
+@test "Run checkpointctl show with tar file and --ps-tree --output dot" {
+	cp test/engines/cri-o/config.dump "$TEST_TMP_DIR1"
+	cp test/engines/cri-o/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --ps-tree --output dot
+	[ "$status" -eq 0 ]
+	[[ ${lines[0]} == 'digraph "process tree" {' ]]
+	[[ ${lines[1]} == *'label="Process tree of container counter";' ]]
+	[[ "$output" == *'1 [label="1\ncounter\n1 thread"];'*'7 [label="7\nsh\n2 threads"];'*'9 [label="9\nsleep\n1 thread"];'* ]]
+	[[ "$output" == *"1 -> 7;"*"7 -> 9;"* ]]
+	[[ ${lines[-1]} == "}" ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --output dot
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == *"--output dot requires --ps-tree"* ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --ps-tree --output json
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == *"--output json does not support --ps-tree"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --ulimits" {
+	cp test/config.dump.ulimits "$TEST_TMP_DIR1"/config.dump
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --ulimits
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == "Configured ulimits (config.dump)" ]]
+	[[ ${lines[8]} == *"NAME"*"SOFT"*"HARD"* ]]
+	[[ ${lines[10]} == *"RLIMIT_NOFILE | 1024 |   1048576"* ]]
+	[[ ${lines[11]} == *"RLIMIT_CORE   |    0 | unlimited"* ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --ulimits --output json
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == *"--output json does not support --ulimits"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --ulimits without configured ulimits" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --ulimits
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == "Configured ulimits (config.dump)" ]]
+	[[ ${lines[7]} == "No ulimits configured" ]]
+}
+
+@test "Run checkpointctl show with tar file and --restore-annotations" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump.restore "$TEST_TMP_DIR1"/spec.dump
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --restore-annotations
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == "Restore annotations" ]]
+	[[ ${lines[8]} == *"ANNOTATION"*"VALUE"*"DESCRIPTION"* ]]
+	[[ ${lines[10]} == *"io.podman.annotations.autoremove"*"TRUE"*"Container is removed when it exits (--rm)"* ]]
+	[[ ${lines[11]} == *"io.podman.annotations.init"*"TRUE"*"An init process runs as PID 1 (--init)"* ]]
+	[[ ${output} != *"org.example.unrelated"* ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --restore-annotations --output json
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == *"--output json does not support --restore-annotations"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --restore-annotations without restore annotations" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --restore-annotations
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == "Restore annotations" ]]
+	[[ ${lines[7]} == "No restore related annotations found" ]]
+}
+
+@test "Run checkpointctl show with pod sandbox checkpoint" {
+	cp test/sandbox/* "$TEST_TMP_DIR1"
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar
+	[ "$status" -eq 0 ]
+	[[ ${lines[0]} == "Displaying pod sandbox checkpoint data from "* ]]
+	[[ ${lines[2]} == *"SANDBOX"*"NAMESPACE"*"ID"*"UID"*"HOSTNAME"*"SHARED NAMESPACES"* ]]
+	[[ ${lines[4]} == *"counter-pod | default   | 9c1a7e2b3d4f |"*"| network: pod, pid: container, ipc: pod |" ]]
+	[[ ${lines[6]} == "Network configuration" ]]
+	[[ ${lines[10]} == *"10.96.0.10"*"default.svc.cluster.local, svc.cluster.local"*"ndots:5"* ]]
+	[[ ${lines[15]} == *"TCP"*"8088"*"8080"*"-"* ]]
+	[[ ${lines[16]} == *"UDP"*"53"*"5353"*"127.0.0.1"* ]]
+	[[ ${lines[18]} == "Containers" ]]
+	[[ ${lines[22]} == *"counter | 7eb9680287f1"* ]]
+	[[ ${lines[23]} == *"sidecar | 0a1b2c3d4e5f"* ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --output json
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == *"--output json is not supported for pod sandbox checkpoints"* ]]
+}
+
+@test "Run checkpointctl show with pod sandbox checkpoint without pod.options" {
+	cp test/sandbox/pod.dump "$TEST_TMP_DIR1"
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar
+	[ "$status" -eq 0 ]
+	[[ ${lines[2]} != *"| ID |"* ]]
+	[[ ${lines[-1]} == "No containers found in checkpoint" ]]
+}
+
+@test "Run checkpointctl show with tar file and --ps-tree --ps-tree-cmd" {
+	cp test/engines/cri-o/config.dump "$TEST_TMP_DIR1"
+	cp test/engines/cri-o/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	cp test/ps-tree-cmd/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --ps-tree --ps-tree-cmd
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == "Process tree" ]]
+	[[ ${lines[7]} == "1 counter (1 thread) [/usr/bin/counter --interval 5]" ]]
+	# The memory of process 7 is not part of the checkpoint
+	[[ ${lines[8]} == "└── 7 sh (2 threads)" ]]
+	[[ ${lines[9]} == "    └── 9 sleep (1 thread) [sleep infinity]" ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --ps-tree --ps-tree-cmd --output dot
+	[ "$status" -eq 0 ]
+	[[ "$output" == *'1 [label="1\ncounter\n1 thread\n/usr/bin/counter --interval 5"];'* ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --ps-tree-cmd
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == *"Cannot use --ps-tree-cmd without --ps-tree option"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --ps-tree and missing pstree.img" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --ps-tree --ps-tree-cmd
+	[ "$status" -eq 3 ]
+	[[ ${lines[-1]} == *"unable to display process tree: pstree.img not found in checkpoint"* ]]
+}
+
+@test "Run checkpointctl inspect with tar file" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	cp test/stats-dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl inspect "$TEST_TMP_DIR2"/test.tar
+	[ "$status" -eq 0 ]
+	[[ ${lines[0]} == "{" ]]
+	[[ ${lines[1]} == *'"schemaVersion": 1,' ]]
+	[[ "$output" == *'"checkpoint": {'*'"engine": "Podman",'*'"threads": 4,'* ]]
+	[[ "$output" == *'"imageSizes": {'*'"categories": ['* ]]
+	[[ "$output" == *'"dumpStatistics": {'*'"frozen_time": 1376964,'* ]]
+	[[ "$output" == *'"restoreStatistics": null,'* ]]
+	[[ "$output" == *'"validation": {'*'"valid": true,'* ]]
+}
+
+@test "Run checkpointctl inspect with tar file and --output=yaml" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl inspect "$TEST_TMP_DIR2"/test.tar --output=yaml
+	[ "$status" -eq 0 ]
+	[[ "$output" == *"schemaVersion: 1"* ]]
+	[[ "$output" == *"dumpStatistics: null"* ]]
+	checkpointctl inspect "$TEST_TMP_DIR2"/test.tar --output=table
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == *'unsupported output format "table" (supported: json, yaml)'* ]]
+}
+
+@test "Run checkpointctl inspect with tar file without spec.dump" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl inspect "$TEST_TMP_DIR2"/test.tar
+	[ "$status" -eq 1 ]
+	[[ "$output" == *'"checkpoint": null,'* ]]
+	[[ "$output" == *'"valid": false,'* ]]
+	[[ "$output" == *"Error: $TEST_TMP_DIR2/test.tar failed validation"* ]]
+}
+
+@test "Run checkpointctl show with tar file and CRIU images in another directory" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/images
+	cp test/images/* "$TEST_TMP_DIR1"/images
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --proc-ids
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == *"Process IDs"* ]]
+	[[ ${lines[10]} == *"1 |    1 |   1 |  0022 | counter"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --images-dir" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint "$TEST_TMP_DIR1"/images
+	cp test/images/inventory.img "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/images
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --proc-ids
+	[ "$status" -eq 3 ]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --proc-ids --images-dir images
+	[ "$status" -eq 0 ]
+	[[ ${lines[10]} == *"1 |    1 |   1 |  0022 | counter"* ]]
+}
+
+@test "Run checkpointctl validate with tar file and CRIU images in another directory" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/images
+	cp test/images/* "$TEST_TMP_DIR1"/images
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl validate "$TEST_TMP_DIR2"/test.tar
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == *"images"*"OK"* ]]
+	[[ ${lines[8]} == *"CRIU images"*"OK"*"14 images"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --env and --mask-env" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump.secrets "$TEST_TMP_DIR1"/spec.dump
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --env
+	[ "$status" -eq 0 ]
+	[[ ${lines[11]} == *"DB_PASSWORD"*"hunter2"* ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --env --mask-env
+	[ "$status" -eq 0 ]
+	[[ ${lines[10]} == *"PATH"*"/usr/bin:/bin"* ]]
+	[[ ${lines[11]} == *"DB_PASSWORD"*"| ***"* ]]
+	[[ ${lines[12]} == *"GITHUB_TOKEN"*"| ***"* ]]
+	[[ ${lines[13]} == *"aws_secret_access_key"*"| ***"* ]]
+	[[ ${lines[14]} == *"API_KEY"*"| ***"* ]]
+	[[ ${lines[15]} == *"KEYBOARD"*"| us"* ]]
+	[[ ${lines[16]} == *"MONKEY_COUNT"*" 3 |" ]]
+	[[ "$output" != *"hunter2"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --mem-pages" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	cp test/mem-pages/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --mem-pages
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == "Memory pages per process" ]]
+	[[ ${lines[8]} == *"ANONYMOUS | FILE-BACKED |  SHARED  |  TOTAL"* ]]
+	[[ ${lines[10]} == *"1 | counter | 12.0 KiB  | 8.0 KiB     | 72.0 KiB | 92.0 KiB |" ]]
+	[[ ${lines[11]} == *"7 | sh      | 8.0 KiB   | 0 B         | 64.0 KiB | 72.0 KiB |" ]]
+	[[ ${lines[12]} == *"9 | sleep   | 4.0 KiB   | 0 B         | 12.0 KiB | 16.0 KiB |" ]]
+}
+
+@test "Run checkpointctl show with tar file and --mem-pages and --pid" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	cp test/mem-pages/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --mem-pages --pid 9
+	[ "$status" -eq 0 ]
+	[[ ${lines[10]} == *"9 | sleep"* ]]
+	[[ ${lines[11]} == "+-----+"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --mem-pages and missing pagemap" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --mem-pages
+	[ "$status" -eq 3 ]
+	[[ ${lines[6]} == *"unable to display memory pages: pagemap-1.img not found in checkpoint"* ]]
+}
+
+@test "Run checkpointctl diff with changed size" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	head -c 1024 /dev/zero > "$TEST_TMP_DIR1"/checkpoint/pages-1.img
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/before.tar . )
+	head -c 3072 /dev/zero > "$TEST_TMP_DIR1"/checkpoint/pages-1.img
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/after.tar . )
+	checkpointctl diff "$TEST_TMP_DIR2"/before.tar "$TEST_TMP_DIR2"/after.tar
+	[ "$status" -eq 0 ]
+	[[ ${lines[2]} == *"FIELD"*"before.tar"*"after.tar"*"DELTA"* ]]
+	[[ ${lines[4]} == *"CHKPT Size"*"1.0 KiB"*"3.0 KiB"*"+2048 bytes (+200.0%)"* ]]
+	checkpointctl diff -o json "$TEST_TMP_DIR2"/after.tar "$TEST_TMP_DIR2"/before.tar
+	[ "$status" -eq 0 ]
+	[[ "$output" == *'"delta": "-2048 bytes (-66.7%)"'* ]]
+	[[ "$output" == *'"sizeDelta": -2048'* ]]
+}
+
+@test "Run checkpointctl diff with sizes differing by a few bytes" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	head -c 2048 /dev/zero > "$TEST_TMP_DIR1"/checkpoint/pages-1.img
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/before.tar . )
+	head -c 2058 /dev/zero > "$TEST_TMP_DIR1"/checkpoint/pages-1.img
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/after.tar . )
+	checkpointctl diff "$TEST_TMP_DIR2"/before.tar "$TEST_TMP_DIR2"/after.tar
+	[ "$status" -eq 0 ]
+	[[ "$output" != *"No differences found"* ]]
+	[[ ${lines[4]} == *"CHKPT Size"*"2.0 KiB"*"2.0 KiB"*"+10 bytes"* ]]
+	checkpointctl diff -o json "$TEST_TMP_DIR2"/before.tar "$TEST_TMP_DIR2"/after.tar
+	[ "$status" -eq 0 ]
+	[[ "$output" == *'"identical": false'* ]]
+	[[ "$output" == *'"sizeDelta": 10'* ]]
+}
+
+@test "Run checkpointctl show with tar file and --mounts and --mount-sort" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump.external "$TEST_TMP_DIR1"/spec.dump
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --mounts --mount-sort
+	[ "$status" -eq 0 ]
+	[[ ${lines[10]} == *"/data"*"bind"*"../srv/data"* ]]
+	[[ ${lines[11]} == *"/etc/host-hosts"*"bind"* ]]
+	[[ ${lines[12]} == *"/proc"*"proc"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --mounts and --mount-type" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump.external "$TEST_TMP_DIR1"/spec.dump
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --mounts --mount-type=bind,tmpfs
+	[ "$status" -eq 0 ]
+	[[ ${lines[10]} == *"/data"*"bind"* ]]
+	[[ ${lines[11]} == *"/etc/host-hosts"*"bind"* ]]
+	[[ ${lines[12]} == "+-----"* ]]
+	[[ "$output" != *"/proc"* ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --mounts --mount-type tmpfs
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == "Overview of Mounts" ]]
+	[[ ${lines[7]} == "No mounts matching filter" ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --mounts --mount-type bind -o json
+	[ "$status" -eq 0 ]
+	[[ "$output" == *'"destination": "/data"'* ]]
+	[[ "$output" != *'"destination": "/proc"'* ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --mounts --mount-type tmpfs -o json
+	[ "$status" -eq 0 ]
+	[[ "$output" == *'"mounts": []'* ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar -o json
+	[ "$status" -eq 0 ]
+	[[ "$output" != *'"mounts"'* ]]
+}
+
+@test "Run checkpointctl show with tar file and --mount-type without --mounts" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --mount-type bind
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == *"Cannot use --mount-type without --mounts option"* ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --mount-sort
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == *"Cannot use --mount-sort without --mounts option"* ]]
+}
+
+@test "Run checkpointctl show with compressed tar file and --metadata-only" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	cp test/stats-dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	head -c 2048 /dev/urandom > "$TEST_TMP_DIR1"/checkpoint/pages-1.img
+	echo "dump finished" > "$TEST_TMP_DIR1"/checkpoint/dump.log
+	( cd "$TEST_TMP_DIR1" && tar czf "$TEST_TMP_DIR2"/test.tar.gz . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar.gz --metadata-only --size --dump-log --print-stats
+	[ "$status" -eq 0 ]
+	[[ ${lines[4]} == *"Podman"* ]]
+	[[ ${lines[6]} == "Size breakdown" ]]
+	[[ ${lines[10]} == *"checkpoint/pages-1.img"*"2.0 KiB"* ]]
+	[[ "$output" == *"CRIU dump log"*"dump finished"* ]]
+	[[ "$output" == *"CRIU dump statistics"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --metadata-only and --ps-tree" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --metadata-only --ps-tree
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == *"--metadata-only cannot be used with options which read the CRIU images or rootfs-diff.tar"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --metadata-only and options reading CRIU images" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	for options in --required-features --proc-ids --processes --sched --timers --hostname --tty \
+		--shared-memory --ipc --mem-pages --ghost-files "--env --env-merged" "--mounts --mount-ids" \
+		--max-open-files --seccomp --mem-usage --net-files; do
+		# shellcheck disable=SC2086
+		checkpointctl show "$TEST_TMP_DIR2"/test.tar --metadata-only $options
+		[ "$status" -eq 1 ]
+		[[ ${lines[0]} == *"--metadata-only cannot be used with options which read the CRIU images or rootfs-diff.tar"* ]]
+	done
+}
+
+@test "Run checkpointctl show with tar file and --metadata-only omits the thread count" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --metadata-only
+	[ "$status" -eq 0 ]
+	[[ ${lines[2]} != *"THREADS"* ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar
+	[ "$status" -eq 0 ]
+	[[ ${lines[2]} == *"THREADS"* ]]
+}
+
+@test "Run checkpointctl verify with complete checkpoint" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	head -c 4096 /dev/zero > "$TEST_TMP_DIR1"/checkpoint/pages-1.img
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl verify "$TEST_TMP_DIR2"/test.tar
+	[ "$status" -eq 0 ]
+	[[ ${lines[0]} == "Verifying container checkpoint $TEST_TMP_DIR2/test.tar" ]]
+	[[ ${lines[4]} == *"config.dump"*"OK"* ]]
+	[[ ${lines[6]} == *"container manager"*"OK"*"libpod"* ]]
+	[[ ${lines[10]} == *"pages-*.img"*"OK"*"1 image"* ]]
+	[[ ${lines[11]} == *"rootfs-diff.tar"*"SKIPPED"* ]]
+}
+
+@test "Run checkpointctl verify with missing CRIU images and empty rootfs-diff.tar" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/inventory.img "$TEST_TMP_DIR1"/checkpoint
+	touch "$TEST_TMP_DIR1"/rootfs-diff.tar
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl verify "$TEST_TMP_DIR2"/test.tar
+	[ "$status" -eq 1 ]
+	[[ ${lines[8]} == *"inventory.img"*"OK"* ]]
+	[[ ${lines[9]} == *"pstree.img"*"FAILED"*"not found"* ]]
+	[[ ${lines[10]} == *"pages-*.img"*"FAILED"*"not found"* ]]
+	[[ ${lines[11]} == *"rootfs-diff.tar"*"FAILED"*"empty"* ]]
+	[[ ${lines[13]} == *"$TEST_TMP_DIR2/test.tar failed verification"* ]]
+}
+
+@test "Run checkpointctl verify with unknown container manager and --output json" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	echo '{"annotations": {"io.container.manager": "custom-tool"}}' > "$TEST_TMP_DIR1"/spec.dump
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl verify "$TEST_TMP_DIR2"/test.tar -o json
+	[ "$status" -eq 1 ]
+	[[ "$output" == *'"valid": false'* ]]
+	[[ "$output" == *'"name": "container manager",'*'"status": "FAILED",'*'"details": "unknown container manager \"custom-tool\""'* ]]
+}
+
+@test "Run checkpointctl show with tar file and --lifecycle" {
+	cp test/config.dump.lifecycle "$TEST_TMP_DIR1"/config.dump
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --lifecycle
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == "Lifecycle" ]]
+	[[ ${lines[10]} == *"Restart Policy"*"on-failure (3 retries)"* ]]
+	[[ ${lines[11]} == *"Health Check"*"/bin/sh -c curl -f http://localhost:8080/ || exit 1"* ]]
+	[[ ${lines[12]} == *"Health Check Interval"*"30s"* ]]
+	[[ ${lines[13]} == *"Health Check Timeout"*"5s"* ]]
+	[[ ${lines[14]} == *"Health Check Retries"*"3 |" ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --lifecycle --output json
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == *"--output json does not support --lifecycle"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --lifecycle without restart policy and health check" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --lifecycle
+	[ "$status" -eq 0 ]
+	[[ ${lines[10]} == *"Restart Policy"*"| none"* ]]
+	[[ ${lines[11]} == *"Health Check"*"| none"* ]]
+	[[ ${lines[14]} == *"Health Check Retries"*"| none"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --size-unit" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	head -c 3000000 /dev/zero > "$TEST_TMP_DIR1"/checkpoint/pages-1.img
+	head -c 20000 /dev/zero > "$TEST_TMP_DIR1"/rootfs-diff.tar
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar
+	[ "$status" -eq 0 ]
+	[[ ${lines[4]} == *"| 2.9 MiB    | 19.5 KiB          |" ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --size-unit MiB
+	[ "$status" -eq 0 ]
+	[[ ${lines[4]} == *"| 2.86 MiB   | 0.02 MiB          |" ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --size-unit KiB --si
+	[ "$status" -eq 0 ]
+	[[ ${lines[4]} == *"| 3000.00 kB | 20.00 kB          |" ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --si
+	[ "$status" -eq 0 ]
+	[[ ${lines[4]} == *"| 3.0 MB     | 20.0 kB           |" ]]
+}
+
+@test "Run checkpointctl with unsupported --size-unit" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --size-unit TB
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == *'unsupported size unit "TB" (supported: auto, B, KiB, MiB, GiB)'* ]]
+	checkpointctl validate "$TEST_TMP_DIR2"/test.tar --size-unit mb
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == *'unsupported size unit "mb"'* ]]
+}
+
+@test "Run checkpointctl show with tar file and --labels and --annotations" {
+	cp test/config.dump.labels "$TEST_TMP_DIR1"/config.dump
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --labels --annotations
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == "Labels" ]]
+	[[ ${lines[10]} == *"io.example/Team"*"payments"* ]]
+	[[ ${lines[11]} == *"version"*"1.2"* ]]
+	[[ ${lines[13]} == "Annotations" ]]
+	[[ ${lines[17]} == *"io.container.manager"*"libpod"* ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --labels --annotations --output json
+	[ "$status" -eq 0 ]
+	[[ $(echo "$output" | jq -r '.labels."io.example/Team"') == "payments" ]]
+	[[ $(echo "$output" | jq -r '.labels.version') == "1.2" ]]
+	[[ $(echo "$output" | jq -r '.annotations."io.container.manager"') == "libpod" ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --output json
+	[ "$status" -eq 0 ]
+	[[ $(echo "$output" | jq 'has("labels") or has("annotations")') == "false" ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --labels --output logfmt
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == *"--output logfmt does not support --labels and --annotations"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --labels (CRI-O)" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump.cri-o.labels "$TEST_TMP_DIR1"/spec.dump
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --labels --output yaml
+	[ "$status" -eq 0 ]
+	[[ "$output" == *"app.kubernetes.io/name: web"* ]]
+	[[ "$output" == *"io.kubernetes.pod.name: web-1"* ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar
+	[ "$status" -eq 0 ]
+	[[ "$output" != *"Labels"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --mounts --mount-sizes" {
+	mkdir "$TEST_TMP_DIR2"/volume
+	head -c 2048 /dev/zero > "$TEST_TMP_DIR2"/volume/data
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cat > "$TEST_TMP_DIR1"/spec.dump <<-EOT
+	{
+	  "mounts": [
+	    { "destination": "/proc", "type": "proc", "source": "proc" },
+	    { "destination": "/data", "type": "bind", "source": "$TEST_TMP_DIR2/volume" },
+	    { "destination": "/srv", "type": "bind", "source": "/srv/does-not-exist" }
+	  ],
+	  "annotations": { "io.container.manager": "libpod" }
+	}
+	EOT
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --mounts --mount-sizes
+	[ "$status" -eq 0 ]
+	[[ ${lines[8]} == *"SOURCE SIZE"* ]]
+	[[ ${lines[10]} == *"/proc"*"| -"* ]]
+	[[ ${lines[11]} == *"/data"*"| 2.0 KiB"* ]]
+	[[ ${lines[12]} == *"/srv"*"| -"* ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --mounts --mount-sizes --output json
+	[ "$status" -eq 0 ]
+	[[ $(echo "$output" | jq '.mounts[1].sourceSize') == "2048" ]]
+	[[ $(echo "$output" | jq '.mounts[2] | has("sourceSize")') == "false" ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --mount-sizes
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == *"Cannot use --mount-sizes without --mounts option"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --compare-criu-version" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	cp test/dump.log "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	mkdir "$TEST_TMP_DIR2"/bin
+	printf '#!/bin/sh\necho "Version: 3.16"\necho "GitID: v3.16"\n' > "$TEST_TMP_DIR2"/bin/criu
+	chmod +x "$TEST_TMP_DIR2"/bin/criu
+	PATH="$TEST_TMP_DIR2/bin:$PATH" checkpointctl show "$TEST_TMP_DIR2"/test.tar --compare-criu-version
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == "CRIU Version" ]]
+	[[ ${lines[10]} == *"3.17.1"*"3.16"* ]]
+	[[ "$output" == *"Warning: the installed CRIU 3.16 is older than CRIU 3.17.1 which created the checkpoint"* ]]
+	printf '#!/bin/sh\necho "Version: 3.19"\n' > "$TEST_TMP_DIR2"/bin/criu
+	PATH="$TEST_TMP_DIR2/bin:$PATH" checkpointctl show "$TEST_TMP_DIR2"/test.tar --compare-criu-version
+	[ "$status" -eq 0 ]
+	[[ "$output" == *"The installed CRIU 3.19 is newer than CRIU 3.17.1 which created the checkpoint"* ]]
+	printf '#!/bin/sh\necho "Version: 3.17.1"\n' > "$TEST_TMP_DIR2"/bin/criu
+	PATH="$TEST_TMP_DIR2/bin:$PATH" checkpointctl show "$TEST_TMP_DIR2"/test.tar --compare-criu-version
+	[ "$status" -eq 0 ]
+	[[ "$output" == *"The installed CRIU matches the version which created the checkpoint"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --compare-criu-version without criu binary" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	mkdir "$TEST_TMP_DIR2"/bin
+	PATH="$TEST_TMP_DIR2/bin" checkpointctl show "$TEST_TMP_DIR2"/test.tar --compare-criu-version
+	[ "$status" -eq 0 ]
+	[[ "$output" == *"Note: no criu binary found in PATH, skipping the CRIU version comparison"* ]]
+	[[ "$output" != *"CRIU Version"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --quiet" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	cp test/stats-dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --mounts --print-stats --quiet
+	[ "$status" -eq 0 ]
+	[[ ${lines[0]} == "+-"* ]]
+	[[ ${lines[1]} == *"CONTAINER"* ]]
+	[[ ${lines[5]} == "+-"* ]]
+	[[ ${lines[6]} == *"DESTINATION"* ]]
+	[[ "$output" != *"Displaying container checkpoint data"* ]]
+	[[ "$output" != *"Overview of Mounts"* ]]
+	[[ "$output" != *"CRIU dump statistics"* ]]
+	[[ "$output" != *$'\n\n'* ]]
+}
+
+@test "Run checkpointctl show with tar file and --no-header" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --mounts --no-header
+	[ "$status" -eq 0 ]
+	[[ ${lines[0]} == *"Displaying container checkpoint data"* ]]
+	[[ ${lines[2]} == *"Podman"* ]]
+	[[ ${lines[4]} == "Overview of Mounts" ]]
+	[[ ${lines[6]} == *"/proc"* ]]
+	[[ "$output" != *"CONTAINER"* ]]
+	[[ "$output" != *"DESTINATION"* ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar "$TEST_TMP_DIR2"/test.tar --allow-duplicates --quiet --no-header
+	[ "$status" -eq 0 ]
+	[ "${#lines[@]}" -eq 6 ]
+	[[ ${lines[1]} == *"Podman"* ]]
+	[[ ${lines[4]} == *"Podman"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --env --env-merged" {
+	cp test/config.dump.env "$TEST_TMP_DIR1"/config.dump
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	cp test/env-merged/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --env --env-merged
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == "Environment variables of process 1 (counter)" ]]
+	[[ ${lines[8]} == *"VARIABLE"*"VALUE"*"SOURCE"* ]]
+	[[ ${lines[10]} == *"PATH"*"/usr/bin:/bin"*"| image "* ]]
+	[[ ${lines[11]} == *"HOME"*"/root"*"| runtime "* ]]
+	[[ ${lines[12]} == *"LOG_LEVEL"*"debug"*"| overridden at runtime |" ]]
+	[[ ${lines[13]} == *"TERM"*"xterm"*"| unset at runtime "* ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --env-merged
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == *"Cannot use --env-merged without --env option"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --env --env-merged without environment in memory" {
+	cp test/config.dump.env "$TEST_TMP_DIR1"/config.dump
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --env --env-merged
+	[ "$status" -eq 3 ]
+	[[ "$output" == *"unable to display merged environment: environment of process 1 not found in checkpoint"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --versions" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	cp test/dump.log "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --versions
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == "Versions" ]]
+	[[ ${lines[10]} == *"CRIU"*"3.17.1"* ]]
+	# The container engine does not record its version
+	[ "${#lines[@]}" -eq 12 ]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --versions --output json
+	[ "$status" -eq 0 ]
+	[[ $(echo "$output" | jq -r '.criuVersion') == "3.17.1" ]]
+	[[ $(echo "$output" | jq -r '.engineVersion') == "null" ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --versions --output logfmt
+	[ "$status" -eq 0 ]
+	[[ ${lines[0]} == *"criu_version=3.17.1" ]]
+}
+
+@test "Run checkpointctl show with tar file and --versions without recorded versions" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --versions
+	[ "$status" -eq 0 ]
+	[[ ${lines[10]} == *"CRIU"*"| unknown |" ]]
+}
+
+@test "Run checkpointctl show with tar file and --validate-spec" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump.seccomp "$TEST_TMP_DIR1"/spec.dump
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --validate-spec --strict
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == "Spec validation" ]]
+	[[ ${lines[7]} == "No violations of the OCI runtime specification found in spec.dump" ]]
+}
+
+@test "Run checkpointctl show with tar file and --validate-spec with invalid spec" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump.invalid "$TEST_TMP_DIR1"/spec.dump
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --validate-spec
+	[ "$status" -eq 0 ]
+	[[ ${lines[8]} == *"FIELD"*"PROBLEM"* ]]
+	[[ ${lines[10]} == *"process.cwd"*'"work" is not an absolute path'* ]]
+	[[ ${lines[11]} == *"process.rlimits[0]"*"soft limit 4096 exceeds hard limit 1024"* ]]
+	[[ ${lines[12]} == *"mounts[2].destination"*'"data" is not an absolute path'* ]]
+	[[ ${lines[13]} == *"linux.namespaces[3].type"*'namespace "pid" is specified more than once'* ]]
+	[[ ${lines[15]} == "Warning: spec.dump has 4 violations of the OCI runtime specification"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --validate-spec --strict with invalid spec" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump.invalid "$TEST_TMP_DIR1"/spec.dump
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --validate-spec --strict
+	[ "$status" -eq 1 ]
+	[[ ${lines[-1]} == "Error: spec.dump violates the OCI runtime specification: 4 violations" ]]
+}
+
+@test "Run checkpointctl show with tar file and compressed pages" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/compressed-pages/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --mem-usage --image-sizes
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == "Memory usage" ]]
+	[[ ${lines[8]} == *"MEMORY (UNCOMPRESSED)"*"COMPRESSED"* ]]
+	[[ ${lines[10]} == *"| 20.0 KiB"*"| 11 B "* ]]
+	[[ ${lines[12]} == "Image sizes" ]]
+	[[ ${lines[14]} == *"UNCOMPRESSED"* ]]
+	[[ ${lines[17]} == *"pages"*"11 B"*"20.0 KiB"* ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --mem-usage --image-sizes --output json
+	[ "$status" -eq 0 ]
+	[[ $(echo "$output" | jq '.memoryUsage.size') == "20480" ]]
+	[[ $(echo "$output" | jq '.memoryUsage.compressedSize') == "11" ]]
+	[[ $(echo "$output" | jq '.imageSizes.categories[] | select(.category == "pages") | .uncompressedSize') == "20480" ]]
+}
+
+@test "Run checkpointctl show with tar file and --caps --show-dropped" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump.caps "$TEST_TMP_DIR1"/spec.dump
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --caps
+	[ "$status" -eq 0 ]
+	[[ ${lines[6]} == "Capabilities" ]]
+	[[ ${lines[10]} == *"CAP_CHOWN"*"| yes "*"| yes "*"| yes "*"| no "*"| no "* ]]
+	[[ ${lines[11]} == *"CAP_DAC_OVERRIDE"*"| yes "*"| no "* ]]
+	[[ "$output" != *"dropped"* ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --caps --show-dropped
+	[ "$status" -eq 0 ]
+	[[ ${lines[21]} == "Capabilities compared to the defaults of Podman" ]]
+	[[ ${lines[25]} == *"CAP_CHOWN"*"| kept "* ]]
+	[[ ${lines[30]} == *"CAP_NET_BIND_SERVICE"*"| dropped |" ]]
+	[[ ${lines[35]} == *"CAP_SYS_CHROOT"*"| dropped |" ]]
+	[[ ${lines[36]} == *"CAP_NET_ADMIN"*"| added "* ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --show-dropped
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == *"Cannot use --show-dropped without --caps option"* ]]
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --caps --output json
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == *"--output json does not support --caps"* ]]
+}
+
+@test "Run checkpointctl show with tar file and --caps without capabilities" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --caps --show-dropped
+	[ "$status" -eq 0 ]
+	[[ ${lines[7]} == "No capabilities found in checkpoint" ]]
+	[[ ${lines[12]} == *"CAP_CHOWN"*"| dropped |" ]]
+}
+
+@test "Run checkpointctl stat with tar file" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* "$TEST_TMP_DIR1"/checkpoint
+	head -c 3000000 /dev/zero > "$TEST_TMP_DIR1"/checkpoint/pages-1.img
+	head -c 20000 /dev/zero > "$TEST_TMP_DIR1"/rootfs-diff.tar
+	( cd "$TEST_TMP_DIR1" && tar czf "$TEST_TMP_DIR2"/test.tar.gz . )
+	checkpointctl stat "$TEST_TMP_DIR2"/test.tar.gz
+	[ "$status" -eq 0 ]
+	[[ ${lines[0]} == "Sizes of container checkpoint $TEST_TMP_DIR2/test.tar.gz" ]]
+	[[ ${lines[2]} == *"ARCHIVE"*"TOTAL"*"CHECKPOINT"*"ROOTFS DIFF"* ]]
+	[[ ${lines[4]} == *"| 2.9 MiB | 2.9 MiB    | 19.5 KiB    |" ]]
+	[[ ${lines[6]} == "Image sizes" ]]
+	[[ ${lines[10]} == *"pages"*" 1 | 2.9 MiB |" ]]
+	checkpointctl stat "$TEST_TMP_DIR2"/test.tar.gz --output json
+	[ "$status" -eq 0 ]
+	[[ $(echo "$output" | jq '.rootFsDiffSize') == "20000" ]]
+	[[ $(echo "$output" | jq '.categories[0].category') == '"pages"' ]]
+	[[ $(echo "$output" | jq '.categories[0].size') == "3000000" ]]
+	[[ $(echo "$output" | jq '.archiveSize') == $(stat -c %s "$TEST_TMP_DIR2"/test.tar.gz) ]]
+}
+
+@test "Run checkpointctl stat with invalid CRIU images" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	echo "invalid" > "$TEST_TMP_DIR1"/checkpoint/inventory.img
+	echo "invalid" > "$TEST_TMP_DIR1"/checkpoint/pstree.img
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl stat "$TEST_TMP_DIR2"/test.tar --output logfmt
+	[ "$status" -eq 0 ]
+	[[ ${lines[0]} == *"checkpoint_size=\"16 B\""* ]]
+}
+
+@test "Run checkpointctl compat with tar file" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	cp test/dump.log "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	cp test/images/* test/compat/* "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	mkdir "$TEST_TMP_DIR2"/bin
+	printf '#!/bin/sh\necho "Version: 3.19"\n' > "$TEST_TMP_DIR2"/bin/criu
+	chmod +x "$TEST_TMP_DIR2"/bin/criu
+	PATH="$TEST_TMP_DIR2/bin:$PATH" checkpointctl compat "$TEST_TMP_DIR2"/test.tar
+	[ "$status" -eq 0 ]
+	[[ ${lines[0]} == "Comparing the host of container checkpoint $TEST_TMP_DIR2/test.tar with this host" ]]
+	[[ ${lines[2]} == *"ATTRIBUTE"*"CHECKPOINT"*"THIS HOST"*"RESULT"* ]]
+	[[ ${lines[4]} == *"Kernel"*"| 6.1.0 "*"| $(uname -r) "* ]]
+	[[ ${lines[5]} == *"Architecture"*"| x86_64 "*"| $(uname -m) "* ]]
+	[[ ${lines[6]} == *"Cgroup version"*"| v2 "* ]]
+	[[ ${lines[7]} == *"CRIU"*"| 3.17.1 "*" 3.19 | compatible |" ]]
+	[[ ${lines[9]} == "Verdict: "*"% of the known attributes are compatible)" ]]
+}
+
+@test "Run checkpointctl compat with tar file from another architecture" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	sed 's/ x86_64$/ riscv32/' test/dump.log > "$TEST_TMP_DIR1"/dump.log
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl compat "$TEST_TMP_DIR2"/test.tar --output json
+	[ "$status" -eq 0 ]
+	[[ $(echo "$output" | jq -r '.verdict') == "incompatible" ]]
+	[[ $(echo "$output" | jq -r '.compatible') == "false" ]]
+	[[ $(echo "$output" | jq -r '.attributes[] | select(.name == "Architecture") | .status') == "mismatch" ]]
+	[[ $(echo "$output" | jq -r '.attributes[] | select(.name == "Cgroup version") | .checkpoint') == "unknown" ]]
+}
+
+@test "Run checkpointctl compat with tar file and distribution kernel releases" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	# Only the major and minor version of the kernel release are compared
+	release=$(uname -r | cut -d. -f1-2).0-1.el9
+	sed "s/ 6\.1\.0 / $release /" test/dump.log > "$TEST_TMP_DIR1"/dump.log
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl compat "$TEST_TMP_DIR2"/test.tar --output json
+	[ "$status" -eq 0 ]
+	[[ $(echo "$output" | jq -r '.attributes[] | select(.name == "Kernel") | .checkpoint') == "$release" ]]
+	[[ $(echo "$output" | jq -r '.attributes[] | select(.name == "Kernel") | .status') == "compatible" ]]
+	sed 's/ 6\.1\.0 / 999.1.0-1.el99 /' test/dump.log > "$TEST_TMP_DIR1"/dump.log
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	checkpointctl compat "$TEST_TMP_DIR2"/test.tar --output json
+	[ "$status" -eq 0 ]
+	[[ $(echo "$output" | jq -r '.attributes[] | select(.name == "Kernel") | .status') == "mismatch" ]]
+	[[ $(echo "$output" | jq -r '.verdict') == "possibly incompatible" ]]
+}
+
+@test "Run checkpointctl compat with tar file without host information" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/test.tar . )
+	mkdir "$TEST_TMP_DIR2"/bin
+	PATH="$TEST_TMP_DIR2/bin" checkpointctl compat "$TEST_TMP_DIR2"/test.tar
+	[ "$status" -eq 0 ]
+	[[ "$output" == *"Verdict: unknown (0% of the known attributes are compatible)"* ]]
+	[[ "$output" == *"Not compared: Kernel, Architecture, Cgroup version, CRIU"* ]]
+}
+
+@test "Run checkpointctl show with several tar files and --resume-from" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/one.tar . )
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/two.tar . )
+	echo "invalid" > "$TEST_TMP_DIR2"/broken.tar
+	checkpointctl show "$TEST_TMP_DIR2"/one.tar "$TEST_TMP_DIR2"/broken.tar --resume-from "$TEST_TMP_DIR2"/state.jsonl --output logfmt
+	[ "$status" -eq 0 ]
+	[ "$(wc -l < "$TEST_TMP_DIR2"/state.jsonl)" -eq 1 ]
+	[[ $(jq -r '.path' "$TEST_TMP_DIR2"/state.jsonl) == "$TEST_TMP_DIR2/one.tar" ]]
+	checkpointctl show "$TEST_TMP_DIR2"/one.tar "$TEST_TMP_DIR2"/two.tar --resume-from "$TEST_TMP_DIR2"/state.jsonl --output logfmt
+	[ "$status" -eq 0 ]
+	[[ "$output" == *"Note: skipping $TEST_TMP_DIR2/one.tar, which has already been processed"* ]]
+	[[ "$output" == *"engine=Podman"* ]]
+	[ "$(wc -l < "$TEST_TMP_DIR2"/state.jsonl)" -eq 2 ]
+	checkpointctl show "$TEST_TMP_DIR2"/one.tar "$TEST_TMP_DIR2"/two.tar --resume-from "$TEST_TMP_DIR2"/state.jsonl
+	[ "$status" -eq 0 ]
+	[[ "$output" == *"Note: all checkpoints have already been processed"* ]]
+	[[ "$output" != *"Podman"* ]]
+}
+
+@test "Run checkpointctl show with --resume-from and an invalid state file" {
+	echo "invalid" > "$TEST_TMP_DIR2"/state.jsonl
+	checkpointctl show "$TEST_TMP_DIR2"/test.tar --resume-from "$TEST_TMP_DIR2"/state.jsonl
+	[ "$status" -eq 1 ]
+	[[ ${lines[0]} == *"failed to read line 1 of $TEST_TMP_DIR2/state.jsonl"* ]]
+}
+
+@test "Run checkpointctl show with --resume-from and a truncated state file" {
+	cp test/config.dump "$TEST_TMP_DIR1"
+	cp test/spec.dump "$TEST_TMP_DIR1"
+	mkdir "$TEST_TMP_DIR1"/checkpoint
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/one.tar . )
+	( cd "$TEST_TMP_DIR1" && tar cf "$TEST_TMP_DIR2"/two.tar . )
+	printf '{"path": "%s"}\n{"path": "%s' "$TEST_TMP_DIR2"/one.tar "$TEST_TMP_DIR2"/two > "$TEST_TMP_DIR2"/state.jsonl
+	checkpointctl show "$TEST_TMP_DIR2"/one.tar "$TEST_TMP_DIR2"/two.tar --resume-from "$TEST_TMP_DIR2"/state.jsonl --output logfmt
+	[ "$status" -eq 0 ]
+	[[ "$output" == *"Note: skipping $TEST_TMP_DIR2/one.tar, which has already been processed"* ]]
+	[[ "$output" == *"engine=Podman"* ]]
+	[ "$(wc -l < "$TEST_TMP_DIR2"/state.jsonl)" -eq 2 ]
+	[[ $(tail -n 1 "$TEST_TMP_DIR2"/state.jsonl | jq -r '.path') == "$TEST_TMP_DIR2/two.tar" ]]
+	checkpointctl show "$TEST_TMP_DIR2"/one.tar "$TEST_TMP_DIR2"/two.tar --resume-from "$TEST_TMP_DIR2"/state.jsonl
+	[ "$status" -eq 0 ]
+	[[ "$output" == *"Note: all checkpoints have already been processed"* ]]
+}
@@ -0,0 +1,60 @@
+#!/bin/bash
+# A repeatable timing of checkpointctl on a large generated checkpoint.
+#
+# The checkpoint consists of the test images, PAGES_MIB MiB of memory pages
+# and a rootfs-diff.tar with FILES files. Each command is run ITERATIONS
+# times and the minimum, average and maximum wall clock time are printed.
+
+set -e
+SELFDIR=$(dirname "$(readlink -f "$0")")
+ITERATIONS=${ITERATIONS:-5}
+PAGES_MIB=${PAGES_MIB:-512}
+FILES=${FILES:-10000}
+CHECKPOINTCTL=${CHECKPOINTCTL:-$SELFDIR/../checkpointctl}
+
+if [ ! -x "$CHECKPOINTCTL" ]; then
+	make -C "$SELFDIR/.."
+fi
+
+WORKDIR=$(mktemp -d)
+trap 'rm -rf "$WORKDIR"' EXIT
+
+echo "Creating checkpoint with $PAGES_MIB MiB of pages and $FILES files"
+mkdir -p "$WORKDIR"/checkpoint/checkpoint "$WORKDIR"/rootfs
+cp "$SELFDIR"/config.dump "$SELFDIR"/spec.dump "$SELFDIR"/dump.log "$WORKDIR"/checkpoint
+cp "$SELFDIR"/stats-dump "$WORKDIR"/checkpoint
+cp "$SELFDIR"/images/* "$WORKDIR"/checkpoint/checkpoint
+head -c "$((PAGES_MIB * 1024 * 1024))" /dev/zero > "$WORKDIR"/checkpoint/checkpoint/pages-1.img
+for i in $(seq "$FILES"); do
+	echo "$i" > "$WORKDIR"/rootfs/file-"$i"
+done
+tar cf "$WORKDIR"/checkpoint/rootfs-diff.tar -C "$WORKDIR"/rootfs .
+tar cf "$WORKDIR"/test.tar -C "$WORKDIR"/checkpoint .
+
+# run prints the time of ITERATIONS runs of checkpointctl with the given
+# arguments in milliseconds
+run() {
+	local label="$*" min=0 max=0 total=0 start elapsed
+	for _ in $(seq "$ITERATIONS"); do
+		start=$(date +%s%N)
+		"$CHECKPOINTCTL" "$@" > /dev/null 2>&1
+		elapsed=$((($(date +%s%N) - start) / 1000000))
+		total=$((total + elapsed))
+		if [ "$min" -eq 0 ] || [ "$elapsed" -lt "$min" ]; then
+			min=$elapsed
+		fi
+		if [ "$elapsed" -gt "$max" ]; then
+			max=$elapsed
+		fi
+	done
+	printf "%-50s min %6d ms  avg %6d ms  max %6d ms\n" "${label//"$TAR"/test.tar}" "$min" "$((total / ITERATIONS))" "$max"
+}
+
+TAR=$WORKDIR/test.tar
+run show "$TAR"
+run show "$TAR" --metadata-only
+run show "$TAR" --warn-size=1GiB --warn-dump-time=5s
+run show "$TAR" --all
+run stat "$TAR"
+run inspect "$TAR"
+run validate "$TAR"
@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to check container checkpoints against configured thresholds
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	units "github.com/docker/go-units"
+)
+
+var errThresholdExceeded = errors.New("checkpoint exceeds the configured thresholds")
+
+// parseWarnSize converts the --warn-size value (e.g. 2GiB or 500M) into bytes
+func parseWarnSize(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	size, err := units.RAMInBytes(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for --warn-size: %w", err)
+	}
+
+	return size, nil
+}
+
+// checkThresholds prints a warning to stderr for each configured threshold
+// the checkpoint exceeds. With --strict the warnings are turned into an error.
+func checkThresholds(checkpointDirectory string, size int64) error {
+	var warnings []string
+
+	limit, err := parseWarnSize(warnSize)
+	if err != nil {
+		return err
+	}
+	if limit > 0 && size > limit {
+		warnings = append(warnings, fmt.Sprintf(
+			"checkpoint size %s exceeds threshold %s",
+			formatSize(size),
+			formatSize(limit),
+		))
+	}
+
+	if warnDumpTime > 0 {
+		dumpStatistics, err := readDumpStats(checkpointDirectory)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: unable to check dump time: %v\n", err)
+		} else {
+			// The frozen time is the time the container was blocked by checkpointing
+			frozen := time.Duration(dumpStatistics.GetFrozenTime()) * time.Microsecond
+			if frozen > warnDumpTime {
+				warnings = append(warnings, fmt.Sprintf(
+					"checkpoint frozen time %s exceeds threshold %s",
+					frozen,
+					warnDumpTime,
+				))
+			}
+		}
+	}
+
+	if maxOpenFiles {
+		openFiles, err := checkOpenFiles(checkpointDirectory)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: unable to check open files: %v\n", err)
+		} else {
+			warnings = append(warnings, openFiles...)
+		}
+	}
+
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+	}
+	if strict && len(warnings) > 0 {
+		return errThresholdExceeded
+	}
+
+	return nil
+}
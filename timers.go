@@ -0,0 +1,208 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to display the timers of the checkpointed processes
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/checkpoint-restore/go-criu/v6/crit/images"
+	"github.com/olekukonko/tablewriter"
+)
+
+// clockNames are the names of the clock IDs of Linux
+var clockNames = map[uint32]string{
+	0:  "CLOCK_REALTIME",
+	1:  "CLOCK_MONOTONIC",
+	2:  "CLOCK_PROCESS_CPUTIME_ID",
+	3:  "CLOCK_THREAD_CPUTIME_ID",
+	4:  "CLOCK_MONOTONIC_RAW",
+	5:  "CLOCK_REALTIME_COARSE",
+	6:  "CLOCK_MONOTONIC_COARSE",
+	7:  "CLOCK_BOOTTIME",
+	8:  "CLOCK_REALTIME_ALARM",
+	9:  "CLOCK_BOOTTIME_ALARM",
+	11: "CLOCK_TAI",
+}
+
+// Clocks which count from the boot of the host. They continue on the
+// restore host, which most likely booted at a different time.
+var hostUptimeClocks = map[uint32]bool{
+	1: true,
+	4: true,
+	6: true,
+	7: true,
+	9: true,
+}
+
+func clockName(id uint32) string {
+	if name, ok := clockNames[id]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("unknown (%d)", id)
+}
+
+// processTimer is an interval timer or a POSIX timer of a process
+type processTimer struct {
+	PID      uint32
+	Comm     string
+	Type     string
+	Clock    uint32
+	Interval time.Duration
+	Value    time.Duration
+}
+
+func timerDuration(sec, nsec uint64) time.Duration {
+	return time.Duration(sec)*time.Second + time.Duration(nsec)
+}
+
+// readPosixTimers returns the POSIX timers of a process. Newer versions of
+// CRIU store them in the core image, older versions in posix-timers-<pid>.img.
+func readPosixTimers(checkpointDirectory string, p *processInfo) ([]*images.PosixTimerEntry, error) {
+	if timers := p.Core.GetTc().GetTimers().GetPosix(); len(timers) > 0 {
+		return timers, nil
+	}
+	name := fmt.Sprintf("posix-timers-%d.img", p.PID)
+	if !criuImageExists(checkpointDirectory, name) {
+		return nil, nil
+	}
+	img, err := readCriuImage(checkpointDirectory, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var timers []*images.PosixTimerEntry
+	for _, entry := range img.Entries {
+		t, ok := entry.Message.(*images.PosixTimerEntry)
+		if !ok {
+			return nil, fmt.Errorf("failed to type assert %s", name)
+		}
+		timers = append(timers, t)
+	}
+
+	return timers, nil
+}
+
+func getProcessTimers(checkpointDirectory string) ([]processTimer, error) {
+	processes, err := readProcesses(checkpointDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	var timers []processTimer
+	for _, p := range processes {
+		if !processSelected(p) {
+			continue
+		}
+		// Every process has the interval timers, only list the armed ones
+		itimers := p.Core.GetTc().GetTimers()
+		for _, it := range []struct {
+			name  string
+			clock uint32
+			entry *images.ItimerEntry
+		}{
+			// ITIMER_REAL is based on CLOCK_MONOTONIC, the others
+			// count the CPU time of the process
+			{"ITIMER_REAL", 1, itimers.GetReal()},
+			{"ITIMER_VIRTUAL", 2, itimers.GetVirt()},
+			{"ITIMER_PROF", 2, itimers.GetProf()},
+		} {
+			if it.entry == nil || (it.entry.GetVsec() == 0 && it.entry.GetVusec() == 0) {
+				continue
+			}
+			timers = append(timers, processTimer{
+				PID:      p.PID,
+				Comm:     p.Comm,
+				Type:     it.name,
+				Clock:    it.clock,
+				Interval: timerDuration(it.entry.GetIsec(), it.entry.GetIusec()*1000),
+				Value:    timerDuration(it.entry.GetVsec(), it.entry.GetVusec()*1000),
+			})
+		}
+
+		posix, err := readPosixTimers(checkpointDirectory, p)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range posix {
+			timers = append(timers, processTimer{
+				PID:      p.PID,
+				Comm:     p.Comm,
+				Type:     fmt.Sprintf("POSIX timer %d", t.GetItId()),
+				Clock:    t.GetClockId(),
+				Interval: timerDuration(t.GetIsec(), t.GetInsec()),
+				Value:    timerDuration(t.GetVsec(), t.GetVnsec()),
+			})
+		}
+	}
+
+	return timers, nil
+}
+
+// hasTimeNamespace returns true if CRIU saved the clock offsets of a time namespace
+func hasTimeNamespace(checkpointDirectory string) bool {
+	matches, _ := filepath.Glob(filepath.Join(imagesDirectory(checkpointDirectory), "timens-*.img"))
+	return len(matches) > 0
+}
+
+func showProcessTimers(checkpointDirectory string) error {
+	timers, err := getProcessTimers(checkpointDirectory)
+	if err != nil {
+		return fmt.Errorf("unable to display timers: %w", err)
+	}
+
+	printCaption("Timers")
+	if len(timers) == 0 {
+		fmt.Println("No timers found in checkpoint")
+		return nil
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	setTableHeader(table, []string{
+		"PID",
+		"Command",
+		"Timer",
+		"Clock",
+		"Interval",
+		"Expires In",
+	})
+	uptimeTimers := 0
+	for _, t := range timers {
+		value := t.Value.String()
+		if t.Value == 0 {
+			value = "disarmed"
+		} else if hostUptimeClocks[t.Clock] {
+			uptimeTimers++
+		}
+		interval := "-"
+		if t.Interval != 0 {
+			interval = t.Interval.String()
+		}
+		table.Append([]string{
+			fmt.Sprintf("%d", t.PID),
+			t.Comm,
+			t.Type,
+			clockName(t.Clock),
+			interval,
+			value,
+		})
+	}
+	table.Render()
+
+	if uptimeTimers > 0 && !hasTimeNamespace(checkpointDirectory) {
+		fmt.Fprintf(
+			os.Stderr,
+			"Warning: %d armed timer(s) use a clock which counts from the boot of the host "+
+				"and the container has no time namespace, the clock will jump on restore on another host\n",
+			uptimeTimers,
+		)
+	}
+
+	return nil
+}
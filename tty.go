@@ -0,0 +1,164 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to display the terminal of the container and the
+// state of the terminals captured by CRIU
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/checkpoint-restore/go-criu/v6/crit/images"
+	"github.com/olekukonko/tablewriter"
+	spec "github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// Local mode flags of termios(3)
+const (
+	termiosICANON = 0x2
+	termiosECHO   = 0x8
+)
+
+// readTTYs returns the terminals captured by CRIU
+func readTTYs(checkpointDirectory string) ([]*images.TtyInfoEntry, error) {
+	img, err := readCriuImage(checkpointDirectory, ttyInfoImg)
+	if err != nil {
+		return nil, err
+	}
+
+	var ttys []*images.TtyInfoEntry
+	for _, entry := range img.Entries {
+		t, ok := entry.Message.(*images.TtyInfoEntry)
+		if !ok {
+			return nil, fmt.Errorf("failed to type assert %s", ttyInfoImg)
+		}
+		ttys = append(ttys, t)
+	}
+
+	return ttys, nil
+}
+
+// readTTYData returns the number of bytes CRIU read from the queue of each
+// terminal. The data is written back to the terminal on restore.
+func readTTYData(checkpointDirectory string) (map[uint32]int, error) {
+	queued := make(map[uint32]int)
+	if !criuImageExists(checkpointDirectory, ttyDataImg) {
+		return queued, nil
+	}
+	img, err := readCriuImage(checkpointDirectory, ttyDataImg)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range img.Entries {
+		d, ok := entry.Message.(*images.TtyDataEntry)
+		if !ok {
+			return nil, fmt.Errorf("failed to type assert %s", ttyDataImg)
+		}
+		queued[d.GetTtyId()] += len(d.GetData())
+	}
+
+	return queued, nil
+}
+
+func ttyDevice(t *images.TtyInfoEntry) string {
+	if t.GetType() == images.TtyType_PTY && t.GetPty() != nil {
+		return fmt.Sprintf("/dev/pts/%d", t.GetPty().GetIndex())
+	}
+
+	return "-"
+}
+
+func ttyWindowSize(t *images.TtyInfoEntry) string {
+	ws := t.GetWinsize()
+	if ws == nil {
+		return "-"
+	}
+
+	return fmt.Sprintf("%dx%d", ws.GetWsCol(), ws.GetWsRow())
+}
+
+// ttyMode describes the line discipline settings which matter most to the
+// program running on the terminal
+func ttyMode(t *images.TtyInfoEntry) string {
+	termios := t.GetTermios()
+	if termios == nil {
+		return "-"
+	}
+	mode := []string{"raw"}
+	if termios.GetCLflag()&termiosICANON != 0 {
+		mode[0] = "canonical"
+	}
+	if termios.GetCLflag()&termiosECHO != 0 {
+		mode = append(mode, "echo")
+	}
+
+	return strings.Join(mode, ", ")
+}
+
+// showTerminals prints whether the container was created with a terminal
+// and the terminals CRIU captured. A checkpoint without tty-info.img has
+// no open terminals, which is the case for most detached containers.
+func showTerminals(checkpointDirectory string, specDump *spec.Spec) error {
+	terminal := specDump.Process != nil && specDump.Process.Terminal
+	consoleSize := "-"
+	if terminal && specDump.Process.ConsoleSize != nil {
+		consoleSize = fmt.Sprintf("%dx%d", specDump.Process.ConsoleSize.Width, specDump.Process.ConsoleSize.Height)
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	setTableHeader(table, []string{
+		"Terminal",
+		"Console Size",
+	})
+	table.Append([]string{yesNo(terminal), consoleSize})
+	printCaption("Terminal")
+	table.Render()
+
+	if !criuImageExists(checkpointDirectory, ttyInfoImg) {
+		fmt.Println("No tty images found in checkpoint")
+		return nil
+	}
+	ttys, err := readTTYs(checkpointDirectory)
+	if err != nil {
+		return fmt.Errorf("unable to display terminals: %w", err)
+	}
+	queued, err := readTTYData(checkpointDirectory)
+	if err != nil {
+		return fmt.Errorf("unable to display terminals: %w", err)
+	}
+
+	table = tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	setTableHeader(table, []string{
+		"ID",
+		"Type",
+		"Device",
+		"Session",
+		"Process Group",
+		"Window Size",
+		"Mode",
+		"Queued Data",
+	})
+	for _, t := range ttys {
+		data := "-"
+		if n, ok := queued[t.GetId()]; ok {
+			data = formatSize(int64(n))
+		}
+		table.Append([]string{
+			fmt.Sprintf("%d", t.GetId()),
+			t.GetType().String(),
+			ttyDevice(t),
+			fmt.Sprintf("%d", t.GetSid()),
+			fmt.Sprintf("%d", t.GetPgrp()),
+			ttyWindowSize(t),
+			ttyMode(t),
+			data,
+		})
+	}
+	printCaption("Captured terminals")
+	table.Render()
+
+	return nil
+}
@@ -0,0 +1,49 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to display the ulimits configured for the container
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+
+	metadata "github.com/checkpoint-restore/checkpointctl/lib"
+	"github.com/olekukonko/tablewriter"
+)
+
+func formatRlimit(limit uint64) string {
+	// Container engines store unlimited (-1) as the largest value
+	if limit == math.MaxUint64 {
+		return "unlimited"
+	}
+
+	return fmt.Sprintf("%d", limit)
+}
+
+// showConfiguredUlimits displays the ulimits the container was configured
+// with in config.dump. These are the limits the container engine requested,
+// the processes may have changed their limits before checkpointing.
+func showConfiguredUlimits(containerConfig *metadata.ContainerConfig) {
+	printCaption("Configured ulimits (%s)", metadata.ConfigDumpFile)
+	if containerConfig.Spec == nil || containerConfig.Spec.Process == nil || len(containerConfig.Spec.Process.Rlimits) == 0 {
+		fmt.Println("No ulimits configured")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	setTableHeader(table, []string{
+		"Name",
+		"Soft",
+		"Hard",
+	})
+	for _, r := range containerConfig.Spec.Process.Rlimits {
+		table.Append([]string{
+			r.Type,
+			formatRlimit(r.Soft),
+			formatRlimit(r.Hard),
+		})
+	}
+	table.Render()
+}
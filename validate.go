@@ -0,0 +1,408 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to validate the content of container checkpoint archives
+
+package main
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	metadata "github.com/checkpoint-restore/checkpointctl/lib"
+	"github.com/checkpoint-restore/go-criu/v6/crit"
+	"github.com/olekukonko/tablewriter"
+)
+
+type checkStatus string
+
+const (
+	checkPassed  checkStatus = "OK"
+	checkFailed  checkStatus = "FAILED"
+	checkSkipped checkStatus = "SKIPPED"
+
+	sizeAuditCheck = "size audit"
+	// Allowed difference in percent between the size of the checkpoint
+	// and the sum of the sizes declared in descriptors.json
+	sizeAuditTolerance = 10
+)
+
+type validationCheck struct {
+	Name    string      `json:"name"`
+	Status  checkStatus `json:"status"`
+	Details string      `json:"details,omitempty"`
+}
+
+type checkpointValidation struct {
+	Checks []validationCheck
+}
+
+// validationOutput is the machine-readable validation result of a checkpoint
+type validationOutput struct {
+	Input  string            `json:"input"`
+	Valid  bool              `json:"valid"`
+	Checks []validationCheck `json:"checks"`
+}
+
+// logfmtFields returns the result of each check as a key=value pair
+// followed by the details of the failed checks
+func (o validationOutput) logfmtFields() []logfmtField {
+	fields := []logfmtField{
+		{"input", o.Input},
+		{"valid", strconv.FormatBool(o.Valid)},
+	}
+	var problems []string
+	for _, c := range o.Checks {
+		fields = append(fields, logfmtField{logfmtKey(c.Name), string(c.Status)})
+		if c.Status == checkFailed {
+			problems = append(problems, c.Name+": "+c.Details)
+		}
+	}
+
+	return append(fields, logfmtField{"problems", strings.Join(problems, "; ")})
+}
+
+func (v *checkpointValidation) add(name string, status checkStatus, details string) {
+	v.Checks = append(v.Checks, validationCheck{
+		Name:    name,
+		Status:  status,
+		Details: details,
+	})
+}
+
+// Valid returns false if at least one of the checks failed
+func (v *checkpointValidation) Valid() bool {
+	for _, c := range v.Checks {
+		if c.Status == checkFailed {
+			return false
+		}
+	}
+
+	return true
+}
+
+func validateCheckpoint(checkpointDirectory string) *checkpointValidation {
+	v := &checkpointValidation{}
+
+	if _, _, err := metadata.ReadContainerCheckpointConfigDump(checkpointDirectory); err != nil {
+		v.add(metadata.ConfigDumpFile, checkFailed, err.Error())
+	} else {
+		v.add(metadata.ConfigDumpFile, checkPassed, "")
+	}
+
+	if _, _, err := metadata.ReadContainerCheckpointSpecDump(checkpointDirectory); err != nil {
+		v.add(metadata.SpecDumpFile, checkFailed, err.Error())
+	} else {
+		v.add(metadata.SpecDumpFile, checkPassed, "")
+	}
+
+	if validateCheckpointDirectory(v, checkpointDirectory) {
+		validateDescriptors(v, checkpointDirectory)
+		if sizeAudit {
+			validateCheckpointSize(v, checkpointDirectory)
+		}
+		validateImages(v, checkpointDirectory)
+	}
+	validateRootFsDiff(v, checkpointDirectory)
+
+	return v
+}
+
+// validateCheckpointDirectory checks that the checkpoint contains a directory
+// with CRIU images. Without it there is nothing to compare the manifest to.
+func validateCheckpointDirectory(v *checkpointValidation, checkpointDirectory string) bool {
+	name := imagesDirectoryName(checkpointDirectory)
+	fi, err := os.Stat(filepath.Join(checkpointDirectory, name))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		v.add(name, checkFailed, "not found")
+	case err != nil:
+		v.add(name, checkFailed, err.Error())
+	case !fi.IsDir():
+		v.add(name, checkFailed, "not a directory")
+	default:
+		v.add(name, checkPassed, "")
+		return true
+	}
+
+	return false
+}
+
+// rawImagePrefixes are the prefixes of the CRIU images which are written
+// as they are returned by the kernel or by external tools, like the tar
+// archives of tmpfs mounts and the dumps of iproute2 and iptables. They
+// have no magic and cannot be checked.
+var rawImagePrefixes = []string{
+	"tmpfs-",
+	"route-",
+	"route6-",
+	"ifaddr-",
+	"rule-",
+	"iptables-",
+	"ip6tables-",
+	"nftables-",
+	"netns-ct-",
+	"netns-exp-",
+}
+
+func isRawImage(name string) bool {
+	for _, prefix := range rawImagePrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateImages checks that the CRIU images required for a restore exist,
+// that every image starts with a known magic and that the memory pages
+// have a plausible size.
+func validateImages(v *checkpointValidation, checkpointDirectory string) {
+	entries, err := os.ReadDir(imagesDirectory(checkpointDirectory))
+	if err != nil {
+		v.add("CRIU images", checkFailed, err.Error())
+		return
+	}
+
+	var problems []string
+	present := make(map[string]bool)
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".img") {
+			continue
+		}
+		present[e.Name()] = true
+
+		path := filepath.Join(imagesDirectory(checkpointDirectory), e.Name())
+		// pages-*.img contain the raw memory pages and have no magic
+		if strings.HasPrefix(e.Name(), "pages-") {
+			fi, err := e.Info()
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", e.Name(), err))
+				continue
+			}
+			// Compressed pages are not stored in multiples of the page size
+			compressed, err := isCompressedPages(path)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", e.Name(), err))
+				continue
+			}
+			if !compressed && fi.Size()%pageSize != 0 {
+				problems = append(problems, fmt.Sprintf("%s: size %d is not a multiple of the page size", e.Name(), fi.Size()))
+			}
+			continue
+		}
+		if isRawImage(e.Name()) {
+			continue
+		}
+
+		c := crit.New(path, "", "", false, true)
+		if _, err := c.Info(); err != nil {
+			if isUnsupportedImage(path, err) {
+				problems = append(problems, criuImageError(checkpointDirectory, path, err).Error())
+				continue
+			}
+			problems = append(problems, fmt.Sprintf("%s: %v", e.Name(), err))
+		}
+	}
+
+	if len(present) == 0 {
+		v.add("CRIU images", checkSkipped, "no CRIU images found")
+		return
+	}
+	for _, name := range []string{inventoryImg, pstreeImg} {
+		if !present[name] {
+			problems = append(problems, "missing: "+name)
+		}
+	}
+	if len(problems) > 0 {
+		v.add("CRIU images", checkFailed, strings.Join(problems, "; "))
+		return
+	}
+	v.add("CRIU images", checkPassed, fmt.Sprintf("%d images", len(present)))
+}
+
+// readTar reads the optionally gzip compressed tar archive r to the end and
+// returns the number of entries and the size of their content
+func readTar(r io.Reader) (entries int, size int64, err error) {
+	r, err = decompress(r)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		_, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			// Read the rest of a compressed archive to verify its checksum
+			_, err := io.Copy(io.Discard, r)
+			return entries, size, err
+		}
+		if err != nil {
+			return entries, size, err
+		}
+		entries++
+		n, err := io.Copy(io.Discard, tr)
+		size += n
+		if err != nil {
+			return entries, size, err
+		}
+	}
+}
+
+// validateRootFsDiff checks that the changes to the root file system of the
+// container are a complete tar archive, a truncated archive breaks the restore
+func validateRootFsDiff(v *checkpointValidation, checkpointDirectory string) {
+	f, err := os.Open(filepath.Join(checkpointDirectory, metadata.RootFsDiffTar))
+	if errors.Is(err, os.ErrNotExist) {
+		v.add(metadata.RootFsDiffTar, checkSkipped, "not included in checkpoint")
+		return
+	}
+	if err != nil {
+		v.add(metadata.RootFsDiffTar, checkFailed, err.Error())
+		return
+	}
+	defer f.Close()
+
+	entries, size, err := readTar(f)
+	if err != nil {
+		v.add(metadata.RootFsDiffTar, checkFailed, fmt.Sprintf("after %d entries: %v", entries, err))
+		return
+	}
+	v.add(metadata.RootFsDiffTar, checkPassed, fmt.Sprintf("%d entries, %s", entries, formatSize(size)))
+}
+
+// validateDescriptors compares the files declared in descriptors.json
+// with the files actually found in the checkpoint directory.
+func validateDescriptors(v *checkpointValidation, checkpointDirectory string) {
+	descriptors, _, err := metadata.ReadContainerCheckpointDescriptors(imagesDirectory(checkpointDirectory))
+	if errors.Is(err, os.ErrNotExist) {
+		v.add(metadata.DescriptorsFile, checkSkipped, "not included in checkpoint")
+		return
+	}
+	if errors.Is(err, metadata.ErrNoFileManifest) {
+		v.add(metadata.DescriptorsFile, checkSkipped, err.Error())
+		return
+	}
+	if err != nil {
+		// The OCI runtime reads descriptors.json during restore
+		v.add(metadata.DescriptorsFile, checkFailed, err.Error())
+		return
+	}
+
+	entries, err := os.ReadDir(imagesDirectory(checkpointDirectory))
+	if err != nil {
+		v.add(metadata.DescriptorsFile, checkFailed, err.Error())
+		return
+	}
+	present := make(map[string]bool)
+	for _, e := range entries {
+		if e.IsDir() || e.Name() == metadata.DescriptorsFile {
+			continue
+		}
+		present[e.Name()] = true
+	}
+
+	var missing []string
+	for _, d := range descriptors {
+		if !present[d.Name] {
+			missing = append(missing, d.Name)
+		}
+		delete(present, d.Name)
+	}
+	var extra []string
+	for name := range present {
+		extra = append(extra, name)
+	}
+	sort.Strings(extra)
+
+	var details []string
+	if len(missing) > 0 {
+		details = append(details, "missing: "+strings.Join(missing, ", "))
+	}
+	if len(extra) > 0 {
+		details = append(details, "extra: "+strings.Join(extra, ", "))
+	}
+	if len(details) > 0 {
+		v.add(metadata.DescriptorsFile, checkFailed, strings.Join(details, "; "))
+		return
+	}
+	v.add(metadata.DescriptorsFile, checkPassed, fmt.Sprintf("%d files", len(descriptors)))
+}
+
+// validateCheckpointSize compares the size of the checkpoint directory with
+// the sum of the sizes declared in descriptors.json. A large difference
+// points to missing, extra, truncated or compressed files.
+func validateCheckpointSize(v *checkpointValidation, checkpointDirectory string) {
+	descriptors, descriptorsFile, err := metadata.ReadContainerCheckpointDescriptors(imagesDirectory(checkpointDirectory))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		v.add(sizeAuditCheck, checkSkipped, "no file manifest in checkpoint")
+		return
+	case err != nil:
+		// Broken manifests are reported by the descriptors.json check
+		v.add(sizeAuditCheck, checkSkipped, err.Error())
+		return
+	}
+	var declared int64
+	for _, d := range descriptors {
+		declared += d.Size
+	}
+	if declared == 0 {
+		v.add(sizeAuditCheck, checkSkipped, "descriptors.json declares no sizes")
+		return
+	}
+
+	size, err := getCheckpointSize(checkpointDirectory)
+	if err != nil {
+		v.add(sizeAuditCheck, checkFailed, err.Error())
+		return
+	}
+	// The manifest does not declare itself
+	if fi, err := os.Stat(descriptorsFile); err == nil {
+		size -= fi.Size()
+	}
+
+	delta := size - declared
+	percent := float64(delta) * 100 / float64(declared)
+	details := fmt.Sprintf(
+		"on disk %s, declared %s, delta %s (%+.1f%%)",
+		formatSize(size), formatSize(declared), formatSizeDelta(delta), percent,
+	)
+	if percent > sizeAuditTolerance || percent < -sizeAuditTolerance {
+		v.add(sizeAuditCheck, checkFailed, details)
+		return
+	}
+	v.add(sizeAuditCheck, checkPassed, details)
+}
+
+// showCheckpointValidation prints the validation results of the checkpoint input
+func showCheckpointValidation(input string, v *checkpointValidation) {
+	fmt.Printf("\nValidating container checkpoint %s\n\n", input)
+	showValidationChecks(v)
+}
+
+// showValidationChecks prints a table with the result of each check
+func showValidationChecks(v *checkpointValidation) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{
+		"Check",
+		"Result",
+		"Details",
+	})
+	for _, c := range v.Checks {
+		table.Append([]string{
+			c.Name,
+			string(c.Status),
+			c.Details,
+		})
+	}
+	table.Render()
+}
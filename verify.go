@@ -0,0 +1,104 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to verify that a container checkpoint contains
+// everything which is needed to restore it
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	metadata "github.com/checkpoint-restore/checkpointctl/lib"
+	spec "github.com/opencontainers/runtime-spec/specs-go"
+)
+
+const (
+	containerManagerCheck = "container manager"
+	pagesImages           = "pages-*.img"
+)
+
+// verifyCheckpoint checks that all mandatory parts of a checkpoint exist.
+// Other than validateCheckpoint it does not decode the CRIU images, which
+// keeps it cheap enough to run before every restore.
+func verifyCheckpoint(checkpointDirectory string) *checkpointValidation {
+	v := &checkpointValidation{}
+
+	if _, _, err := metadata.ReadContainerCheckpointConfigDump(checkpointDirectory); err != nil {
+		v.add(metadata.ConfigDumpFile, checkFailed, err.Error())
+	} else {
+		v.add(metadata.ConfigDumpFile, checkPassed, "")
+	}
+
+	specDump, _, err := metadata.ReadContainerCheckpointSpecDump(checkpointDirectory)
+	if err != nil {
+		v.add(metadata.SpecDumpFile, checkFailed, err.Error())
+		v.add(containerManagerCheck, checkSkipped, "spec.dump cannot be read")
+	} else {
+		v.add(metadata.SpecDumpFile, checkPassed, "")
+		verifyContainerManager(v, checkpointDirectory, specDump)
+	}
+
+	if validateCheckpointDirectory(v, checkpointDirectory) {
+		for _, name := range []string{inventoryImg, pstreeImg} {
+			if criuImageExists(checkpointDirectory, name) {
+				v.add(name, checkPassed, "")
+			} else {
+				v.add(name, checkFailed, "not found")
+			}
+		}
+		pages, _ := filepath.Glob(filepath.Join(imagesDirectory(checkpointDirectory), pagesImages))
+		if len(pages) > 0 {
+			v.add(pagesImages, checkPassed, plural(len(pages), "image"))
+		} else {
+			v.add(pagesImages, checkFailed, "not found")
+		}
+	}
+
+	verifyRootFsDiff(v, checkpointDirectory)
+
+	return v
+}
+
+// verifyContainerManager checks that the checkpoint was created by one of
+// the container engines known to checkpointctl
+func verifyContainerManager(v *checkpointValidation, checkpointDirectory string, specDump *spec.Spec) {
+	for _, d := range engineDetectors {
+		if d.Detect(checkpointDirectory, specDump) {
+			manager, ok := specDump.Annotations["io.container.manager"]
+			if !ok {
+				manager = "containerd"
+			}
+			v.add(containerManagerCheck, checkPassed, manager)
+			return
+		}
+	}
+
+	v.add(containerManagerCheck, checkFailed, fmt.Sprintf(
+		"unknown container manager %q", specDump.Annotations["io.container.manager"],
+	))
+}
+
+// verifyRootFsDiff checks that the changes to the root file system are not
+// empty if they are part of the checkpoint
+func verifyRootFsDiff(v *checkpointValidation, checkpointDirectory string) {
+	fi, err := os.Stat(filepath.Join(checkpointDirectory, metadata.RootFsDiffTar))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		v.add(metadata.RootFsDiffTar, checkSkipped, "not included in checkpoint")
+	case err != nil:
+		v.add(metadata.RootFsDiffTar, checkFailed, err.Error())
+	case fi.Size() == 0:
+		v.add(metadata.RootFsDiffTar, checkFailed, "empty")
+	default:
+		v.add(metadata.RootFsDiffTar, checkPassed, formatSize(fi.Size()))
+	}
+}
+
+// showCheckpointVerification prints the verification results of the checkpoint input
+func showCheckpointVerification(input string, v *checkpointValidation) {
+	fmt.Printf("\nVerifying container checkpoint %s\n\n", input)
+	showValidationChecks(v)
+}
@@ -0,0 +1,38 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// This file is used to display the versions of the tools which created
+// container checkpoints. The container engines do not record their version
+// in the checkpoint, only the version of CRIU is available.
+
+package main
+
+import (
+	"os"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+const versionUnknown = "unknown"
+
+// checkpointCriuVersion returns the version of CRIU which created the
+// checkpoint. CRIU only records it in the dump log, the images and the
+// statistics do not contain it.
+func checkpointCriuVersion(checkpointDirectory string) string {
+	if v := readCriuVersion(checkpointDirectory); v != "" {
+		return v
+	}
+
+	return versionUnknown
+}
+
+func showCheckpointVersions(checkpointDirectory string) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	setTableHeader(table, []string{
+		"Component",
+		"Version",
+	})
+	table.Append([]string{"CRIU", checkpointCriuVersion(checkpointDirectory)})
+	printCaption("Versions")
+	table.Render()
+}